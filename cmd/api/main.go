@@ -1,23 +1,63 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
 
 	_ "modernc.org/sqlite"
 
+	"bot-api/internal/auth"
+	"bot-api/internal/config"
+	"bot-api/internal/domain"
+	"bot-api/internal/gazetteer"
 	"bot-api/internal/handler"
+	"bot-api/internal/health"
+	"bot-api/internal/logging"
+	"bot-api/internal/metrics"
+	"bot-api/internal/ranking"
 	"bot-api/internal/repository"
 	"bot-api/internal/service"
+	"bot-api/internal/storage"
+	"bot-api/internal/textfilter"
 )
 
 func main() {
+	issueAdminKey := flag.String("issue-admin-key", "", "выпустить ключ API с правом admin под этим именем и выйти")
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "YAML-файл настроек (переменные окружения важнее него)")
+	flag.Parse()
+
+	// SIGINT/SIGTERM отменяют ctx: сервер перестает принимать запросы,
+	// дожидается начатых, останавливает фоновые задачи и закрывает БД
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// 0. Настройки: по умолчанию, из файла и из окружения (см. config.Config)
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fatal("Ошибка настроек", err)
+	}
+
+	// Логи: уровень и формат из настроек; записи по запросу получают его request_id
+	logger, err := logging.New(os.Stderr, cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		fatal("Ошибка настроек логов", err)
+	}
+	slog.SetDefault(logger)
+
 	// 1. Инициализация БД
-	db, err := sql.Open("sqlite", "/home/creepy0964/bot-api/cmd/api/db/dating_app.db")
+	db, err := sql.Open("sqlite", cfg.DBPath)
 	if err != nil {
-		log.Fatalf("FATAL: Ошибка открытия БД: %v", err)
+		fatal("Ошибка открытия БД", err)
 	}
 	defer db.Close()
 
@@ -25,34 +65,224 @@ func main() {
 
 	// Repository: работает с БД
 	repo := repository.NewStorage(db)
-	if err := repo.CreateTables(); err != nil {
-		log.Fatalf("FATAL: Ошибка создания таблиц: %v", err)
+
+	// Состояние процесса для /readyz и /version. Миграции применяются уже после
+	// запуска сервера, чтобы /healthz и /readyz отвечали и во время долгих миграций.
+	latestSchema, err := repository.LatestSchemaVersion()
+	if err != nil {
+		fatal("Ошибка чтения миграций", err)
+	}
+	checker := health.New()
+	checker.FailWhileMigrating = cfg.Readiness.FailWhileMigrating
+	checker.Schema, checker.SchemaLatest = repo.SchemaVersion, latestSchema
+	checker.AddCheck("database", repo.Ping)
+	checker.SetMigrating(true)
+	migrate := func() {
+		if err := repo.Migrate(ctx); err != nil {
+			fatal("Ошибка миграции БД", err)
+		}
+		checker.SetMigrating(false)
+	}
+
+	// Фото анкет по умолчанию хранятся рядом с БД
+	photosDir := cfg.PhotosDir
+	if photosDir == "" {
+		photosDir = filepath.Join(filepath.Dir(cfg.DBPath), "photos")
+	}
+	blobs, err := storage.NewLocalStore(photosDir)
+	if err != nil {
+		fatal("Ошибка инициализации хранилища фото", err)
 	}
 
 	// Service: содержит бизнес-логику и зависит от Repository
 	svc := service.NewService(repo)
+	svc.Blobs = blobs
+
+	// Проверка текста анкет: встроенные словари можно дополнить своими
+	// (CONTENT_FILTER_DICT_DIR) и поменять политику (CONTENT_FILTER_POLICY=link=reject,...)
+	if cfg.Features.ContentFilter {
+		filterCfg := textfilter.DefaultConfig()
+		if filterCfg.Policy, err = textfilter.ParsePolicy(cfg.ContentFilter.Policy); err != nil {
+			fatal("Ошибка политики фильтра текста", err)
+		}
+		if dir := cfg.ContentFilter.DictDir; dir != "" {
+			if err := filterCfg.LoadDir(dir); err != nil {
+				fatal("Ошибка загрузки словарей фильтра текста", err)
+			}
+		}
+		svc.Filter = textfilter.New(filterCfg)
+	}
+	if cfg.Features.Gazetteer {
+		svc.Cities = gazetteer.Default()
+	}
+
+	// Ранжирование ленты (sort=score); веса сигналов меняются через
+	// RANKING_WEIGHTS=interests=5,recency=0,...
+	if cfg.Features.Ranking {
+		weights, err := ranking.ParseWeights(cfg.RankingWeights)
+		if err != nil {
+			fatal("Ошибка весов ранжирования", err)
+		}
+		svc.Ranker = ranking.New(weights)
+	}
+
+	// Первый ключ админки выпускается из командной строки
+	if *issueAdminKey != "" {
+		migrate()
+		issued, err := svc.CreateAPIKey(context.Background(), domain.APIKeyRequest{Name: *issueAdminKey, Scopes: []string{auth.ScopeAdmin}})
+		if err != nil {
+			fatal("Ошибка выпуска ключа API", err)
+		}
+		fmt.Printf("Ключ API %q (ID %d): %s\n", issued.Name, issued.ID, issued.Key)
+		return
+	}
+
+	// Метрики Prometheus: запросы HTTP, ошибки методов сервиса, пул соединений
+	// и показатели сервиса из БД
+	var api service.UserService = svc
+	var reg *metrics.Registry
+	if cfg.Features.Metrics {
+		reg = metrics.NewRegistry()
+		repo.RegisterMetrics(reg)
+		api = service.Instrumented(svc, reg)
+	}
 
 	// Handler: обрабатывает HTTP и зависит от Service
-	h := handler.NewHandler(svc)
+	h := handler.NewHandler(api)
+	h.Health = checker
+
+	// Авторизация: ключи API ботов и админки или initData Telegram Mini App,
+	// подписанный токеном бота
+	if cfg.TelegramBotToken == "" {
+		fatal("Не задан TELEGRAM_BOT_TOKEN", nil)
+	}
+	authz := handler.NewAuth(api, auth.NewTelegramValidator(cfg.TelegramBotToken, cfg.TelegramAuthMaxAge))
+	require := func(scope string, next http.HandlerFunc) http.HandlerFunc {
+		return authz.Require(scope)(next)
+	}
 
 	// 3. Настройка Роутера
 	mux := http.NewServeMux()
 
-	// Регистрация роутов (используем экспортированные методы h)
-	mux.HandleFunc("POST /api/v1/users", h.CreateUserHandler)
-	mux.HandleFunc("GET /api/v1/users/{id}", h.GetUserHandler)
-	mux.HandleFunc("PUT /api/v1/users/{id}", h.UpdateUserHandler)
-	mux.HandleFunc("POST /api/v1/anquettes", h.CreateAnquetteHandler)
-	mux.HandleFunc("GET /api/v1/anquettes/{id}", h.GetAnquetteHandler)
-	mux.HandleFunc("PUT /api/v1/anquettes/{id}", h.UpdateAnquetteHandler)
-	mux.HandleFunc("DELETE /api/v1/anquettes/{id}", h.DeleteAnquetteHandler)
+	// Регистрация роутов (используем экспортированные методы h).
+	// Каждый роут требует свое право ключа API (см. auth.Scope*).
+	mux.HandleFunc("GET /api/v1/users", require(auth.ScopeAnalyticsRead, h.ListUsersHandler))
+	mux.HandleFunc("POST /api/v1/users", require(auth.ScopeUsersWrite, h.CreateUserHandler))
+	mux.HandleFunc("GET /api/v1/users/{id}", require(auth.ScopeUsersRead, h.GetUserHandler))
+	mux.HandleFunc("PUT /api/v1/users/{id}", require(auth.ScopeUsersWrite, h.UpdateUserHandler))
+	mux.HandleFunc("GET /api/v1/users/{id}/feed", require(auth.ScopeAnquettesRead, h.GetFeedHandler))
+	mux.HandleFunc("GET /api/v1/users/{id}/matches", require(auth.ScopeMatchesRead, h.ListMatchesHandler))
+	mux.HandleFunc("GET /api/v1/anquettes", require(auth.ScopeAnalyticsRead, h.ListAnquettesHandler))
+	mux.HandleFunc("GET /api/v1/anquettes/search", require(auth.ScopeAnquettesRead, h.SearchAnquettesHandler))
+	mux.HandleFunc("POST /api/v1/anquettes", require(auth.ScopeAnquettesWrite, h.CreateAnquetteHandler))
+	mux.HandleFunc("GET /api/v1/anquettes/{id}", require(auth.ScopeAnquettesRead, h.GetAnquetteHandler))
+	mux.HandleFunc("PUT /api/v1/anquettes/{id}", require(auth.ScopeAnquettesWrite, h.UpdateAnquetteHandler))
+	mux.HandleFunc("DELETE /api/v1/anquettes/{id}", require(auth.ScopeAnquettesWrite, h.DeleteAnquetteHandler))
+	mux.HandleFunc("PUT /api/v1/anquettes/{id}/location", require(auth.ScopeAnquettesWrite, h.SetAnquetteLocationHandler))
+	mux.HandleFunc("DELETE /api/v1/anquettes/{id}/location", require(auth.ScopeAnquettesWrite, h.DeleteAnquetteLocationHandler))
+	mux.HandleFunc("PUT /api/v1/anquettes/{id}/interests", require(auth.ScopeAnquettesWrite, h.SetAnquetteInterestsHandler))
+	mux.HandleFunc("POST /api/v1/anquettes/{id}/photos", require(auth.ScopeAnquettesWrite, h.UploadPhotoHandler))
+	mux.HandleFunc("GET /api/v1/anquettes/{id}/photos", require(auth.ScopeAnquettesRead, h.ListPhotosHandler))
+	mux.HandleFunc("PUT /api/v1/anquettes/{id}/photos/order", require(auth.ScopeAnquettesWrite, h.ReorderPhotosHandler))
+	mux.HandleFunc("DELETE /api/v1/anquettes/{id}/photos/{photoID}", require(auth.ScopeAnquettesWrite, h.DeletePhotoHandler))
+	mux.HandleFunc("GET /api/v1/photos/{id}", require(auth.ScopeAnquettesRead, h.GetPhotoFileHandler))
+	mux.HandleFunc("GET /api/v1/cities", require(auth.ScopeAnquettesRead, h.SearchCitiesHandler))
+	mux.HandleFunc("GET /api/v1/interests", require(auth.ScopeAnquettesRead, h.ListInterestsHandler))
+	mux.HandleFunc("POST /api/v1/reactions", require(auth.ScopeReactionsWrite, h.CreateReactionHandler))
+	mux.HandleFunc("DELETE /api/v1/matches/{id}", require(auth.ScopeMatchesWrite, h.DeleteMatchHandler))
+	mux.HandleFunc("POST /api/v1/blocks", require(auth.ScopeBlocksWrite, h.CreateBlockHandler))
+	mux.HandleFunc("POST /api/v1/reports", require(auth.ScopeReportsWrite, h.CreateReportHandler))
+
+	// Админка
+	mux.HandleFunc("POST /api/v1/admin/api-keys", require(auth.ScopeAdmin, h.CreateAPIKeyHandler))
+	mux.HandleFunc("GET /api/v1/admin/api-keys", require(auth.ScopeAdmin, h.ListAPIKeysHandler))
+	mux.HandleFunc("DELETE /api/v1/admin/api-keys/{id}", require(auth.ScopeAdmin, h.RevokeAPIKeyHandler))
+	mux.HandleFunc("GET /api/v1/admin/moderation", require(auth.ScopeAdmin, h.ModerationQueueHandler))
+	mux.HandleFunc("POST /api/v1/admin/moderation/decisions", require(auth.ScopeAdmin, h.CreateModerationDecisionHandler))
+	mux.HandleFunc("GET /api/v1/admin/users/{id}/feed/explain", require(auth.ScopeAdmin, h.ExplainFeedHandler))
+
+	// Проверки состояния: без авторизации и во время миграций
+	root := http.NewServeMux()
+	root.Handle("/", h.WaitForMigrations(mux))
+	root.HandleFunc("GET /healthz", h.HealthzHandler)
+	root.HandleFunc("GET /readyz", h.ReadyzHandler)
+	root.HandleFunc("GET /version", h.VersionHandler)
+
+	var srvHandler http.Handler = root
+	if reg != nil {
+		// Метрики содержат показатели сервиса, поэтому Prometheus передает ключ API
+		root.HandleFunc("GET /metrics", require(auth.ScopeAnalyticsRead, reg.ServeHTTP))
+		srvHandler = handler.NewHTTPMetrics(reg).Middleware(root)
+	}
 
 	// 4. Запуск Сервера
-	port := ":8080"
-	fmt.Printf("Сервер запущен на порту %s\n", port)
-	log.Printf("INFO: Starting server on %s", port)
+	srv := &http.Server{
+		Addr:         cfg.HTTP.Addr,
+		Handler:      handler.LogRequests(srvHandler),
+		ReadTimeout:  cfg.HTTP.ReadTimeout,
+		WriteTimeout: cfg.HTTP.WriteTimeout,
+		IdleTimeout:  cfg.HTTP.IdleTimeout,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+	slog.Info("Сервер запущен", "addr", cfg.HTTP.Addr)
+
+	migrate()
 
-	if err := http.ListenAndServe(port, mux); err != nil {
-		log.Fatal(err)
+	// Фоновые задачи прерываются вместе с ctx; перед закрытием БД их дожидаются
+	var workers sync.WaitGroup
+	if cfg.Features.BackgroundJobs {
+		// Фото, загруженные до появления очистки EXIF, обрабатываются в фоне
+		photosDone := checker.Worker("pending_photos")
+		workers.Go(func() {
+			err := svc.ProcessPendingPhotos(ctx)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				slog.Warn("Ошибка обработки старых фото", "error", err)
+			}
+			photosDone(err)
+		})
+
+		// Анкеты, созданные до справочника городов, получают city_id в фоне
+		citiesDone := checker.Worker("normalize_cities")
+		workers.Go(func() {
+			err := svc.NormalizeCities(ctx)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				slog.Warn("Ошибка распознавания городов старых анкет", "error", err)
+			}
+			citiesDone(err)
+		})
+	}
+
+	select {
+	case err := <-serveErr:
+		fatal("Ошибка HTTP-сервера", err)
+	case <-ctx.Done():
+	}
+	stop() // повторный сигнал завершит процесс сразу
+	checker.SetDraining()
+
+	// 5. Остановка: начатые запросы получают до cfg.HTTP.ShutdownTimeout
+	slog.Info("Остановка сервера, ожидание активных запросов", "timeout", cfg.HTTP.ShutdownTimeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("Не все запросы завершились до остановки", "error", err)
+	}
+	workers.Wait()
+	if err := db.Close(); err != nil {
+		slog.Warn("Ошибка закрытия БД", "error", err)
+	}
+	slog.Info("Сервер остановлен")
+}
+
+// fatal - пишет в лог ошибку запуска и завершает процесс
+func fatal(msg string, err error) {
+	if err != nil {
+		slog.Error(msg, "error", err)
+	} else {
+		slog.Error(msg)
 	}
+	os.Exit(1)
 }
@@ -2,7 +2,11 @@ module bot-api
 
 go 1.25.5
 
-require modernc.org/sqlite v1.40.1
+require (
+	golang.org/x/image v0.33.0
+	gopkg.in/yaml.v3 v3.0.1
+	modernc.org/sqlite v1.40.1
+)
 
 require (
 	github.com/dustin/go-humanize v1.0.1 // indirect
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"slices"
+)
+
+// Права ключей API. ScopeAdmin включает все остальные.
+const (
+	ScopeUsersRead      = "users:read"
+	ScopeUsersWrite     = "users:write"
+	ScopeAnquettesRead  = "anquettes:read"
+	ScopeAnquettesWrite = "anquettes:write"
+	ScopeReactionsWrite = "reactions:write"
+	ScopeMatchesRead    = "matches:read"
+	ScopeMatchesWrite   = "matches:write"
+	ScopeBlocksWrite    = "blocks:write"
+	ScopeReportsWrite   = "reports:write"
+	ScopeAnalyticsRead  = "analytics:read" // списки всех пользователей и анкет, метрики
+	ScopeAdmin          = "admin"
+)
+
+// KnownScopes - все права, которые можно выдать ключу
+var KnownScopes = []string{
+	ScopeUsersRead, ScopeUsersWrite,
+	ScopeAnquettesRead, ScopeAnquettesWrite,
+	ScopeReactionsWrite,
+	ScopeMatchesRead, ScopeMatchesWrite,
+	ScopeBlocksWrite, ScopeReportsWrite,
+	ScopeAnalyticsRead,
+	ScopeAdmin,
+}
+
+// UserScopes - права пользователя Telegram Mini App. Владение ресурсами
+// при этом дополнительно проверяется в сервисе.
+var UserScopes = []string{
+	ScopeUsersRead, ScopeUsersWrite,
+	ScopeAnquettesRead, ScopeAnquettesWrite,
+	ScopeReactionsWrite,
+	ScopeMatchesRead, ScopeMatchesWrite,
+	ScopeBlocksWrite, ScopeReportsWrite,
+}
+
+const apiKeyPrefix = "bk_"
+
+// GenerateAPIKey - новый случайный ключ API в открытом виде
+func GenerateAPIKey() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("auth: failed to generate api key: %w", err)
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// HashAPIKey - хеш ключа для хранения и поиска в БД.
+// Ключи случайные и длинные, поэтому соль и медленный хеш не нужны.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// HasScope - есть ли среди scopes право scope (ScopeAdmin дает любое право)
+func HasScope(scopes []string, scope string) bool {
+	return slices.Contains(scopes, scope) || slices.Contains(scopes, ScopeAdmin)
+}
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"context"
+
+	"bot-api/internal/domain"
+)
+
+type ctxKey int
+
+const (
+	tgIDKey ctxKey = iota
+	apiKeyKey
+)
+
+// WithTgID - кладет в контекст tg_id пользователя, подтвержденный подписью Telegram
+func WithTgID(ctx context.Context, tgID int64) context.Context {
+	return context.WithValue(ctx, tgIDKey, tgID)
+}
+
+// TgIDFromContext - tg_id вызывающего пользователя, если запрос прошел проверку initData
+func TgIDFromContext(ctx context.Context) (int64, bool) {
+	tgID, ok := ctx.Value(tgIDKey).(int64)
+	return tgID, ok
+}
+
+// WithAPIKey - кладет в контекст ключ API, которым подписан запрос
+func WithAPIKey(ctx context.Context, key domain.APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyKey, key)
+}
+
+// APIKeyFromContext - ключ API вызывающего сервиса, если запрос пришел с ключом
+func APIKeyFromContext(ctx context.Context) (domain.APIKey, bool) {
+	key, ok := ctx.Value(apiKeyKey).(domain.APIKey)
+	return key, ok
+}
@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidInitData = errors.New("auth: invalid telegram init data")
+	ErrInitDataExpired = errors.New("auth: telegram init data expired")
+)
+
+// Допустимое расхождение часов, если auth_date оказался в будущем
+const clockSkew = time.Minute
+
+// TelegramUser - поле user из initData Telegram Mini App
+type TelegramUser struct {
+	ID           int64  `json:"id"`
+	Username     string `json:"username"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	LanguageCode string `json:"language_code"`
+}
+
+// TelegramValidator - проверяет подпись initData Telegram Mini App.
+// См. https://core.telegram.org/bots/webapps#validating-data-received-via-the-mini-app
+type TelegramValidator struct {
+	secret []byte
+	maxAge time.Duration
+	now    func() time.Time
+}
+
+// NewTelegramValidator - maxAge ограничивает возраст auth_date (0 - без ограничения)
+func NewTelegramValidator(botToken string, maxAge time.Duration) *TelegramValidator {
+	mac := hmac.New(sha256.New, []byte("WebAppData"))
+	mac.Write([]byte(botToken))
+	return &TelegramValidator{secret: mac.Sum(nil), maxAge: maxAge, now: time.Now}
+}
+
+// Validate - проверяет hash и свежесть auth_date и возвращает пользователя из initData
+func (v *TelegramValidator) Validate(initData string) (TelegramUser, error) {
+	vals, err := url.ParseQuery(initData)
+	if err != nil {
+		return TelegramUser{}, fmt.Errorf("%w: %v", ErrInvalidInitData, err)
+	}
+
+	hash := vals.Get("hash")
+	if hash == "" {
+		return TelegramUser{}, fmt.Errorf("%w: hash is missing", ErrInvalidInitData)
+	}
+	got, err := hex.DecodeString(hash)
+	if err != nil {
+		return TelegramUser{}, fmt.Errorf("%w: hash is not hex", ErrInvalidInitData)
+	}
+	if !hmac.Equal(got, v.sign(vals)) {
+		return TelegramUser{}, fmt.Errorf("%w: signature mismatch", ErrInvalidInitData)
+	}
+
+	authDate, err := strconv.ParseInt(vals.Get("auth_date"), 10, 64)
+	if err != nil {
+		return TelegramUser{}, fmt.Errorf("%w: bad auth_date", ErrInvalidInitData)
+	}
+	issued := time.Unix(authDate, 0)
+	now := v.now()
+	if issued.After(now.Add(clockSkew)) {
+		return TelegramUser{}, fmt.Errorf("%w: auth_date is in the future", ErrInvalidInitData)
+	}
+	if v.maxAge > 0 && now.Sub(issued) > v.maxAge {
+		return TelegramUser{}, ErrInitDataExpired
+	}
+
+	var u TelegramUser
+	if err := json.Unmarshal([]byte(vals.Get("user")), &u); err != nil || u.ID <= 0 {
+		return TelegramUser{}, fmt.Errorf("%w: bad user field", ErrInvalidInitData)
+	}
+	return u, nil
+}
+
+// sign - HMAC-SHA256 от data-check-string: все поля, кроме hash,
+// в виде key=value, отсортированные по ключу и разделенные \n
+func (v *TelegramValidator) sign(vals url.Values) []byte {
+	keys := make([]string, 0, len(vals))
+	for k := range vals {
+		if k != "hash" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + vals.Get(k)
+	}
+
+	mac := hmac.New(sha256.New, v.secret)
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,97 @@
+package auth_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"bot-api/internal/auth"
+)
+
+const testBotToken = "123456:TEST-TOKEN"
+
+// signInitData - собирает initData так же, как это делает Telegram
+func signInitData(botToken string, vals url.Values) string {
+	keys := make([]string, 0, len(vals))
+	for k := range vals {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + vals.Get(k)
+	}
+
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(botToken))
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+
+	signed := url.Values{}
+	for k := range vals {
+		signed.Set(k, vals.Get(k))
+	}
+	signed.Set("hash", hex.EncodeToString(mac.Sum(nil)))
+	return signed.Encode()
+}
+
+func initDataAt(t time.Time) url.Values {
+	return url.Values{
+		"auth_date": {strconv.FormatInt(t.Unix(), 10)},
+		"query_id":  {"AAH"},
+		"user":      {`{"id":42,"username":"student","language_code":"ru"}`},
+	}
+}
+
+func TestTelegramValidator_Valid(t *testing.T) {
+	v := auth.NewTelegramValidator(testBotToken, time.Hour)
+
+	u, err := v.Validate(signInitData(testBotToken, initDataAt(time.Now())))
+
+	if err != nil {
+		t.Fatalf("Ожидали отсутствие ошибки, получили: %v", err)
+	}
+	if u.ID != 42 || u.Username != "student" {
+		t.Errorf("Неверный пользователь: %+v", u)
+	}
+}
+
+func TestTelegramValidator_WrongToken(t *testing.T) {
+	v := auth.NewTelegramValidator(testBotToken, time.Hour)
+
+	_, err := v.Validate(signInitData("654321:OTHER-TOKEN", initDataAt(time.Now())))
+
+	if !errors.Is(err, auth.ErrInvalidInitData) {
+		t.Errorf("Ожидали auth.ErrInvalidInitData, получили: %v", err)
+	}
+}
+
+func TestTelegramValidator_Tampered(t *testing.T) {
+	v := auth.NewTelegramValidator(testBotToken, time.Hour)
+	signed, _ := url.ParseQuery(signInitData(testBotToken, initDataAt(time.Now())))
+
+	// Подменяем пользователя, оставляя старую подпись
+	signed.Set("user", `{"id":1}`)
+	_, err := v.Validate(signed.Encode())
+
+	if !errors.Is(err, auth.ErrInvalidInitData) {
+		t.Errorf("Ожидали auth.ErrInvalidInitData, получили: %v", err)
+	}
+}
+
+func TestTelegramValidator_Expired(t *testing.T) {
+	v := auth.NewTelegramValidator(testBotToken, time.Hour)
+
+	_, err := v.Validate(signInitData(testBotToken, initDataAt(time.Now().Add(-2*time.Hour))))
+
+	if !errors.Is(err, auth.ErrInitDataExpired) {
+		t.Errorf("Ожидали auth.ErrInitDataExpired, получили: %v", err)
+	}
+}
@@ -0,0 +1,237 @@
+// Package config - настройки сервера. Значения берутся по умолчанию, затем из
+// YAML-файла (если он задан), затем из переменных окружения: окружение
+// важнее файла. Load проверяет итоговые настройки, чтобы ошибка в них
+// останавливала запуск, а не всплывала на первом запросе.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Уровни и форматы логов
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+var (
+	logLevels  = []string{LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError}
+	logFormats = []string{LogFormatText, LogFormatJSON}
+)
+
+// Config - все настройки сервера
+type Config struct {
+	DBPath    string `yaml:"db_path"`
+	PhotosDir string `yaml:"photos_dir"` // пусто - каталог photos рядом с БД
+
+	HTTP      HTTPConfig      `yaml:"http"`
+	Log       LogConfig       `yaml:"log"`
+	Readiness ReadinessConfig `yaml:"readiness"`
+
+	TelegramBotToken   string        `yaml:"telegram_bot_token"`
+	TelegramAuthMaxAge time.Duration `yaml:"telegram_auth_max_age"` // срок жизни initData Mini App
+
+	ContentFilter ContentFilterConfig `yaml:"content_filter"`
+	// Веса ранжирования ленты в формате ranking.ParseWeights
+	RankingWeights string `yaml:"ranking_weights"`
+
+	Features Features `yaml:"features"`
+}
+
+// HTTPConfig - адрес и таймауты HTTP-сервера
+type HTTPConfig struct {
+	Addr            string        `yaml:"addr"`
+	ReadTimeout     time.Duration `yaml:"read_timeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"` // сколько ждать активные запросы при остановке
+}
+
+// ReadinessConfig - когда /readyz сообщает о готовности
+type ReadinessConfig struct {
+	// Не готов, пока идут миграции (API в это время в любом случае отвечает 503)
+	FailWhileMigrating bool `yaml:"fail_while_migrating"`
+}
+
+// LogConfig - уровень (LogLevel*) и формат (LogFormat*) логов
+type LogConfig struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
+// ContentFilterConfig - политика (textfilter.ParsePolicy) и каталог
+// дополнительных словарей проверки текста анкет
+type ContentFilterConfig struct {
+	Policy  string `yaml:"policy"`
+	DictDir string `yaml:"dict_dir"`
+}
+
+// Features - части сервера, которые можно выключить
+type Features struct {
+	ContentFilter  bool `yaml:"content_filter"`  // проверка текста анкет
+	Gazetteer      bool `yaml:"gazetteer"`       // справочник городов
+	Ranking        bool `yaml:"ranking"`         // sort=score в ленте; без него - порядок по активности
+	BackgroundJobs bool `yaml:"background_jobs"` // обработка старых фото и городов при запуске
+	Metrics        bool `yaml:"metrics"`         // метрики Prometheus на /metrics
+}
+
+// Default - настройки по умолчанию. Путь к БД - относительно рабочего каталога
+// (в образе Docker это /app/cmd/api/db).
+func Default() Config {
+	return Config{
+		DBPath: "cmd/api/db/dating_app.db",
+		HTTP: HTTPConfig{
+			Addr:            ":8080",
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     2 * time.Minute,
+			ShutdownTimeout: 20 * time.Second,
+		},
+		Log:                LogConfig{Level: LogLevelInfo, Format: LogFormatText},
+		Readiness:          ReadinessConfig{FailWhileMigrating: true},
+		TelegramAuthMaxAge: 24 * time.Hour,
+		Features:           Features{ContentFilter: true, Gazetteer: true, Ranking: true, BackgroundJobs: true, Metrics: true},
+	}
+}
+
+// Load - настройки по умолчанию, файл path (пусто - без файла) и окружение
+func Load(path string) (Config, error) {
+	return load(path, os.LookupEnv)
+}
+
+func load(path string, lookupEnv func(string) (string, bool)) (Config, error) {
+	cfg := Default()
+	if path != "" {
+		if err := cfg.readFile(path); err != nil {
+			return cfg, err
+		}
+	}
+	if err := cfg.readEnv(lookupEnv); err != nil {
+		return cfg, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// readFile - поверх текущих значений читает YAML-файл. Неизвестные ключи -
+// ошибка, чтобы опечатка в имени настройки не проходила молча.
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config: failed to read %s: %w", path, err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("config: failed to parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// readEnv - поверх текущих значений читает заданные переменные окружения
+func (c *Config) readEnv(lookupEnv func(string) (string, bool)) error {
+	texts := map[string]*string{
+		"SQLITE_DB_PATH":          &c.DBPath,
+		"PHOTOS_DIR":              &c.PhotosDir,
+		"HTTP_ADDR":               &c.HTTP.Addr,
+		"LOG_LEVEL":               &c.Log.Level,
+		"LOG_FORMAT":              &c.Log.Format,
+		"TELEGRAM_BOT_TOKEN":      &c.TelegramBotToken,
+		"CONTENT_FILTER_POLICY":   &c.ContentFilter.Policy,
+		"CONTENT_FILTER_DICT_DIR": &c.ContentFilter.DictDir,
+		"RANKING_WEIGHTS":         &c.RankingWeights,
+	}
+	durations := map[string]*time.Duration{
+		"HTTP_READ_TIMEOUT":     &c.HTTP.ReadTimeout,
+		"HTTP_WRITE_TIMEOUT":    &c.HTTP.WriteTimeout,
+		"HTTP_IDLE_TIMEOUT":     &c.HTTP.IdleTimeout,
+		"HTTP_SHUTDOWN_TIMEOUT": &c.HTTP.ShutdownTimeout,
+		"TELEGRAM_AUTH_MAX_AGE": &c.TelegramAuthMaxAge,
+	}
+	flags := map[string]*bool{
+		"FEATURE_CONTENT_FILTER":  &c.Features.ContentFilter,
+		"FEATURE_GAZETTEER":       &c.Features.Gazetteer,
+		"FEATURE_RANKING":         &c.Features.Ranking,
+		"FEATURE_BACKGROUND_JOBS": &c.Features.BackgroundJobs,
+		"FEATURE_METRICS":         &c.Features.Metrics,
+
+		"READYZ_FAIL_WHILE_MIGRATING": &c.Readiness.FailWhileMigrating,
+	}
+
+	for name, dst := range texts {
+		if v, ok := lookupEnv(name); ok {
+			*dst = v
+		}
+	}
+	for name, dst := range durations {
+		if v, ok := lookupEnv(name); ok {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("config: %s: expected duration like 30s, got %q", name, v)
+			}
+			*dst = d
+		}
+	}
+	for name, dst := range flags {
+		if v, ok := lookupEnv(name); ok {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("config: %s: expected true or false, got %q", name, v)
+			}
+			*dst = b
+		}
+	}
+	return nil
+}
+
+// Validate - проверяет настройки; возвращает все найденные ошибки сразу
+func (c Config) Validate() error {
+	var errs []error
+	if c.DBPath == "" {
+		errs = append(errs, errors.New("db_path is required"))
+	}
+	if _, _, err := net.SplitHostPort(c.HTTP.Addr); err != nil {
+		errs = append(errs, fmt.Errorf("http.addr: %w", err))
+	}
+	for _, d := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"http.read_timeout", c.HTTP.ReadTimeout},
+		{"http.write_timeout", c.HTTP.WriteTimeout},
+		{"http.idle_timeout", c.HTTP.IdleTimeout},
+		{"http.shutdown_timeout", c.HTTP.ShutdownTimeout},
+		{"telegram_auth_max_age", c.TelegramAuthMaxAge},
+	} {
+		if d.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %s", d.name, d.value))
+		}
+	}
+	if !slices.Contains(logLevels, c.Log.Level) {
+		errs = append(errs, fmt.Errorf("log.level must be one of %v, got %q", logLevels, c.Log.Level))
+	}
+	if !slices.Contains(logFormats, c.Log.Format) {
+		errs = append(errs, fmt.Errorf("log.format must be one of %v, got %q", logFormats, c.Log.Format))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("config: invalid settings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// env - переменные окружения для load
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+}
+
+func writeFile(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatalf("Не удалось записать файл настроек: %v", err)
+	}
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := load("", env(nil))
+	if err != nil {
+		t.Fatalf("load провалился: %v", err)
+	}
+	if cfg.DBPath != Default().DBPath || cfg.HTTP.Addr != ":8080" || !cfg.Features.Ranking {
+		t.Errorf("Ожидали настройки по умолчанию, получили %+v", cfg)
+	}
+}
+
+func TestLoad_FileThenEnv(t *testing.T) {
+	path := writeFile(t, `
+db_path: /data/file.db
+http:
+  addr: "127.0.0.1:9000"
+  write_timeout: 1m
+log:
+  format: json
+features:
+  ranking: false
+`)
+	cfg, err := load(path, env(map[string]string{
+		"SQLITE_DB_PATH":         "/app/data/dating_app.db",
+		"HTTP_IDLE_TIMEOUT":      "5s",
+		"FEATURE_CONTENT_FILTER": "false",
+	}))
+	if err != nil {
+		t.Fatalf("load провалился: %v", err)
+	}
+
+	// Окружение важнее файла, файл - важнее значений по умолчанию
+	if cfg.DBPath != "/app/data/dating_app.db" {
+		t.Errorf("Ожидали путь к БД из SQLITE_DB_PATH, получили %q", cfg.DBPath)
+	}
+	if cfg.HTTP.Addr != "127.0.0.1:9000" || cfg.HTTP.WriteTimeout != time.Minute || cfg.Log.Format != LogFormatJSON {
+		t.Errorf("Ожидали значения из файла, получили %+v", cfg)
+	}
+	if cfg.HTTP.IdleTimeout != 5*time.Second || cfg.HTTP.ReadTimeout != Default().HTTP.ReadTimeout {
+		t.Errorf("Неверные таймауты: %+v", cfg.HTTP)
+	}
+	if cfg.Features.Ranking || cfg.Features.ContentFilter || !cfg.Features.Gazetteer {
+		t.Errorf("Неверные флаги: %+v", cfg.Features)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		file string
+		env  map[string]string
+		want string
+	}{
+		"неизвестный ключ":   {file: "db_pth: x.db", want: "db_pth"},
+		"неверный таймаут":   {env: map[string]string{"HTTP_READ_TIMEOUT": "15"}, want: "HTTP_READ_TIMEOUT"},
+		"неверный флаг":      {env: map[string]string{"FEATURE_RANKING": "maybe"}, want: "FEATURE_RANKING"},
+		"неверный адрес":     {env: map[string]string{"HTTP_ADDR": "8080"}, want: "http.addr"},
+		"пустой путь к БД":   {env: map[string]string{"SQLITE_DB_PATH": ""}, want: "db_path"},
+		"уровень логов":      {file: "log: {level: verbose}", want: "log.level"},
+		"нулевой таймаут":    {file: "http: {shutdown_timeout: 0s}", want: "http.shutdown_timeout"},
+		"файл не существует": {file: "-", want: "failed to read"},
+	} {
+		path := ""
+		switch tc.file {
+		case "":
+		case "-":
+			path = filepath.Join(t.TempDir(), "missing.yaml")
+		default:
+			path = writeFile(t, tc.file)
+		}
+		_, err := load(path, env(tc.env))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: ожидали ошибку с %q, получили %v", name, tc.want, err)
+		}
+	}
+}
+
+func TestLoad_ExampleFile(t *testing.T) {
+	if _, err := load("../../config.example.yaml", env(nil)); err != nil {
+		t.Errorf("Пример настроек не загружается: %v", err)
+	}
+}
@@ -1,22 +1,281 @@
 package domain
 
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// Виды реакций на анкету
+const (
+	ReactionLike    = "like"
+	ReactionDislike = "dislike"
+)
+
+// Причины жалоб
+const (
+	ReportReasonSpam          = "spam"
+	ReportReasonHarassment    = "harassment"
+	ReportReasonFake          = "fake"
+	ReportReasonInappropriate = "inappropriate"
+	ReportReasonUnderage      = "underage"
+	ReportReasonOther         = "other"
+)
+
+// Статусы жалоб
+const (
+	ReportStatusPending  = "pending"
+	ReportStatusResolved = "resolved"
+)
+
+// Статусы анкет. В ленте показываются только активные анкеты.
+const (
+	AnquetteStatusPending  = "pending"
+	AnquetteStatusActive   = "active"
+	AnquetteStatusRejected = "rejected"
+	AnquetteStatusHidden   = "hidden"
+)
+
+// Пол анкеты
+const (
+	GenderMale   = "m"
+	GenderFemale = "f"
+)
+
+// Цели знакомства (Preferences.Goal)
+const (
+	GoalFriendship = "friendship"
+	GoalDating     = "dating"
+	GoalStudyBuddy = "study_buddy"
+)
+
+// Стоп-факторы (Preferences.Dealbreakers): такие анкеты не показываются в ленте
+const (
+	DealbreakerOtherGoal        = "other_goal"          // у кандидата другая цель знакомства
+	DealbreakerNoPhotos         = "no_photos"           // у кандидата нет фото
+	DealbreakerNoSharedInterest = "no_shared_interests" // нет общих интересов
+)
+
+// Порядок выдачи ленты
+const (
+	FeedSortID       = ""         // по возрастанию ID анкеты
+	FeedSortDistance = "distance" // сначала ближайшие
+	FeedSortScore    = "score"    // сначала самые подходящие (см. пакет ranking)
+)
+
+// Порядок списков пользователей и анкет (ListFilter.Sort); "-" в начале - по убыванию
+const (
+	ListSortCreated    = "created_at"
+	ListSortAge        = "age"            // только анкеты
+	ListSortLastActive = "last_active_at" // только пользователи
+)
+
+// Коды нарушений валидации (Violation.Code)
+const (
+	CodeRequired         = "required"
+	CodeTooShort         = "too_short"
+	CodeTooLong          = "too_long"
+	CodeOutOfRange       = "out_of_range"
+	CodeInvalid          = "invalid"
+	CodeForbiddenContent = "forbidden_content"
+)
+
+// Коды ошибок API (APIResponse.Code). Не зависят от языка, на них можно ветвиться.
+const (
+	ErrCodeInvalidJSON     = "invalid_json"
+	ErrCodeInvalidID       = "invalid_id"
+	ErrCodeInvalidParam    = "invalid_param"
+	ErrCodeInvalidFile     = "invalid_file"
+	ErrCodeFileTooLarge    = "file_too_large"
+	ErrCodeValidation      = "validation_failed"
+	ErrCodeNotFound        = "not_found"
+	ErrCodeAlreadyExists   = "already_exists"
+	ErrCodeUnauthorized    = "unauthorized"
+	ErrCodeInitDataInvalid = "init_data_invalid"
+	ErrCodeInitDataExpired = "init_data_expired"
+	ErrCodeForbidden       = "forbidden"
+	ErrCodeMissingScope    = "missing_scope"
+	ErrCodeUserBanned      = "user_banned"
+	ErrCodeInternal        = "internal_error"
+	ErrCodeUnavailable     = "unavailable"
+)
+
+// Решения модератора
+const (
+	ModerationApprove = "approve"
+	ModerationReject  = "reject"
+	ModerationBan     = "ban"
+)
+
 // === Модели БД ===
 
 type User struct {
-	ID         int    `json:"id,omitempty"`
-	TgID       int64  `json:"tg_id"`
-	TgUsername string `json:"tg_username"`
-	AnquetteID int    `json:"anquette_id"`
+	ID         int        `json:"id,omitempty"`
+	TgID       int64      `json:"tg_id"`
+	TgUsername string     `json:"tg_username"`
+	AnquetteID int        `json:"anquette_id"`
+	Locale     string     `json:"locale,omitempty"`
+	BannedAt   *time.Time `json:"banned_at,omitempty"`
+
+	LastActiveAt *time.Time `json:"last_active_at,omitempty"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"` // нет у пользователей из старых версий
 }
 
 type Anquette struct {
-	ID          int    `json:"id,omitempty"`
+	ID          int         `json:"id,omitempty"`
+	Name        string      `json:"name"`
+	Age         int         `json:"age"`
+	City        string      `json:"city"`
+	CityID      string      `json:"city_id,omitempty"` // City.ID из справочника; пусто, если город не распознан
+	Gender      string      `json:"gender"`
+	Preferences Preferences `json:"preferences"`
+	Description string      `json:"description"`
+	Status      string      `json:"status,omitempty"`
+
+	// Причина отказа модератора; только у отклоненной анкеты
+	RejectionReason string `json:"rejection_reason,omitempty"`
+
+	Interests []string `json:"interests,omitempty"` // Interest.ID по алфавиту
+
+	CreatedAt *time.Time `json:"created_at,omitempty"` // нет у анкет из старых версий
+
+	// Точные координаты наружу не отдаются, в ленте - только примерное расстояние
+	Location *GeoPoint `json:"-"`
+}
+
+// City - город из встроенного справочника (см. пакет gazetteer)
+type City struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	NameEn     string  `json:"name_en"`
+	Country    string  `json:"country"` // ISO 3166-1 alpha-2
+	Region     string  `json:"region,omitempty"`
+	Lat        float64 `json:"lat"`
+	Lon        float64 `json:"lon"`
+	Population int     `json:"population"`
+}
+
+// Preferences - кого ищет владелец анкеты. Пустые поля - без ограничения.
+type Preferences struct {
+	Genders       []string `json:"genders,omitempty"` // Gender*
+	AgeMin        int      `json:"age_min,omitempty"`
+	AgeMax        int      `json:"age_max,omitempty"`
+	MaxDistanceKm int      `json:"max_distance_km,omitempty"`
+	Goal          string   `json:"goal,omitempty"`         // Goal*
+	Dealbreakers  []string `json:"dealbreakers,omitempty"` // Dealbreaker*
+	Text          string   `json:"text,omitempty"`         // свободный текст, ни на что не влияет
+}
+
+// UnmarshalJSON - принимает и объект, и строку, которую присылают клиенты
+// времен свободного текста ("preferences": "Ж"): строка становится Text.
+func (p *Preferences) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '"' {
+		var text string
+		if err := json.Unmarshal(trimmed, &text); err != nil {
+			return err
+		}
+		*p = Preferences{Text: text}
+		return nil
+	}
+	type plain Preferences // без методов, чтобы не зациклиться
+	return json.Unmarshal(data, (*plain)(p))
+}
+
+// Interest - интерес из справочника (таблица interests)
+type Interest struct {
+	ID       string `json:"id"`
+	Category string `json:"category"`
+	Name     string `json:"name"`
+	NameEn   string `json:"name_en"`
+}
+
+// GeoPoint - координаты в градусах
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+type Reaction struct {
+	ID           int       `json:"id"`
+	FromTgID     int64     `json:"from_tg_id"`
+	ToAnquetteID int       `json:"to_anquette_id"`
+	Kind         string    `json:"kind"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Match - взаимная симпатия двух пользователей.
+// Пара хранится упорядоченной: UserTgID1 < UserTgID2.
+type Match struct {
+	ID        int       `json:"id"`
+	UserTgID1 int64     `json:"user_tg_id_1"`
+	UserTgID2 int64     `json:"user_tg_id_2"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Photo - фото анкеты; Position задает порядок показа (0 - главное фото).
+// Variants - уменьшенные копии (превью, миниатюра).
+type Photo struct {
+	ID          int            `json:"id"`
+	AnquetteID  int            `json:"anquette_id"`
+	Position    int            `json:"position"`
+	ContentType string         `json:"content_type"`
+	Size        int64          `json:"size"`
+	Width       int            `json:"width"`
+	Height      int            `json:"height"`
+	URL         string         `json:"url"`
+	Variants    []PhotoVariant `json:"variants"`
+	StorageKey  string         `json:"-"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+type PhotoVariant struct {
 	Name        string `json:"name"`
-	Age         int    `json:"age"`
-	City        string `json:"city"`
-	Gender      string `json:"gender"`
-	Preferences string `json:"preferences"`
-	Description string `json:"description"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	URL         string `json:"url"`
+	StorageKey  string `json:"-"`
+}
+
+type Block struct {
+	BlockerTgID int64     `json:"blocker_tg_id"`
+	BlockedTgID int64     `json:"blocked_tg_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Report - жалоба на пользователя; ReportedAnquetteID = 0, если жалоба не на анкету
+type Report struct {
+	ID                 int       `json:"id"`
+	ReporterTgID       int64     `json:"reporter_tg_id"`
+	ReportedTgID       int64     `json:"reported_tg_id"`
+	ReportedAnquetteID int       `json:"reported_anquette_id,omitempty"`
+	Reason             string    `json:"reason"`
+	Note               string    `json:"note,omitempty"`
+	Status             string    `json:"status"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// ModerationDecision - запись журнала модерации. ReportID, AnquetteID и TgID
+// равны 0, если решение их не касается.
+type ModerationDecision struct {
+	ID         int       `json:"id"`
+	Action     string    `json:"action"`
+	ReportID   int       `json:"report_id,omitempty"`
+	AnquetteID int       `json:"anquette_id,omitempty"`
+	TgID       int64     `json:"tg_id,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	Moderator  string    `json:"moderator"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// APIKey - ключ доступа бота или админки. Сам ключ не хранится, только его хеш.
+type APIKey struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
 }
 
 // === Структуры Запросов ===
@@ -25,22 +284,231 @@ type UserRequest struct {
 	TgID       int64  `json:"tg_id"`
 	TgUsername string `json:"tg_username"`
 	AnquetteID int    `json:"anquette_id"`
+	Locale     string `json:"locale"` // язык сообщений API: ru, en или пусто
 }
 
 type AnquetteRequest struct {
-	Name        string `json:"name"`
-	Age         int    `json:"age"`
-	City        string `json:"city"`
-	Gender      string `json:"gender"`
-	Preferences string `json:"preferences"`
-	Description string `json:"description"`
+	Name        string      `json:"name"`
+	Age         int         `json:"age"`
+	City        string      `json:"city"`
+	Gender      string      `json:"gender"`
+	Preferences Preferences `json:"preferences"`
+	Description string      `json:"description"`
+
+	Status string `json:"-"` // выставляется сервисом по итогам проверки текста
+	CityID string `json:"-"` // выставляется сервисом по справочнику городов
+}
+
+// InterestsRequest - полный список интересов анкеты (Interest.ID)
+type InterestsRequest struct {
+	Interests []string `json:"interests"`
+}
+
+type ReactionRequest struct {
+	TgID       int64  `json:"tg_id"`
+	AnquetteID int    `json:"anquette_id"`
+	Kind       string `json:"kind"`
+}
+
+// BlockRequest - пользователь TgID блокирует пользователя TargetTgID
+// или владельца анкеты AnquetteID
+type BlockRequest struct {
+	TgID       int64 `json:"tg_id"`
+	TargetTgID int64 `json:"target_tg_id"`
+	AnquetteID int   `json:"anquette_id"`
+}
+
+// ReportRequest - жалоба пользователя TgID на пользователя TargetTgID
+// или на анкету AnquetteID
+type ReportRequest struct {
+	TgID       int64  `json:"tg_id"`
+	TargetTgID int64  `json:"target_tg_id"`
+	AnquetteID int    `json:"anquette_id"`
+	Reason     string `json:"reason"`
+	Note       string `json:"note"`
+}
+
+// ModerationRequest - решение модератора по жалобе ReportID, анкете AnquetteID
+// или пользователю TgID (для бана). Reason обязателен для reject.
+type ModerationRequest struct {
+	Action     string `json:"action"`
+	ReportID   int    `json:"report_id"`
+	AnquetteID int    `json:"anquette_id"`
+	TgID       int64  `json:"tg_id"`
+	Reason     string `json:"reason"`
+}
+
+type APIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// LocationRequest - геопозиция анкеты. Поля совпадают с Location из Telegram
+// Bot API, так что бот может переслать message.location как есть.
+type LocationRequest struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type PhotoOrderRequest struct {
+	PhotoIDs []int `json:"photo_ids"`
+}
+
+// FeedFilter - параметры выдачи ленты анкет.
+// Пустые поля не участвуют в фильтрации.
+type FeedFilter struct {
+	Gender  string `json:"gender"`
+	City    string `json:"city"`
+	CityID  string `json:"city_id"` // важнее City; City без CityID сравнивается как текст
+	AgeMin  int    `json:"age_min"`
+	AgeMax  int    `json:"age_max"`
+	AfterID int    `json:"after_id"` // курсор: ID последней показанной анкеты
+	Limit   int    `json:"limit"`
+
+	MaxDistanceKm int    `json:"max_distance_km"` // 0 - без ограничения
+	Sort          string `json:"sort"`            // FeedSort*
+	// Курсор для FeedSortDistance: DistanceKm последней показанной анкеты (вместе с AfterID)
+	AfterDistanceKm int `json:"after_distance_km"`
+	// Курсор для FeedSortScore: Score последней показанной анкеты (вместе с AfterID)
+	AfterScore float64 `json:"after_score"`
+	// Interest.ID; подходят анкеты хотя бы с одним из интересов
+	Interests []string `json:"interests"`
+	Goal      string   `json:"goal"` // Goal*; подходят только анкеты с той же целью
+
+	ViewerTgID        int64     `json:"-"`
+	ExcludeAnquetteID int       `json:"-"`
+	Origin            *GeoPoint `json:"-"` // геопозиция зрителя
+	// Пол и возраст зрителя: анкеты, чьи Preferences их исключают, не показываются
+	ViewerGender string `json:"-"`
+	ViewerAge    int    `json:"-"`
+	WithPhotos   bool   `json:"-"` // только анкеты с фото
+}
+
+// ListFilter - фильтры и порядок списков пользователей и анкет (админка, аналитика).
+// Пользователи отбираются по городу, полу и возрасту своих анкет.
+type ListFilter struct {
+	City         string     `json:"city"`
+	CityID       string     `json:"city_id"` // важнее City; City без CityID сравнивается как текст
+	Gender       string     `json:"gender"`
+	AgeMin       int        `json:"age_min"`
+	AgeMax       int        `json:"age_max"`
+	CreatedAfter *time.Time `json:"created_after"`
+
+	Sort   string `json:"sort"`   // ListSort*, по умолчанию ListSortCreated
+	Cursor string `json:"cursor"` // NextCursor предыдущей страницы
+	Limit  int    `json:"limit"`
+}
+
+// SearchFilter - полнотекстовый поиск анкет по имени и описанию
+type SearchFilter struct {
+	Query      string `json:"q"`
+	Status     string `json:"status"` // AnquetteStatus*; пусто - любой статус
+	ViewerTgID int64  `json:"tg_id"`  // кто ищет: анкеты заблокированных им и заблокировавших его скрываются
+	Limit      int    `json:"limit"`
+}
+
+// SearchHit - найденная анкета. Snippet - фрагмент описания в HTML,
+// совпадения выделены <mark>; Rank - релевантность (больше - лучше).
+type SearchHit struct {
+	Anquette
+	Snippet string  `json:"snippet"`
+	Rank    float64 `json:"rank"`
+}
+
+// Page - страница списка. NextCursor пуст на последней странице.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// FeedItem - анкета в ленте. DistanceKm - расстояние до зрителя, округленное
+// до целых километров (не меньше 1); 0, если у зрителя или кандидата нет геопозиции.
+type FeedItem struct {
+	Anquette
+	DistanceKm int     `json:"distance_km,omitempty"`
+	Score      float64 `json:"score,omitempty"` // оценка совместимости (FeedSortScore), 0..1
+
+	Stats FeedStats `json:"-"`
+}
+
+// FeedStats - данные о кандидате для ранжирования ленты; наружу не отдаются
+type FeedStats struct {
+	PhotoCount     int
+	LastActiveAt   *time.Time // владелец анкеты
+	LikesGiven     int        // лайков, поставленных владельцем
+	ReactionsGiven int        // всех реакций владельца
+}
+
+// ScoreExplanation - оценка кандидата в ленте (0..1) и вклад каждого сигнала
+type ScoreExplanation struct {
+	Score   float64       `json:"score"`
+	Signals []SignalScore `json:"signals"`
+}
+
+type SignalScore struct {
+	Signal       string  `json:"signal"`
+	Value        float64 `json:"value"` // 0..1
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"` // Weight * Value / сумма весов
+}
+
+// RankedFeedItem - анкета ленты с объяснением оценки (отладка ранжирования);
+// сама оценка - FeedItem.Score
+type RankedFeedItem struct {
+	FeedItem
+	Signals []SignalScore `json:"signals"`
+}
+
+// ReactionResult - результат реакции; Match заполнен, если симпатия оказалась взаимной
+type ReactionResult struct {
+	Reaction Reaction `json:"reaction"`
+	Match    *Match   `json:"match,omitempty"`
+}
+
+// MatchView - мэтч глазами одного из участников: партнер и его анкета
+type MatchView struct {
+	ID        int          `json:"id"`
+	CreatedAt time.Time    `json:"created_at"`
+	Partner   MatchPartner `json:"partner"`
+}
+
+type MatchPartner struct {
+	TgID       int64     `json:"tg_id"`
+	TgUsername string    `json:"tg_username"`
+	Anquette   *Anquette `json:"anquette,omitempty"`
+}
+
+// ModerationQueue - то, что ждет решения модератора: новые жалобы
+// и анкеты со статусом pending
+type ModerationQueue struct {
+	Reports   []Report   `json:"reports"`
+	Anquettes []Anquette `json:"anquettes"`
+}
+
+// IssuedAPIKey - только что выпущенный ключ; Key в открытом виде отдается один раз
+type IssuedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
 }
 
 // === Структура Ответа API ===
 
 type APIResponse struct {
-	Status string      `json:"status"`
-	ID     int         `json:"id,omitempty"`
-	Data   interface{} `json:"data,omitempty"`
-	Error  string      `json:"error,omitempty"`
+	Status     string      `json:"status"`
+	ID         int         `json:"id,omitempty"`
+	Data       interface{} `json:"data,omitempty"`
+	Code       string      `json:"code,omitempty"`  // ErrCode* для ошибок
+	Error      string      `json:"error,omitempty"` // на языке вызывающего
+	Violations []Violation `json:"violations,omitempty"`
+}
+
+// Violation - нарушение правила валидации в одном поле запроса
+type Violation struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+
+	// Сообщение из каталога i18n на языке вызывающего (заполняет Message в хендлере)
+	Key  string `json:"-"`
+	Args []any  `json:"-"`
 }
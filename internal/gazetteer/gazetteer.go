@@ -0,0 +1,216 @@
+// Package gazetteer - встроенный справочник городов России и СНГ.
+// Переводит названия в любом распространенном написании ("Спб", "Питер",
+// "Saint Petersburg") в город с постоянным ID и ищет города по началу названия.
+package gazetteer
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"bot-api/internal/domain"
+)
+
+//go:embed cities.tsv
+var citiesTSV string
+
+// Gazetteer - справочник городов. Безопасен для одновременного чтения.
+type Gazetteer struct {
+	cities []domain.City
+	byID   map[string]int
+	keys   []key
+	byKey  map[string][]int // нормализованное написание -> города, крупные первыми
+}
+
+// key - одно из написаний города
+type key struct {
+	norm string
+	city int
+}
+
+// Default - справочник из встроенного cities.tsv
+var Default = sync.OnceValue(func() *Gazetteer {
+	g, err := Parse(strings.NewReader(citiesTSV))
+	if err != nil {
+		panic(err)
+	}
+	return g
+})
+
+// Parse - читает справочник в формате cities.tsv: строки
+// "id<TAB>название<TAB>название en<TAB>страна<TAB>регион<TAB>широта<TAB>долгота<TAB>население[<TAB>варианты через |]".
+// Пустые строки и строки с # пропускаются.
+func Parse(r io.Reader) (*Gazetteer, error) {
+	g := &Gazetteer{byID: map[string]int{}, byKey: map[string][]int{}}
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		c, aliases, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("gazetteer: line %d: %w", n, err)
+		}
+		if _, dup := g.byID[c.ID]; dup {
+			return nil, fmt.Errorf("gazetteer: line %d: duplicate id %q", n, c.ID)
+		}
+		g.add(c, aliases)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("gazetteer: %w", err)
+	}
+
+	for _, ids := range g.byKey {
+		sort.SliceStable(ids, func(i, j int) bool {
+			return g.cities[ids[i]].Population > g.cities[ids[j]].Population
+		})
+	}
+	return g, nil
+}
+
+func parseLine(line string) (domain.City, []string, error) {
+	f := strings.Split(line, "\t")
+	if len(f) != 8 && len(f) != 9 {
+		return domain.City{}, nil, fmt.Errorf("expected 8 or 9 fields, got %d", len(f))
+	}
+	c := domain.City{ID: f[0], Name: f[1], NameEn: f[2], Country: f[3], Region: f[4]}
+	if c.ID == "" || c.Name == "" {
+		return c, nil, fmt.Errorf("empty id or name")
+	}
+
+	var err error
+	if c.Lat, err = strconv.ParseFloat(f[5], 64); err != nil {
+		return c, nil, fmt.Errorf("latitude: %w", err)
+	}
+	if c.Lon, err = strconv.ParseFloat(f[6], 64); err != nil {
+		return c, nil, fmt.Errorf("longitude: %w", err)
+	}
+	if c.Population, err = strconv.Atoi(f[7]); err != nil {
+		return c, nil, fmt.Errorf("population: %w", err)
+	}
+
+	var aliases []string
+	if len(f) == 9 && f[8] != "" {
+		aliases = strings.Split(f[8], "|")
+	}
+	return c, aliases, nil
+}
+
+func (g *Gazetteer) add(c domain.City, aliases []string) {
+	i := len(g.cities)
+	g.cities = append(g.cities, c)
+	g.byID[c.ID] = i
+
+	seen := map[string]bool{}
+	for _, name := range append([]string{c.Name, c.NameEn, c.ID}, aliases...) {
+		norm := Normalize(name)
+		if norm == "" || seen[norm] {
+			continue
+		}
+		seen[norm] = true
+		g.keys = append(g.keys, key{norm: norm, city: i})
+		g.byKey[norm] = append(g.byKey[norm], i)
+	}
+}
+
+// Len - число городов в справочнике
+func (g *Gazetteer) Len() int {
+	return len(g.cities)
+}
+
+// Get - город по ID
+func (g *Gazetteer) Get(id string) (domain.City, bool) {
+	i, ok := g.byID[id]
+	if !ok {
+		return domain.City{}, false
+	}
+	return g.cities[i], true
+}
+
+// Lookup - город по точному названию в любом известном написании.
+// Если написание подходит нескольким городам, берется самый крупный.
+func (g *Gazetteer) Lookup(name string) (domain.City, bool) {
+	ids := g.byKey[Normalize(name)]
+	if len(ids) == 0 {
+		return domain.City{}, false
+	}
+	return g.cities[ids[0]], true
+}
+
+// Search - до limit городов для автодополнения по началу названия или
+// любого слова в нем. Сначала точные совпадения, затем совпадения с начала
+// названия, затем по слову; внутри группы - по убыванию населения.
+func (g *Gazetteer) Search(q string, limit int) []domain.City {
+	q = Normalize(q)
+	if q == "" || limit <= 0 {
+		return nil
+	}
+
+	// Лучший ранг города среди всех его написаний: 0 - точное совпадение,
+	// 1 - совпадает начало, 2 - совпадает начало одного из слов
+	rank := map[int]int{}
+	for _, k := range g.keys {
+		r := -1
+		switch {
+		case k.norm == q:
+			r = 0
+		case strings.HasPrefix(k.norm, q):
+			r = 1
+		case strings.Contains(k.norm, " "+q):
+			r = 2
+		}
+		if r < 0 {
+			continue
+		}
+		if best, ok := rank[k.city]; !ok || r < best {
+			rank[k.city] = r
+		}
+	}
+
+	ids := make([]int, 0, len(rank))
+	for i := range rank {
+		ids = append(ids, i)
+	}
+	sort.Slice(ids, func(a, b int) bool {
+		ca, cb := g.cities[ids[a]], g.cities[ids[b]]
+		if rank[ids[a]] != rank[ids[b]] {
+			return rank[ids[a]] < rank[ids[b]]
+		}
+		if ca.Population != cb.Population {
+			return ca.Population > cb.Population
+		}
+		return ca.ID < cb.ID
+	})
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	found := make([]domain.City, len(ids))
+	for n, i := range ids {
+		found[n] = g.cities[i]
+	}
+	return found
+}
+
+// cityPrefixes - слова перед названием, которые не влияют на город ("г. Москва")
+var cityPrefixes = map[string]bool{"г": true, "гор": true, "город": true, "city": true}
+
+// Normalize - приводит написание города к виду для сравнения: нижний регистр,
+// "ё" как "е", знаки препинания и дефисы как пробелы, без "г."/"город" в начале
+func Normalize(s string) string {
+	s = strings.ReplaceAll(strings.ToLower(s), "ё", "е")
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > 1 && cityPrefixes[words[0]] {
+		words = words[1:]
+	}
+	return strings.Join(words, " ")
+}
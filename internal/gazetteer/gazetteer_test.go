@@ -0,0 +1,88 @@
+package gazetteer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefault_Loads(t *testing.T) {
+	g := Default()
+	if g.Len() < 100 {
+		t.Fatalf("Ожидали встроенный справочник, получили %d городов", g.Len())
+	}
+	if c, ok := g.Get("moscow"); !ok || c.Name != "Москва" || c.Country != "RU" {
+		t.Errorf("Неверная Москва: %+v", c)
+	}
+}
+
+func TestLookup_Aliases(t *testing.T) {
+	g := Default()
+	for _, name := range []string{
+		"Санкт-Петербург", "санкт петербург", "СПб", "Питер", "Петербург",
+		"Saint Petersburg", "St. Petersburg", "г. Санкт-Петербург", "Ленинград",
+	} {
+		c, ok := g.Lookup(name)
+		if !ok || c.ID != "saint-petersburg" {
+			t.Errorf("Lookup(%q) = %+v, %v; ожидали saint-petersburg", name, c, ok)
+		}
+	}
+
+	if c, ok := g.Lookup("Орел"); !ok || c.ID != "oryol" {
+		t.Errorf("ё должна совпадать с е, получили %+v", c)
+	}
+	if _, ok := g.Lookup("Урюпинск-на-Марсе"); ok {
+		t.Error("Неизвестный город не должен находиться")
+	}
+}
+
+func TestSearch_Ranking(t *testing.T) {
+	g := Default()
+
+	found := g.Search("ново", 5)
+	if len(found) != 5 || found[0].ID != "novosibirsk" {
+		t.Fatalf("Ожидали 5 городов, первым Новосибирск, получили %+v", found)
+	}
+	for i := 1; i < len(found); i++ {
+		if found[i].Population > found[i-1].Population {
+			t.Errorf("Города одного ранга должны идти по убыванию населения: %+v", found)
+		}
+	}
+
+	// Точное совпадение важнее крупного города с тем же началом
+	if found := g.Search("ош", 3); len(found) == 0 || found[0].ID != "osh" {
+		t.Errorf("Ожидали Ош первым, получили %+v", found)
+	}
+	// Совпадение по слову внутри названия
+	if found := g.Search("новгород", 3); len(found) < 2 {
+		t.Errorf("Ожидали Великий и Нижний Новгород, получили %+v", found)
+	}
+	if found := g.Search("  ", 3); found != nil {
+		t.Errorf("Пустой запрос должен вернуть nil, получили %+v", found)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, src := range []string{
+		"moscow\tМосква\tMoscow\tRU",
+		"moscow\tМосква\tMoscow\tRU\t\tx\t37.6\t1",
+		"moscow\tМосква\tMoscow\tRU\t\t55.7\t37.6\t1\nmoscow\tМосква\tMoscow\tRU\t\t55.7\t37.6\t1",
+	} {
+		if _, err := Parse(strings.NewReader(src)); err == nil {
+			t.Errorf("Ожидали ошибку разбора для %q", src)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	cases := map[string]string{
+		"  Ростов-на-Дону ": "ростов на дону",
+		"г. Москва":         "москва",
+		"Город Королёв":     "королев",
+		"Г":                 "г",
+	}
+	for in, want := range cases {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, ожидали %q", in, got, want)
+		}
+	}
+}
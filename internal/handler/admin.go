@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"bot-api/internal/domain"
+)
+
+// --- Методы APIKey (админка) ---
+
+func (h *Handler) CreateAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	var req domain.APIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidJSON, "error.invalid_json")
+		return
+	}
+
+	issued, err := h.Service.CreateAPIKey(r.Context(), req)
+	if err != nil {
+		handleServiceError(w, r, err, "api_key")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "API key created", "api_key_id", issued.ID, "name", issued.Name)
+	sendJSON(w, r, http.StatusCreated, domain.APIResponse{Status: "created", ID: issued.ID, Data: issued})
+}
+
+func (h *Handler) ListAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.Service.ListAPIKeys(r.Context())
+	if err != nil {
+		handleServiceError(w, r, err, "api_key")
+		return
+	}
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "ok", Data: keys})
+}
+
+func (h *Handler) RevokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidID, "error.invalid_id")
+		return
+	}
+
+	if err := h.Service.RevokeAPIKey(r.Context(), id); err != nil {
+		handleServiceError(w, r, err, "api_key")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "API key revoked", "api_key_id", id)
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "revoked"})
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"bot-api/internal/domain"
+)
+
+// --- Методы Block ---
+
+func (h *Handler) CreateBlockHandler(w http.ResponseWriter, r *http.Request) {
+	var req domain.BlockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidJSON, "error.invalid_json")
+		return
+	}
+
+	b, err := h.Service.Block(r.Context(), req)
+	if err != nil {
+		handleServiceError(w, r, err, "block")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "User blocked", "blocker_tg_id", b.BlockerTgID, "blocked_tg_id", b.BlockedTgID)
+	sendJSON(w, r, http.StatusCreated, domain.APIResponse{Status: "created", Data: b})
+}
+
+// --- Методы Report ---
+
+func (h *Handler) CreateReportHandler(w http.ResponseWriter, r *http.Request) {
+	var req domain.ReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidJSON, "error.invalid_json")
+		return
+	}
+
+	rep, err := h.Service.Report(r.Context(), req)
+	if err != nil {
+		handleServiceError(w, r, err, "report")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Report created", "report_id", rep.ID)
+	sendJSON(w, r, http.StatusCreated, domain.APIResponse{Status: "created", ID: rep.ID, Data: rep})
+}
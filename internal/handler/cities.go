@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"net/http"
+
+	"bot-api/internal/domain"
+)
+
+// --- Методы City ---
+
+// SearchCitiesHandler - автодополнение города: GET /api/v1/cities?q=<начало названия>&limit=
+func (h *Handler) SearchCitiesHandler(w http.ResponseWriter, r *http.Request) {
+	limit, err := queryInt(r, "limit")
+	if err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidParam, "error.invalid_param", "limit")
+		return
+	}
+
+	cities, err := h.Service.SearchCities(r.Context(), r.URL.Query().Get("q"), limit)
+	if err != nil {
+		handleServiceError(w, r, err, "city")
+		return
+	}
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "ok", Data: cities})
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"bot-api/internal/domain"
+)
+
+// queryInt - читает необязательный числовой query-параметр (0, если он не задан)
+func queryInt(r *http.Request, name string) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(v)
+}
+
+// feedFilter - читает параметры ленты из query; при ошибке возвращает имя параметра
+func feedFilter(r *http.Request) (domain.FeedFilter, string, bool) {
+	q := r.URL.Query()
+	f := domain.FeedFilter{Gender: q.Get("gender"), City: q.Get("city"), CityID: q.Get("city_id"), Sort: q.Get("sort"), Goal: q.Get("goal")}
+	if v := q.Get("interests"); v != "" {
+		f.Interests = strings.Split(v, ",")
+	}
+	for name, dst := range map[string]*int{
+		"age_min":           &f.AgeMin,
+		"age_max":           &f.AgeMax,
+		"after":             &f.AfterID,
+		"after_distance_km": &f.AfterDistanceKm,
+		"max_distance_km":   &f.MaxDistanceKm,
+		"limit":             &f.Limit,
+	} {
+		var err error
+		if *dst, err = queryInt(r, name); err != nil {
+			return f, name, false
+		}
+	}
+	if v := q.Get("after_score"); v != "" {
+		var err error
+		if f.AfterScore, err = strconv.ParseFloat(v, 64); err != nil {
+			return f, "after_score", false
+		}
+	}
+	return f, "", true
+}
+
+// --- Методы Feed ---
+
+// GetFeedHandler - лента пользователя. Порядок и следующая страница зависят от sort:
+// по умолчанию - по ID анкеты, ?after=<ID последней>; sort=distance - от ближних
+// к дальним, ?after_distance_km=<расстояние последней>&after=<ее ID>; sort=score - лучшие
+// по оценке совместимости, ?after_score=<score последней>&after=<ее ID>.
+func (h *Handler) GetFeedHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidID, "error.invalid_id")
+		return
+	}
+
+	f, bad, ok := feedFilter(r)
+	if !ok {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidParam, "error.invalid_param", bad)
+		return
+	}
+
+	feed, err := h.Service.GetFeed(r.Context(), id, f)
+	if err != nil {
+		handleServiceError(w, r, err, "user")
+		return
+	}
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "ok", Data: feed})
+}
+
+// ExplainFeedHandler - лента пользователя в порядке ранжирования с разбором оценок (админка)
+func (h *Handler) ExplainFeedHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidID, "error.invalid_id")
+		return
+	}
+
+	f, bad, ok := feedFilter(r)
+	if !ok {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidParam, "error.invalid_param", bad)
+		return
+	}
+
+	ranked, err := h.Service.ExplainFeed(r.Context(), id, f)
+	if err != nil {
+		handleServiceError(w, r, err, "user")
+		return
+	}
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "ok", Data: ranked})
+}
@@ -3,18 +3,20 @@ package handler
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"strconv"
 
 	"bot-api/internal/domain"
+	"bot-api/internal/health"
+	"bot-api/internal/i18n"
 	"bot-api/internal/service"
 )
 
 // Handler - структура для DI
 type Handler struct {
 	Service service.UserService
+	Health  *health.Checker // состояние процесса для /readyz и /version; nil - всегда готов
 }
 
 func NewHandler(svc service.UserService) *Handler {
@@ -22,34 +24,80 @@ func NewHandler(svc service.UserService) *Handler {
 }
 
 // sendJSON - Хелпер для отправки JSON-ответа
-func sendJSON(w http.ResponseWriter, status int, payload interface{}) {
+func sendJSON(w http.ResponseWriter, r *http.Request, status int, payload interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(payload); err != nil {
-		log.Printf("Error encoding JSON response: %v", err)
+		slog.WarnContext(r.Context(), "Error encoding JSON response", "error", err)
 	}
 }
 
-// handleServiceError - централизованная функция для обработки ошибок Service
-func handleServiceError(w http.ResponseWriter, err error, resourceName string) {
-	log.Printf("WARNING: %s operation failed: %v", resourceName, err)
+// requestLang - язык ответа: настройка пользователя из контекста (см. Auth)
+// или лучший поддерживаемый язык из Accept-Language
+func requestLang(r *http.Request) string {
+	if lang, ok := i18n.LangFromContext(r.Context()); ok {
+		return lang
+	}
+	return i18n.Negotiate(r.Header.Get("Accept-Language"))
+}
+
+// sendError - ответ с ошибкой: code - domain.ErrCode*, key и args - сообщение из каталога i18n
+func sendError(w http.ResponseWriter, r *http.Request, status int, code, key string, args ...any) {
+	sendJSON(w, r, status, domain.APIResponse{Status: "error", Code: code, Error: i18n.T(requestLang(r), key, args...)})
+}
+
+// handleServiceError - централизованная функция для обработки ошибок Service.
+// resource уточняет сообщение: "error.not_found.<resource>" и т.п.
+func handleServiceError(w http.ResponseWriter, r *http.Request, err error, resource string) {
+	// Ошибки клиента (не найдено, валидация и т.п.) - предупреждение, остальные - ошибка сервера
+	kind := service.ErrorKind(err)
+	level := slog.LevelWarn
+	if kind == domain.ErrCodeInternal {
+		level = slog.LevelError
+	}
+	slog.Log(r.Context(), level, "Service operation failed", "resource", resource, "kind", kind, "error", err)
 
 	if errors.Is(err, service.ErrNotFound) {
-		sendJSON(w, http.StatusNotFound, domain.APIResponse{
-			Status: "error", Error: fmt.Sprintf("%s не найден", resourceName),
+		sendError(w, r, http.StatusNotFound, domain.ErrCodeNotFound, "error.not_found."+resource)
+		return
+	}
+	if errors.Is(err, service.ErrUnauthorized) {
+		sendError(w, r, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "error.unauthorized")
+		return
+	}
+	if errors.Is(err, service.ErrUserBanned) {
+		sendError(w, r, http.StatusForbidden, domain.ErrCodeUserBanned, "error.user_banned")
+		return
+	}
+	if errors.Is(err, service.ErrForbidden) {
+		sendError(w, r, http.StatusForbidden, domain.ErrCodeForbidden, "error.forbidden")
+		return
+	}
+	if errors.Is(err, service.ErrAlreadyExists) {
+		sendError(w, r, http.StatusConflict, domain.ErrCodeAlreadyExists, "error.already_exists."+resource)
+		return
+	}
+	var verr *service.ValidationError
+	if errors.As(err, &verr) {
+		lang := requestLang(r)
+		violations := make([]domain.Violation, len(verr.Violations))
+		for i, v := range verr.Violations {
+			if v.Key != "" {
+				v.Message = i18n.T(lang, v.Key, v.Args...)
+			}
+			violations[i] = v
+		}
+		sendJSON(w, r, http.StatusBadRequest, domain.APIResponse{
+			Status: "error", Code: domain.ErrCodeValidation, Error: i18n.T(lang, "error.validation_failed"), Violations: violations,
 		})
 		return
 	}
 	if errors.Is(err, service.ErrValidationFailed) {
-		sendJSON(w, http.StatusBadRequest, domain.APIResponse{
-			Status: "error", Error: "Ошибка валидации: " + err.Error(),
-		})
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeValidation, "error.validation_failed")
 		return
 	}
 
-	sendJSON(w, http.StatusInternalServerError, domain.APIResponse{
-		Status: "error", Error: "Внутренняя ошибка сервера",
-	})
+	sendError(w, r, http.StatusInternalServerError, domain.ErrCodeInternal, "error.internal")
 }
 
 // --- Методы User с экспортированными именами ---
@@ -57,57 +105,57 @@ func handleServiceError(w http.ResponseWriter, err error, resourceName string) {
 func (h *Handler) CreateUserHandler(w http.ResponseWriter, r *http.Request) { // Изменено
 	var req domain.UserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		sendJSON(w, http.StatusBadRequest, domain.APIResponse{Status: "error", Error: "Неверный JSON формат"})
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidJSON, "error.invalid_json")
 		return
 	}
 
 	newID, err := h.Service.InsertUser(r.Context(), req)
 	if err != nil {
-		handleServiceError(w, err, "юзер")
+		handleServiceError(w, r, err, "user")
 		return
 	}
 
-	log.Printf("INFO: User created ID: %d", newID)
-	sendJSON(w, http.StatusCreated, domain.APIResponse{Status: "created", ID: newID})
+	slog.InfoContext(r.Context(), "User created", "tg_id", newID)
+	sendJSON(w, r, http.StatusCreated, domain.APIResponse{Status: "created", ID: newID})
 }
 
 func (h *Handler) GetUserHandler(w http.ResponseWriter, r *http.Request) { // Изменено
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		sendJSON(w, http.StatusBadRequest, domain.APIResponse{Status: "error", Error: "ID должен быть числом"})
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidID, "error.invalid_id")
 		return
 	}
 
 	u, err := h.Service.GetUser(r.Context(), id)
 	if err != nil {
-		handleServiceError(w, err, "юзер")
+		handleServiceError(w, r, err, "user")
 		return
 	}
 
-	sendJSON(w, http.StatusOK, domain.APIResponse{Status: "ok", Data: u})
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "ok", Data: u})
 }
 
 func (h *Handler) UpdateUserHandler(w http.ResponseWriter, r *http.Request) { // Изменено
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		sendJSON(w, http.StatusBadRequest, domain.APIResponse{Status: "error", Error: "Неверный ID"})
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidID, "error.invalid_id")
 		return
 	}
 
 	var req domain.UserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		sendJSON(w, http.StatusBadRequest, domain.APIResponse{Status: "error", Error: "JSON ошибка"})
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidJSON, "error.invalid_json")
 		return
 	}
 
 	if err := h.Service.UpdateUser(r.Context(), id, req); err != nil {
-		handleServiceError(w, err, "юзер")
+		handleServiceError(w, r, err, "user")
 		return
 	}
 
-	sendJSON(w, http.StatusOK, domain.APIResponse{Status: "updated"})
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "updated"})
 }
 
 // --- Методы Anquette с экспортированными именами ---
@@ -115,68 +163,68 @@ func (h *Handler) UpdateUserHandler(w http.ResponseWriter, r *http.Request) { //
 func (h *Handler) CreateAnquetteHandler(w http.ResponseWriter, r *http.Request) { // Изменено
 	var req domain.AnquetteRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		sendJSON(w, http.StatusBadRequest, domain.APIResponse{Status: "error", Error: "Неверный JSON"})
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidJSON, "error.invalid_json")
 		return
 	}
 
 	newID, err := h.Service.InsertAnquette(r.Context(), req)
 	if err != nil {
-		handleServiceError(w, err, "анкета")
+		handleServiceError(w, r, err, "anquette")
 		return
 	}
 
-	log.Printf("INFO: Anquette created ID: %d", newID)
-	sendJSON(w, http.StatusCreated, domain.APIResponse{Status: "created", ID: newID})
+	slog.InfoContext(r.Context(), "Anquette created", "anquette_id", newID)
+	sendJSON(w, r, http.StatusCreated, domain.APIResponse{Status: "created", ID: newID})
 }
 
 func (h *Handler) GetAnquetteHandler(w http.ResponseWriter, r *http.Request) { // Изменено
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		sendJSON(w, http.StatusBadRequest, domain.APIResponse{Status: "error", Error: "ID должен быть числом"})
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidID, "error.invalid_id")
 		return
 	}
 
 	a, err := h.Service.GetAnquette(r.Context(), id)
 	if err != nil {
-		handleServiceError(w, err, "анкета")
+		handleServiceError(w, r, err, "anquette")
 		return
 	}
-	sendJSON(w, http.StatusOK, domain.APIResponse{Status: "ok", Data: a})
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "ok", Data: a})
 }
 
 func (h *Handler) UpdateAnquetteHandler(w http.ResponseWriter, r *http.Request) { // Изменено
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		sendJSON(w, http.StatusBadRequest, domain.APIResponse{Status: "error", Error: "Неверный ID"})
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidID, "error.invalid_id")
 		return
 	}
 
 	var req domain.AnquetteRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		sendJSON(w, http.StatusBadRequest, domain.APIResponse{Status: "error", Error: "Ошибка JSON"})
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidJSON, "error.invalid_json")
 		return
 	}
 
 	if err := h.Service.UpdateAnquette(r.Context(), id, req); err != nil {
-		handleServiceError(w, err, "анкета")
+		handleServiceError(w, r, err, "anquette")
 		return
 	}
-	sendJSON(w, http.StatusOK, domain.APIResponse{Status: "updated"})
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "updated"})
 }
 
 func (h *Handler) DeleteAnquetteHandler(w http.ResponseWriter, r *http.Request) { // Изменено
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		sendJSON(w, http.StatusBadRequest, domain.APIResponse{Status: "error", Error: "ID должен быть числом"})
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidID, "error.invalid_id")
 		return
 	}
 
 	if err := h.Service.DeleteAnquette(r.Context(), id); err != nil {
-		handleServiceError(w, err, "анкета")
+		handleServiceError(w, r, err, "anquette")
 		return
 	}
-	sendJSON(w, http.StatusOK, domain.APIResponse{Status: "deleted"})
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "deleted"})
 }
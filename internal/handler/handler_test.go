@@ -3,14 +3,30 @@ package handler_test
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"bot-api/internal/auth"
 	"bot-api/internal/domain"
 	"bot-api/internal/handler"
+	"bot-api/internal/health"
+	"bot-api/internal/logging"
+	"bot-api/internal/metrics"
 	"bot-api/internal/service"
 )
 
@@ -25,6 +41,26 @@ type MockService struct {
 	GetAnquetteFunc    func(ctx context.Context, id int) (domain.Anquette, error)
 	UpdateAnquetteFunc func(ctx context.Context, id int, req domain.AnquetteRequest) error
 	DeleteAnquetteFunc func(ctx context.Context, id int) error
+	GetFeedFunc        func(ctx context.Context, tgID int, f domain.FeedFilter) ([]domain.FeedItem, error)
+	ExplainFeedFunc    func(ctx context.Context, tgID int, f domain.FeedFilter) ([]domain.RankedFeedItem, error)
+
+	SetAnquetteInterestsFunc func(ctx context.Context, id int, req domain.InterestsRequest) error
+	ReactFunc                func(ctx context.Context, fromTgID int64, toAnquetteID int, kind string) (domain.ReactionResult, error)
+	ListMatchesFunc          func(ctx context.Context, tgID int, beforeID, limit int) ([]domain.MatchView, error)
+	DeleteMatchFunc          func(ctx context.Context, id int) error
+	BlockFunc                func(ctx context.Context, req domain.BlockRequest) (domain.Block, error)
+	ReportFunc               func(ctx context.Context, req domain.ReportRequest) (domain.Report, error)
+	ModerateFunc             func(ctx context.Context, req domain.ModerationRequest) (domain.ModerationDecision, error)
+
+	AuthenticateAPIKeyFunc  func(ctx context.Context, key string) (domain.APIKey, error)
+	UploadPhotoFunc         func(ctx context.Context, anquetteID int, data []byte) (domain.Photo, error)
+	SetAnquetteLocationFunc func(ctx context.Context, id int, req domain.LocationRequest) error
+	SearchCitiesFunc        func(ctx context.Context, q string, limit int) ([]domain.City, error)
+
+	ListUsersFunc     func(ctx context.Context, f domain.ListFilter) (domain.Page[domain.User], error)
+	ListAnquettesFunc func(ctx context.Context, f domain.ListFilter) (domain.Page[domain.Anquette], error)
+
+	SearchAnquettesFunc func(ctx context.Context, f domain.SearchFilter) ([]domain.SearchHit, error)
 }
 
 func (m *MockService) InsertUser(ctx context.Context, req domain.UserRequest) (int, error) {
@@ -48,6 +84,54 @@ func (m *MockService) UpdateAnquette(ctx context.Context, id int, req domain.Anq
 func (m *MockService) DeleteAnquette(ctx context.Context, id int) error {
 	return m.DeleteAnquetteFunc(ctx, id)
 }
+func (m *MockService) SetAnquetteLocation(ctx context.Context, id int, req domain.LocationRequest) error {
+	return m.SetAnquetteLocationFunc(ctx, id, req)
+}
+func (m *MockService) SearchCities(ctx context.Context, q string, limit int) ([]domain.City, error) {
+	return m.SearchCitiesFunc(ctx, q, limit)
+}
+func (m *MockService) ListUsers(ctx context.Context, f domain.ListFilter) (domain.Page[domain.User], error) {
+	return m.ListUsersFunc(ctx, f)
+}
+func (m *MockService) ListAnquettes(ctx context.Context, f domain.ListFilter) (domain.Page[domain.Anquette], error) {
+	return m.ListAnquettesFunc(ctx, f)
+}
+func (m *MockService) SearchAnquettes(ctx context.Context, f domain.SearchFilter) ([]domain.SearchHit, error) {
+	return m.SearchAnquettesFunc(ctx, f)
+}
+func (m *MockService) GetFeed(ctx context.Context, tgID int, f domain.FeedFilter) ([]domain.FeedItem, error) {
+	return m.GetFeedFunc(ctx, tgID, f)
+}
+func (m *MockService) SetAnquetteInterests(ctx context.Context, id int, req domain.InterestsRequest) error {
+	return m.SetAnquetteInterestsFunc(ctx, id, req)
+}
+func (m *MockService) ExplainFeed(ctx context.Context, tgID int, f domain.FeedFilter) ([]domain.RankedFeedItem, error) {
+	return m.ExplainFeedFunc(ctx, tgID, f)
+}
+func (m *MockService) React(ctx context.Context, fromTgID int64, toAnquetteID int, kind string) (domain.ReactionResult, error) {
+	return m.ReactFunc(ctx, fromTgID, toAnquetteID, kind)
+}
+func (m *MockService) ListMatches(ctx context.Context, tgID int, beforeID, limit int) ([]domain.MatchView, error) {
+	return m.ListMatchesFunc(ctx, tgID, beforeID, limit)
+}
+func (m *MockService) DeleteMatch(ctx context.Context, id int) error {
+	return m.DeleteMatchFunc(ctx, id)
+}
+func (m *MockService) Block(ctx context.Context, req domain.BlockRequest) (domain.Block, error) {
+	return m.BlockFunc(ctx, req)
+}
+func (m *MockService) Report(ctx context.Context, req domain.ReportRequest) (domain.Report, error) {
+	return m.ReportFunc(ctx, req)
+}
+func (m *MockService) Moderate(ctx context.Context, req domain.ModerationRequest) (domain.ModerationDecision, error) {
+	return m.ModerateFunc(ctx, req)
+}
+func (m *MockService) UploadPhoto(ctx context.Context, anquetteID int, data []byte) (domain.Photo, error) {
+	return m.UploadPhotoFunc(ctx, anquetteID, data)
+}
+func (m *MockService) AuthenticateAPIKey(ctx context.Context, key string) (domain.APIKey, error) {
+	return m.AuthenticateAPIKeyFunc(ctx, key)
+}
 
 // checkResponseCode - Хелпер для проверки HTTP-кода
 func checkResponseCode(t *testing.T, expected, actual int) {
@@ -146,6 +230,42 @@ func TestCreateAnquetteHandler_ValidationFail(t *testing.T) {
 	checkResponseCode(t, http.StatusBadRequest, rr.Code)
 }
 
+func TestCreateAnquetteHandler_LegacyPreferences(t *testing.T) {
+	var got domain.Preferences
+	mockSvc := &MockService{
+		InsertAnquetteFunc: func(ctx context.Context, req domain.AnquetteRequest) (int, error) {
+			got = req.Preferences
+			return 1, nil
+		},
+	}
+	h := handler.NewHandler(mockSvc)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/anquettes", h.CreateAnquetteHandler)
+
+	for body, want := range map[string]domain.Preferences{
+		// Старые клиенты присылают предпочтения строкой
+		`{"name": "Анна", "preferences": "Ж"}`:                                                {Text: "Ж"},
+		`{"name": "Анна", "preferences": {"genders": ["f"], "text": "без вредных привычек"}}`: {Genders: []string{"f"}, Text: "без вредных привычек"},
+		`{"name": "Анна"}`: {},
+	} {
+		got = domain.Preferences{}
+		req, _ := http.NewRequest("POST", "/api/v1/anquettes", bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+
+		checkResponseCode(t, http.StatusCreated, rr.Code)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("%s: ожидали предпочтения %+v, получили %+v", body, want, got)
+		}
+	}
+
+	// Ни строка, ни объект - ошибка разбора тела
+	req, _ := http.NewRequest("POST", "/api/v1/anquettes", bytes.NewBufferString(`{"name": "Анна", "preferences": 5}`))
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	checkResponseCode(t, http.StatusBadRequest, rr.Code)
+}
+
 func TestDeleteAnquetteHandler_Success(t *testing.T) {
 	mockSvc := &MockService{
 		DeleteAnquetteFunc: func(ctx context.Context, id int) error {
@@ -197,3 +317,825 @@ func TestDeleteAnquetteHandler_InternalError(t *testing.T) {
 	// Ожидаем 500 Internal Server Error, так как это не ErrNotFound и не ErrValidationFailed
 	checkResponseCode(t, http.StatusInternalServerError, rr.Code)
 }
+
+// --- ТЕСТЫ LISTS ---
+
+func TestListAnquettesHandler_Success(t *testing.T) {
+	var gotFilter domain.ListFilter
+	mockSvc := &MockService{
+		ListAnquettesFunc: func(ctx context.Context, f domain.ListFilter) (domain.Page[domain.Anquette], error) {
+			gotFilter = f
+			return domain.Page[domain.Anquette]{Items: []domain.Anquette{{ID: 2}}, NextCursor: "abc"}, nil
+		},
+	}
+	h := handler.NewHandler(mockSvc)
+
+	req, _ := http.NewRequest("GET", "/api/v1/anquettes?city=Казань&gender=f&age_min=18&age_max=25&created_after=2026-10-01&sort=-age&cursor=xyz&limit=5", nil)
+	rr := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/anquettes", h.ListAnquettesHandler)
+	mux.ServeHTTP(rr, req)
+
+	checkResponseCode(t, http.StatusOK, rr.Code)
+
+	after := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	want := domain.ListFilter{City: "Казань", Gender: "f", AgeMin: 18, AgeMax: 25, CreatedAfter: &after, Sort: "-age", Cursor: "xyz", Limit: 5}
+	if !reflect.DeepEqual(gotFilter, want) {
+		t.Errorf("Ожидали фильтр %+v, получили %+v", want, gotFilter)
+	}
+	if body := rr.Body.String(); !strings.Contains(body, `"next_cursor":"abc"`) {
+		t.Errorf("Ожидали курсор следующей страницы: %s", body)
+	}
+}
+
+func TestListUsersHandler_BadDate(t *testing.T) {
+	h := handler.NewHandler(&MockService{})
+
+	req, _ := http.NewRequest("GET", "/api/v1/users?created_after=вчера", nil)
+	rr := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/users", h.ListUsersHandler)
+	mux.ServeHTTP(rr, req)
+
+	checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	if body := rr.Body.String(); !strings.Contains(body, "created_after") {
+		t.Errorf("Ожидали имя параметра в ошибке: %s", body)
+	}
+}
+
+// --- ТЕСТЫ SEARCH ---
+
+func TestSearchAnquettesHandler_Success(t *testing.T) {
+	var gotFilter domain.SearchFilter
+	mockSvc := &MockService{
+		SearchAnquettesFunc: func(ctx context.Context, f domain.SearchFilter) ([]domain.SearchHit, error) {
+			gotFilter = f
+			return []domain.SearchHit{{Anquette: domain.Anquette{ID: 2}, Snippet: "<mark>музыка</mark>", Rank: 1.5}}, nil
+		},
+	}
+	h := handler.NewHandler(mockSvc)
+
+	req, _ := http.NewRequest("GET", "/api/v1/anquettes/search?q=%D0%BC%D1%83%D0%B7%D1%8B%D0%BA%D0%B0&status=pending&tg_id=7&limit=5", nil)
+	rr := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/anquettes/search", h.SearchAnquettesHandler)
+	mux.HandleFunc("GET /api/v1/anquettes/{id}", h.GetAnquetteHandler)
+	mux.ServeHTTP(rr, req)
+
+	checkResponseCode(t, http.StatusOK, rr.Code)
+	if want := (domain.SearchFilter{Query: "музыка", Status: "pending", ViewerTgID: 7, Limit: 5}); gotFilter != want {
+		t.Errorf("Ожидали фильтр %+v, получили %+v", want, gotFilter)
+	}
+	if body := rr.Body.String(); !strings.Contains(body, `"snippet":"\u003cmark\u003eмузыка`) {
+		t.Errorf("Ожидали фрагмент с выделением: %s", body)
+	}
+}
+
+// --- ТЕСТЫ FEED ---
+
+func TestGetFeedHandler_Success(t *testing.T) {
+	var gotFilter domain.FeedFilter
+	mockSvc := &MockService{
+		GetFeedFunc: func(ctx context.Context, tgID int, f domain.FeedFilter) ([]domain.FeedItem, error) {
+			gotFilter = f
+			return []domain.FeedItem{{Anquette: domain.Anquette{ID: 2, Name: "Кандидат", Location: &domain.GeoPoint{Lat: 55.75, Lon: 37.62}}, DistanceKm: 3}}, nil
+		},
+	}
+	h := handler.NewHandler(mockSvc)
+
+	req, _ := http.NewRequest("GET", "/api/v1/users/123/feed?gender=f&age_min=18&age_max=25&after=1&limit=5&max_distance_km=30&sort=distance&after_distance_km=2&interests=music,it", nil)
+	rr := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/users/{id}/feed", h.GetFeedHandler)
+	mux.ServeHTTP(rr, req)
+
+	checkResponseCode(t, http.StatusOK, rr.Code)
+
+	want := domain.FeedFilter{
+		Gender: "f", AgeMin: 18, AgeMax: 25, AfterID: 1, Limit: 5,
+		MaxDistanceKm: 30, Sort: domain.FeedSortDistance, AfterDistanceKm: 2,
+		Interests: []string{"music", "it"},
+	}
+	if !reflect.DeepEqual(gotFilter, want) {
+		t.Errorf("Ожидали фильтр %+v, получили %+v", want, gotFilter)
+	}
+
+	// Наружу уходит только округленное расстояние, без координат
+	body := rr.Body.String()
+	if !strings.Contains(body, `"distance_km":3`) || strings.Contains(body, "55.75") {
+		t.Errorf("Неверная анкета в ленте: %s", body)
+	}
+}
+
+func TestGetFeedHandler_InvalidAfterDistance(t *testing.T) {
+	h := handler.NewHandler(&MockService{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/users/{id}/feed", h.GetFeedHandler)
+
+	// Ошибка называет параметр так же, как нарушение валидации
+	req, _ := http.NewRequest("GET", "/api/v1/users/123/feed?sort=distance&after_distance_km=far", nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	if !strings.Contains(rr.Body.String(), "after_distance_km") {
+		t.Errorf("Ожидали ошибку параметра after_distance_km: %s", rr.Body.String())
+	}
+}
+
+func TestGetFeedHandler_BadQuery(t *testing.T) {
+	h := handler.NewHandler(&MockService{})
+
+	req, _ := http.NewRequest("GET", "/api/v1/users/123/feed?limit=many", nil)
+	rr := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/users/{id}/feed", h.GetFeedHandler)
+	mux.ServeHTTP(rr, req)
+
+	checkResponseCode(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestExplainFeedHandler_Success(t *testing.T) {
+	var gotID int
+	var gotFilter domain.FeedFilter
+	mockSvc := &MockService{
+		ExplainFeedFunc: func(ctx context.Context, tgID int, f domain.FeedFilter) ([]domain.RankedFeedItem, error) {
+			gotID, gotFilter = tgID, f
+			return []domain.RankedFeedItem{{
+				FeedItem: domain.FeedItem{Anquette: domain.Anquette{ID: 2}, Score: 0.5},
+				Signals:  []domain.SignalScore{{Signal: "distance", Value: 0.5, Weight: 1, Contribution: 0.5}},
+			}}, nil
+		},
+	}
+	h := handler.NewHandler(mockSvc)
+
+	req, _ := http.NewRequest("GET", "/api/v1/admin/users/123/feed/explain?limit=5&after_score=0.75&after=10", nil)
+	rr := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/admin/users/{id}/feed/explain", h.ExplainFeedHandler)
+	mux.ServeHTTP(rr, req)
+
+	checkResponseCode(t, http.StatusOK, rr.Code)
+	body := rr.Body.String()
+	if gotID != 123 || gotFilter.AfterScore != 0.75 || gotFilter.AfterID != 10 || !strings.Contains(body, `"score":0.5`) || !strings.Contains(body, `"signal":"distance"`) {
+		t.Errorf("Неверный ответ: %s", body)
+	}
+}
+
+// --- ТЕСТЫ INTERESTS ---
+
+func TestSetAnquetteInterestsHandler_Validation(t *testing.T) {
+	var got domain.InterestsRequest
+	mockSvc := &MockService{
+		SetAnquetteInterestsFunc: func(ctx context.Context, id int, req domain.InterestsRequest) error {
+			got = req
+			return &service.ValidationError{Violations: []domain.Violation{
+				{Field: "interests", Code: domain.CodeInvalid, Key: "violation.interests.unknown", Args: []any{"knitting"}},
+			}}
+		},
+	}
+	h := handler.NewHandler(mockSvc)
+
+	req, _ := http.NewRequest("PUT", "/api/v1/anquettes/10/interests", bytes.NewBufferString(`{"interests": ["music", "knitting"]}`))
+	req.Header.Set("Accept-Language", "en")
+	rr := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /api/v1/anquettes/{id}/interests", h.SetAnquetteInterestsHandler)
+	mux.ServeHTTP(rr, req)
+
+	checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	if len(got.Interests) != 2 || got.Interests[1] != "knitting" {
+		t.Errorf("Неверный запрос в сервис: %+v", got)
+	}
+	if body := rr.Body.String(); !strings.Contains(body, "Unknown interest: knitting") {
+		t.Errorf("Ожидали сообщение о неизвестном интересе, получили %s", body)
+	}
+}
+
+// --- ТЕСТЫ LOCATION ---
+
+func TestSetAnquetteLocationHandler_Success(t *testing.T) {
+	var got domain.LocationRequest
+	mockSvc := &MockService{
+		SetAnquetteLocationFunc: func(ctx context.Context, id int, req domain.LocationRequest) error {
+			got = req
+			return nil
+		},
+	}
+	h := handler.NewHandler(mockSvc)
+
+	// Тело - Location из Telegram Bot API, лишние поля игнорируются
+	reqBody := `{"latitude": 44.7235, "longitude": 37.7686, "horizontal_accuracy": 15}`
+	req, _ := http.NewRequest("PUT", "/api/v1/anquettes/10/location", bytes.NewBufferString(reqBody))
+	rr := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /api/v1/anquettes/{id}/location", h.SetAnquetteLocationHandler)
+	mux.ServeHTTP(rr, req)
+
+	checkResponseCode(t, http.StatusOK, rr.Code)
+	if got.Latitude != 44.7235 || got.Longitude != 37.7686 {
+		t.Errorf("Неверные координаты: %+v", got)
+	}
+}
+
+// --- ТЕСТЫ CITY ---
+
+func TestSearchCitiesHandler_Success(t *testing.T) {
+	var gotQ string
+	var gotLimit int
+	mockSvc := &MockService{
+		SearchCitiesFunc: func(ctx context.Context, q string, limit int) ([]domain.City, error) {
+			gotQ, gotLimit = q, limit
+			return []domain.City{{ID: "saint-petersburg", Name: "Санкт-Петербург"}}, nil
+		},
+	}
+	h := handler.NewHandler(mockSvc)
+
+	req, _ := http.NewRequest("GET", "/api/v1/cities?q=%D0%BF%D0%B8%D1%82&limit=5", nil)
+	rr := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/cities", h.SearchCitiesHandler)
+	mux.ServeHTTP(rr, req)
+
+	checkResponseCode(t, http.StatusOK, rr.Code)
+	if gotQ != "пит" || gotLimit != 5 {
+		t.Errorf("Неверные параметры поиска: %q, %d", gotQ, gotLimit)
+	}
+	if !strings.Contains(rr.Body.String(), `"id":"saint-petersburg"`) {
+		t.Errorf("Ожидали город в ответе, получили %s", rr.Body.String())
+	}
+}
+
+// --- ТЕСТЫ REACTION ---
+
+func TestCreateReactionHandler_Match(t *testing.T) {
+	reqBody := `{"tg_id": 1, "anquette_id": 20, "kind": "like"}`
+	mockSvc := &MockService{
+		ReactFunc: func(ctx context.Context, fromTgID int64, toAnquetteID int, kind string) (domain.ReactionResult, error) {
+			return domain.ReactionResult{
+				Reaction: domain.Reaction{ID: 4, FromTgID: fromTgID, ToAnquetteID: toAnquetteID, Kind: kind},
+				Match:    &domain.Match{ID: 9, UserTgID1: 1, UserTgID2: 2},
+			}, nil
+		},
+	}
+	h := handler.NewHandler(mockSvc)
+
+	req, _ := http.NewRequest("POST", "/api/v1/reactions", bytes.NewBufferString(reqBody))
+	rr := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/reactions", h.CreateReactionHandler)
+	mux.ServeHTTP(rr, req)
+
+	checkResponseCode(t, http.StatusCreated, rr.Code)
+
+	var resp struct {
+		ID   int                   `json:"id"`
+		Data domain.ReactionResult `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Ошибка парсинга JSON: %v", err)
+	}
+
+	if resp.ID != 4 || resp.Data.Match == nil || resp.Data.Match.ID != 9 {
+		t.Errorf("Ожидали реакцию 4 с мэтчем 9, получили %+v", resp)
+	}
+}
+
+func TestCreateReactionHandler_AlreadyRated(t *testing.T) {
+	reqBody := `{"tg_id": 1, "anquette_id": 20, "kind": "dislike"}`
+	mockSvc := &MockService{
+		ReactFunc: func(ctx context.Context, fromTgID int64, toAnquetteID int, kind string) (domain.ReactionResult, error) {
+			return domain.ReactionResult{}, service.ErrAlreadyExists
+		},
+	}
+	h := handler.NewHandler(mockSvc)
+
+	req, _ := http.NewRequest("POST", "/api/v1/reactions", bytes.NewBufferString(reqBody))
+	rr := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/reactions", h.CreateReactionHandler)
+	mux.ServeHTTP(rr, req)
+
+	checkResponseCode(t, http.StatusConflict, rr.Code)
+}
+
+func TestCreateAnquetteHandler_Violations(t *testing.T) {
+	mockSvc := &MockService{
+		InsertAnquetteFunc: func(ctx context.Context, req domain.AnquetteRequest) (int, error) {
+			return 0, fmt.Errorf("service: wrapped: %w", &service.ValidationError{Violations: []domain.Violation{
+				{Field: "age", Code: domain.CodeOutOfRange, Message: "Возраст должен быть от 18 до 100 лет"},
+			}})
+		},
+	}
+	h := handler.NewHandler(mockSvc)
+
+	req, _ := http.NewRequest("POST", "/api/v1/anquettes", bytes.NewBufferString(`{"age": 12}`))
+	rr := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/anquettes", h.CreateAnquetteHandler)
+	mux.ServeHTTP(rr, req)
+
+	checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	var resp domain.APIResponse
+	json.NewDecoder(rr.Body).Decode(&resp)
+	if len(resp.Violations) != 1 || resp.Violations[0].Field != "age" || resp.Violations[0].Code != domain.CodeOutOfRange {
+		t.Errorf("Ожидали нарушение в поле age, получили %+v", resp)
+	}
+}
+
+func TestCreateAnquetteHandler_ViolationsLocalized(t *testing.T) {
+	mockSvc := &MockService{
+		InsertAnquetteFunc: func(ctx context.Context, req domain.AnquetteRequest) (int, error) {
+			return 0, &service.ValidationError{Violations: []domain.Violation{
+				{Field: "age", Code: domain.CodeOutOfRange, Key: "violation.age.out_of_range", Args: []any{18, 100}},
+			}}
+		},
+	}
+	h := handler.NewHandler(mockSvc)
+
+	req, _ := http.NewRequest("POST", "/api/v1/anquettes", bytes.NewBufferString(`{"age": 12}`))
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9,ru;q=0.8")
+	rr := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/anquettes", h.CreateAnquetteHandler)
+	mux.ServeHTTP(rr, req)
+
+	checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	var resp domain.APIResponse
+	json.NewDecoder(rr.Body).Decode(&resp)
+	if resp.Code != domain.ErrCodeValidation || resp.Error != "Validation failed" {
+		t.Errorf("Ожидали validation_failed на английском, получили %+v", resp)
+	}
+	if len(resp.Violations) != 1 || resp.Violations[0].Message != "Age must be between 18 and 100" {
+		t.Errorf("Ожидали сообщение на английском, получили %+v", resp.Violations)
+	}
+}
+
+func TestGetUserHandler_NotFoundLocalized(t *testing.T) {
+	mockSvc := &MockService{
+		GetUserFunc: func(ctx context.Context, id int) (domain.User, error) {
+			return domain.User{}, service.ErrNotFound
+		},
+	}
+	h := handler.NewHandler(mockSvc)
+
+	for _, tc := range []struct{ lang, want string }{
+		{"", "Пользователь не найден"},
+		{"de, en;q=0.5", "User not found"},
+	} {
+		req, _ := http.NewRequest("GET", "/api/v1/users/1", nil)
+		if tc.lang != "" {
+			req.Header.Set("Accept-Language", tc.lang)
+		}
+		rr := httptest.NewRecorder()
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /api/v1/users/{id}", h.GetUserHandler)
+		mux.ServeHTTP(rr, req)
+
+		checkResponseCode(t, http.StatusNotFound, rr.Code)
+		var resp domain.APIResponse
+		json.NewDecoder(rr.Body).Decode(&resp)
+		if resp.Code != domain.ErrCodeNotFound || resp.Error != tc.want {
+			t.Errorf("Accept-Language %q: ожидали %q, получили %+v", tc.lang, tc.want, resp)
+		}
+	}
+}
+
+// --- ТЕСТЫ BLOCK / REPORT ---
+
+func TestCreateBlockHandler_Success(t *testing.T) {
+	reqBody := `{"tg_id": 1, "anquette_id": 20}`
+	mockSvc := &MockService{
+		BlockFunc: func(ctx context.Context, req domain.BlockRequest) (domain.Block, error) {
+			return domain.Block{BlockerTgID: req.TgID, BlockedTgID: 2}, nil
+		},
+	}
+	h := handler.NewHandler(mockSvc)
+
+	req, _ := http.NewRequest("POST", "/api/v1/blocks", bytes.NewBufferString(reqBody))
+	rr := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/blocks", h.CreateBlockHandler)
+	mux.ServeHTTP(rr, req)
+
+	checkResponseCode(t, http.StatusCreated, rr.Code)
+}
+
+func TestCreateReportHandler_BadReason(t *testing.T) {
+	reqBody := `{"tg_id": 1, "target_tg_id": 2, "reason": "boring"}`
+	mockSvc := &MockService{
+		ReportFunc: func(ctx context.Context, req domain.ReportRequest) (domain.Report, error) {
+			return domain.Report{}, service.ErrValidationFailed
+		},
+	}
+	h := handler.NewHandler(mockSvc)
+
+	req, _ := http.NewRequest("POST", "/api/v1/reports", bytes.NewBufferString(reqBody))
+	rr := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/reports", h.CreateReportHandler)
+	mux.ServeHTTP(rr, req)
+
+	checkResponseCode(t, http.StatusBadRequest, rr.Code)
+}
+
+// --- ТЕСТЫ MODERATION ---
+
+func TestCreateModerationDecisionHandler_RejectWithoutReason(t *testing.T) {
+	reqBody := `{"action": "reject", "anquette_id": 5}`
+	mockSvc := &MockService{
+		ModerateFunc: func(ctx context.Context, req domain.ModerationRequest) (domain.ModerationDecision, error) {
+			if req.Action != domain.ModerationReject || req.AnquetteID != 5 {
+				t.Errorf("Неверный запрос в сервис: %+v", req)
+			}
+			return domain.ModerationDecision{}, service.ErrValidationFailed
+		},
+	}
+	h := handler.NewHandler(mockSvc)
+
+	req, _ := http.NewRequest("POST", "/api/v1/admin/moderation/decisions", bytes.NewBufferString(reqBody))
+	rr := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/admin/moderation/decisions", h.CreateModerationDecisionHandler)
+	mux.ServeHTTP(rr, req)
+
+	checkResponseCode(t, http.StatusBadRequest, rr.Code)
+}
+
+// --- ТЕСТЫ MATCH ---
+
+func TestListMatchesHandler_Success(t *testing.T) {
+	var gotBefore, gotLimit int
+	mockSvc := &MockService{
+		ListMatchesFunc: func(ctx context.Context, tgID int, beforeID, limit int) ([]domain.MatchView, error) {
+			gotBefore, gotLimit = beforeID, limit
+			return []domain.MatchView{{ID: 5, Partner: domain.MatchPartner{TgID: 2, TgUsername: "partner"}}}, nil
+		},
+	}
+	h := handler.NewHandler(mockSvc)
+
+	req, _ := http.NewRequest("GET", "/api/v1/users/1/matches?before=10&limit=3", nil)
+	rr := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/users/{id}/matches", h.ListMatchesHandler)
+	mux.ServeHTTP(rr, req)
+
+	checkResponseCode(t, http.StatusOK, rr.Code)
+	if gotBefore != 10 || gotLimit != 3 {
+		t.Errorf("Ожидали before=10 и limit=3, получили %d и %d", gotBefore, gotLimit)
+	}
+}
+
+func TestDeleteMatchHandler_NotFound(t *testing.T) {
+	mockSvc := &MockService{
+		DeleteMatchFunc: func(ctx context.Context, id int) error {
+			return service.ErrNotFound
+		},
+	}
+	h := handler.NewHandler(mockSvc)
+
+	req, _ := http.NewRequest("DELETE", "/api/v1/matches/7", nil)
+	rr := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("DELETE /api/v1/matches/{id}", h.DeleteMatchHandler)
+	mux.ServeHTTP(rr, req)
+
+	checkResponseCode(t, http.StatusNotFound, rr.Code)
+}
+
+// --- ТЕСТЫ AUTH ---
+
+func TestAuthRequire_NoCredentials(t *testing.T) {
+	mockSvc := &MockService{
+		UpdateUserFunc: func(ctx context.Context, id int, req domain.UserRequest) error {
+			t.Error("Сервис не должен вызываться без авторизации")
+			return nil
+		},
+	}
+	h := handler.NewHandler(mockSvc)
+	authz := handler.NewAuth(mockSvc, auth.NewTelegramValidator("123456:TEST-TOKEN", time.Hour))
+
+	req, _ := http.NewRequest("PUT", "/api/v1/users/1", bytes.NewBufferString(`{"tg_id": 1}`))
+	rr := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /api/v1/users/{id}", authz.Require(auth.ScopeUsersWrite)(h.UpdateUserHandler))
+	mux.ServeHTTP(rr, req)
+
+	checkResponseCode(t, http.StatusUnauthorized, rr.Code)
+}
+
+// tmaHeader - заголовок Authorization с initData пользователя tgID, подписанной токеном botToken
+func tmaHeader(botToken string, tgID int64) string {
+	vals := url.Values{}
+	vals.Set("auth_date", strconv.FormatInt(time.Now().Unix(), 10))
+	vals.Set("user", `{"id":`+strconv.FormatInt(tgID, 10)+`}`)
+
+	keys := make([]string, 0, len(vals))
+	for k := range vals {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + vals.Get(k)
+	}
+
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(botToken))
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+	vals.Set("hash", hex.EncodeToString(mac.Sum(nil)))
+	return "tma " + vals.Encode()
+}
+
+func TestAuthRequire_TelegramBannedUser(t *testing.T) {
+	banned := time.Now()
+	called := false
+	mockSvc := &MockService{
+		GetUserFunc: func(ctx context.Context, id int) (domain.User, error) {
+			if _, ok := auth.TgIDFromContext(ctx); ok {
+				called = true
+			}
+			return domain.User{TgID: int64(id), BannedAt: &banned}, nil
+		},
+	}
+	h := handler.NewHandler(mockSvc)
+	authz := handler.NewAuth(mockSvc, auth.NewTelegramValidator("123456:TEST-TOKEN", time.Hour))
+
+	req, _ := http.NewRequest("GET", "/api/v1/users/7", nil)
+	req.Header.Set("Authorization", tmaHeader("123456:TEST-TOKEN", 7))
+	rr := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/users/{id}", authz.Require(auth.ScopeUsersRead)(h.GetUserHandler))
+	mux.ServeHTTP(rr, req)
+
+	checkResponseCode(t, http.StatusForbidden, rr.Code)
+	if called {
+		t.Error("Хендлер не должен вызываться для забаненного пользователя")
+	}
+}
+
+// serveWithAPIKey - выполняет GET /api/v1/users/1 с ключом, у которого есть права scopes
+func serveWithAPIKey(t *testing.T, scopes []string) (*httptest.ResponseRecorder, bool) {
+	called := false
+	mockSvc := &MockService{
+		AuthenticateAPIKeyFunc: func(ctx context.Context, key string) (domain.APIKey, error) {
+			if key != "bk_valid" {
+				return domain.APIKey{}, service.ErrUnauthorized
+			}
+			return domain.APIKey{ID: 1, Name: "bot", Scopes: scopes}, nil
+		},
+		GetUserFunc: func(ctx context.Context, id int) (domain.User, error) {
+			_, called = auth.APIKeyFromContext(ctx)
+			return domain.User{TgID: int64(id)}, nil
+		},
+	}
+	h := handler.NewHandler(mockSvc)
+	authz := handler.NewAuth(mockSvc, auth.NewTelegramValidator("123456:TEST-TOKEN", time.Hour))
+
+	req, _ := http.NewRequest("GET", "/api/v1/users/1", nil)
+	req.Header.Set("X-API-Key", "bk_valid")
+	rr := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/users/{id}", authz.Require(auth.ScopeUsersRead)(h.GetUserHandler))
+	mux.ServeHTTP(rr, req)
+	return rr, called
+}
+
+func TestAuthRequire_APIKeyWithScope(t *testing.T) {
+	rr, called := serveWithAPIKey(t, []string{auth.ScopeUsersRead})
+
+	checkResponseCode(t, http.StatusOK, rr.Code)
+	if !called {
+		t.Error("Ожидали ключ API в контексте хендлера")
+	}
+}
+
+func TestAuthRequire_APIKeyMissingScope(t *testing.T) {
+	rr, called := serveWithAPIKey(t, []string{auth.ScopeAnquettesRead})
+
+	checkResponseCode(t, http.StatusForbidden, rr.Code)
+	if called {
+		t.Error("Хендлер не должен вызываться без нужного права")
+	}
+}
+
+func TestUpdateUserHandler_Forbidden(t *testing.T) {
+	mockSvc := &MockService{
+		UpdateUserFunc: func(ctx context.Context, id int, req domain.UserRequest) error {
+			return service.ErrForbidden
+		},
+	}
+	h := handler.NewHandler(mockSvc)
+
+	req, _ := http.NewRequest("PUT", "/api/v1/users/2", bytes.NewBufferString(`{"tg_id": 2}`))
+	rr := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /api/v1/users/{id}", h.UpdateUserHandler)
+	mux.ServeHTTP(rr, req)
+
+	checkResponseCode(t, http.StatusForbidden, rr.Code)
+}
+
+// --- ТЕСТЫ PHOTO ---
+
+func TestUploadPhotoHandler_Success(t *testing.T) {
+	var gotData []byte
+	mockSvc := &MockService{
+		UploadPhotoFunc: func(ctx context.Context, anquetteID int, data []byte) (domain.Photo, error) {
+			gotData = data
+			return domain.Photo{ID: 3, AnquetteID: anquetteID}, nil
+		},
+	}
+	h := handler.NewHandler(mockSvc)
+
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	fw, _ := mw.CreateFormFile("photo", "me.png")
+	fw.Write([]byte("png-bytes"))
+	mw.Close()
+
+	req, _ := http.NewRequest("POST", "/api/v1/anquettes/1/photos", body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rr := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/anquettes/{id}/photos", h.UploadPhotoHandler)
+	mux.ServeHTTP(rr, req)
+
+	checkResponseCode(t, http.StatusCreated, rr.Code)
+	if string(gotData) != "png-bytes" {
+		t.Errorf("Сервис получил неверное содержимое файла: %q", gotData)
+	}
+}
+
+func TestUploadPhotoHandler_NoFile(t *testing.T) {
+	h := handler.NewHandler(&MockService{})
+
+	req, _ := http.NewRequest("POST", "/api/v1/anquettes/1/photos", bytes.NewBufferString("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/anquettes/{id}/photos", h.UploadPhotoHandler)
+	mux.ServeHTTP(rr, req)
+
+	checkResponseCode(t, http.StatusBadRequest, rr.Code)
+}
+
+// --- ТЕСТЫ HEALTH ---
+
+func TestReadyzHandler(t *testing.T) {
+	h := handler.NewHandler(&MockService{})
+	h.Health = health.New()
+	h.Health.SetMigrating(true)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /readyz", h.ReadyzHandler)
+
+	req, _ := http.NewRequest("GET", "/readyz", nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	checkResponseCode(t, http.StatusServiceUnavailable, rr.Code)
+	if body := rr.Body.String(); !strings.Contains(body, `"migrations":"running"`) {
+		t.Errorf("Ожидали причину неготовности: %s", body)
+	}
+
+	h.Health.SetMigrating(false)
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	checkResponseCode(t, http.StatusOK, rr.Code)
+}
+
+func TestWaitForMigrations(t *testing.T) {
+	h := handler.NewHandler(&MockService{})
+	h.Health = health.New()
+	h.Health.SetMigrating(true)
+
+	api := h.WaitForMigrations(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req, _ := http.NewRequest("GET", "/api/v1/feed", nil)
+	rr := httptest.NewRecorder()
+	api.ServeHTTP(rr, req)
+	checkResponseCode(t, http.StatusServiceUnavailable, rr.Code)
+	if rr.Header().Get("Retry-After") == "" {
+		t.Error("Ожидали заголовок Retry-After")
+	}
+
+	h.Health.SetMigrating(false)
+	rr = httptest.NewRecorder()
+	api.ServeHTTP(rr, req)
+	checkResponseCode(t, http.StatusNoContent, rr.Code)
+}
+
+// --- ТЕСТЫ METRICS ---
+
+func TestHTTPMetrics_Middleware(t *testing.T) {
+	mockSvc := &MockService{
+		GetUserFunc: func(ctx context.Context, id int) (domain.User, error) {
+			return domain.User{}, service.ErrNotFound
+		},
+	}
+	h := handler.NewHandler(mockSvc)
+	reg := metrics.NewRegistry()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/users/{id}", h.GetUserHandler)
+	srv := handler.NewHTTPMetrics(reg).Middleware(mux)
+
+	for _, path := range []string{"/api/v1/users/1", "/api/v1/users/2", "/no/such/route"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		srv.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	var b strings.Builder
+	reg.WriteTo(context.Background(), &b)
+	for _, want := range []string{
+		`http_requests_total{route="GET /api/v1/users/{id}",status="404"} 2`,
+		`http_requests_total{route="unmatched",status="404"} 1`,
+		`http_request_duration_seconds_count{route="GET /api/v1/users/{id}",status="404"} 2`,
+	} {
+		if !strings.Contains(b.String(), want+"\n") {
+			t.Errorf("Нет строки %s в метриках:\n%s", want, b.String())
+		}
+	}
+}
+
+// --- ТЕСТЫ LOGGING ---
+
+func TestLogRequests_RequestID(t *testing.T) {
+	var logs bytes.Buffer
+	logger, _ := logging.New(&logs, "info", "json")
+	prev := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	mockSvc := &MockService{
+		GetUserFunc: func(ctx context.Context, id int) (domain.User, error) {
+			return domain.User{}, errors.New("disk I/O error")
+		},
+	}
+	h := handler.NewHandler(mockSvc)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/users/{id}", h.GetUserHandler)
+	srv := handler.LogRequests(mux)
+
+	// ID бота возвращается в ответе и попадает в каждую запись лога по запросу
+	req, _ := http.NewRequest("GET", "/api/v1/users/1", nil)
+	req.Header.Set(handler.RequestIDHeader, "bot-42")
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+
+	checkResponseCode(t, http.StatusInternalServerError, rr.Code)
+	if got := rr.Header().Get(handler.RequestIDHeader); got != "bot-42" {
+		t.Errorf("Ожидали X-Request-ID bot-42, получили %q", got)
+	}
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Ожидали ошибку сервиса и итог запроса в логе, получили:\n%s", logs.String())
+	}
+	for _, line := range lines {
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil || rec[logging.KeyRequestID] != "bot-42" {
+			t.Errorf("Запись без request_id: %s", line)
+		}
+	}
+	if !strings.Contains(lines[1], `"route":"GET /api/v1/users/{id}"`) || !strings.Contains(lines[1], `"status":500`) {
+		t.Errorf("Неверный итог запроса: %s", lines[1])
+	}
+
+	// Без заголовка или с негодным ID генерируется новый
+	for _, id := range []string{"", "bad id\n"} {
+		req, _ := http.NewRequest("GET", "/api/v1/users/1", nil)
+		req.Header.Set(handler.RequestIDHeader, id)
+		rr := httptest.NewRecorder()
+		srv.ServeHTTP(rr, req)
+		if got := rr.Header().Get(handler.RequestIDHeader); got == "" || got == id {
+			t.Errorf("Ожидали новый X-Request-ID вместо %q, получили %q", id, got)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net/http"
+
+	"bot-api/internal/domain"
+	"bot-api/internal/health"
+)
+
+// --- Методы Health (без авторизации) ---
+
+// HealthzHandler - процесс жив и отвечает на запросы
+func (h *Handler) HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "ok"})
+}
+
+// ReadyzHandler - готов ли процесс принимать запросы (см. health.Checker.Ready); иначе 503
+func (h *Handler) ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	if h.Health == nil {
+		sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "ok"})
+		return
+	}
+	report := h.Health.Ready(r.Context())
+	if !report.Ready {
+		sendJSON(w, r, http.StatusServiceUnavailable, domain.APIResponse{Status: "error", Code: domain.ErrCodeUnavailable, Data: report})
+		return
+	}
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "ok", Data: report})
+}
+
+// VersionHandler - версия сборки и схемы БД
+func (h *Handler) VersionHandler(w http.ResponseWriter, r *http.Request) {
+	info := health.Build()
+	if h.Health != nil {
+		info = h.Health.Version(r.Context())
+	}
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "ok", Data: info})
+}
+
+// WaitForMigrations - пока идут миграции, отвечает 503 вместо next:
+// схема БД еще не та, которую ждет код
+func (h *Handler) WaitForMigrations(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.Health != nil && h.Health.Migrating() {
+			w.Header().Set("Retry-After", "5")
+			sendError(w, r, http.StatusServiceUnavailable, domain.ErrCodeUnavailable, "error.unavailable")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
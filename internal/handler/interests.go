@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"bot-api/internal/domain"
+)
+
+// --- Методы Interest ---
+
+// ListInterestsHandler - справочник интересов: GET /api/v1/interests
+func (h *Handler) ListInterestsHandler(w http.ResponseWriter, r *http.Request) {
+	interests, err := h.Service.ListInterests(r.Context())
+	if err != nil {
+		handleServiceError(w, r, err, "interest")
+		return
+	}
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "ok", Data: interests})
+}
+
+// SetAnquetteInterestsHandler - заменяет интересы анкеты списком ID из справочника
+func (h *Handler) SetAnquetteInterestsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidID, "error.invalid_id")
+		return
+	}
+
+	var req domain.InterestsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidJSON, "error.invalid_json")
+		return
+	}
+
+	if err := h.Service.SetAnquetteInterests(r.Context(), id, req); err != nil {
+		handleServiceError(w, r, err, "anquette")
+		return
+	}
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "updated"})
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"bot-api/internal/domain"
+)
+
+// listFilter - читает параметры списка из query; при ошибке возвращает имя параметра
+func listFilter(r *http.Request) (domain.ListFilter, string, bool) {
+	q := r.URL.Query()
+	f := domain.ListFilter{City: q.Get("city"), CityID: q.Get("city_id"), Gender: q.Get("gender"), Sort: q.Get("sort"), Cursor: q.Get("cursor")}
+	for name, dst := range map[string]*int{
+		"age_min": &f.AgeMin,
+		"age_max": &f.AgeMax,
+		"limit":   &f.Limit,
+	} {
+		var err error
+		if *dst, err = queryInt(r, name); err != nil {
+			return f, name, false
+		}
+	}
+	if v := q.Get("created_after"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			if t, err = time.Parse(time.DateOnly, v); err != nil {
+				return f, "created_after", false
+			}
+		}
+		f.CreatedAfter = &t
+	}
+	return f, "", true
+}
+
+// --- Методы списков (админка, аналитика) ---
+
+func (h *Handler) ListUsersHandler(w http.ResponseWriter, r *http.Request) {
+	f, bad, ok := listFilter(r)
+	if !ok {
+		sendListParamError(w, r, bad)
+		return
+	}
+
+	page, err := h.Service.ListUsers(r.Context(), f)
+	if err != nil {
+		handleServiceError(w, r, err, "user")
+		return
+	}
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "ok", Data: page})
+}
+
+func (h *Handler) ListAnquettesHandler(w http.ResponseWriter, r *http.Request) {
+	f, bad, ok := listFilter(r)
+	if !ok {
+		sendListParamError(w, r, bad)
+		return
+	}
+
+	page, err := h.Service.ListAnquettes(r.Context(), f)
+	if err != nil {
+		handleServiceError(w, r, err, "anquette")
+		return
+	}
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "ok", Data: page})
+}
+
+func sendListParamError(w http.ResponseWriter, r *http.Request, name string) {
+	key := "error.invalid_param"
+	if name == "created_after" {
+		key = "error.invalid_date"
+	}
+	sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidParam, key, name)
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"bot-api/internal/domain"
+)
+
+// --- Методы Location ---
+
+// SetAnquetteLocationHandler - принимает геопозицию в формате Location из Telegram
+func (h *Handler) SetAnquetteLocationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidID, "error.invalid_id")
+		return
+	}
+
+	var req domain.LocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidJSON, "error.invalid_json")
+		return
+	}
+
+	if err := h.Service.SetAnquetteLocation(r.Context(), id, req); err != nil {
+		handleServiceError(w, r, err, "anquette")
+		return
+	}
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "updated"})
+}
+
+func (h *Handler) DeleteAnquetteLocationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidID, "error.invalid_id")
+		return
+	}
+
+	if err := h.Service.ClearAnquetteLocation(r.Context(), id); err != nil {
+		handleServiceError(w, r, err, "anquette")
+		return
+	}
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "deleted"})
+}
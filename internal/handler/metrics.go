@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"bot-api/internal/metrics"
+)
+
+// routeUnmatched - метка запросов, не дошедших ни до одного роута API
+// (404 или 503 во время миграций): иначе каждый случайный путь дал бы свою метку
+const routeUnmatched = "unmatched"
+
+// HTTPMetrics - число и длительность запросов по шаблону роута и статусу ответа
+type HTTPMetrics struct {
+	requests *metrics.Counter
+	duration *metrics.Histogram
+}
+
+func NewHTTPMetrics(reg *metrics.Registry) *HTTPMetrics {
+	return &HTTPMetrics{
+		requests: reg.NewCounter("http_requests_total",
+			"HTTP requests by route pattern and status code.", "route", "status"),
+		duration: reg.NewHistogram("http_request_duration_seconds",
+			"HTTP request latency by route pattern and status code.", metrics.DefBuckets, "route", "status"),
+	}
+}
+
+// Middleware - считает запросы к next. Шаблон роута ("GET /api/v1/users/{id}")
+// берется из r.Pattern, который заполняет ServeMux при выборе обработчика.
+func (m *HTTPMetrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		route := r.Pattern
+		if route == "" || route == "/" {
+			route = routeUnmatched
+		}
+		status := strconv.Itoa(rec.Status())
+		m.requests.Inc(route, status)
+		m.duration.Observe(time.Since(start).Seconds(), route, status)
+	})
+}
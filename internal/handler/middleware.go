@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"bot-api/internal/auth"
+	"bot-api/internal/domain"
+	"bot-api/internal/i18n"
+	"bot-api/internal/logging"
+	"bot-api/internal/service"
+)
+
+// RequestIDHeader - заголовок с ID запроса: бот может передать свой, иначе он генерируется
+const RequestIDHeader = "X-Request-ID"
+
+// quietRoutes - роуты проверок и сбора метрик: их итог пишется в лог только на уровне debug
+var quietRoutes = []string{"GET /healthz", "GET /readyz", "GET /metrics"}
+
+// LogRequests - присваивает запросу ID (X-Request-ID клиента или новый),
+// возвращает его в ответе и кладет в контекст, чтобы все записи лога по
+// запросу получали request_id. По завершении запроса пишет в лог его итог.
+func LogRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		id := r.Header.Get(RequestIDHeader)
+		if !logging.ValidRequestID(id) {
+			id = logging.NewRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		r = r.WithContext(logging.WithRequestID(r.Context(), id))
+
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		level := slog.LevelInfo
+		switch {
+		case rec.Status() >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case slices.Contains(quietRoutes, r.Pattern):
+			level = slog.LevelDebug
+		}
+		slog.Log(r.Context(), level, "http request",
+			"method", r.Method, "path", r.URL.Path, "route", r.Pattern,
+			"status", rec.Status(), "duration", time.Since(start))
+	})
+}
+
+// statusRecorder - запоминает код ответа обработчика
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	if s.status == 0 {
+		s.status = code
+	}
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(b)
+}
+
+// Unwrap - исходный ResponseWriter для http.ResponseController
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// Status - код ответа; 200, если обработчик ничего не записал
+func (s *statusRecorder) Status() int {
+	if s.status == 0 {
+		return http.StatusOK
+	}
+	return s.status
+}
+
+// Auth - проверка доступа к роутам. Запрос проходит, если он подписан
+// ключом API с нужным правом или initData пользователя Telegram Mini App,
+// когда это право есть в auth.UserScopes.
+type Auth struct {
+	Service  service.UserService
+	Telegram *auth.TelegramValidator
+}
+
+func NewAuth(svc service.UserService, tg *auth.TelegramValidator) *Auth {
+	return &Auth{Service: svc, Telegram: tg}
+}
+
+// apiKeyFromRequest - ключ передается в X-API-Key или в "Authorization: Bearer <key>"
+func apiKeyFromRequest(r *http.Request) string {
+	if scheme, key, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "bearer") {
+		return key
+	}
+	return r.Header.Get("X-API-Key")
+}
+
+// initDataFromRequest - initData передается в заголовке "Authorization: tma <initData>"
+// или в X-Telegram-Init-Data
+func initDataFromRequest(r *http.Request) string {
+	if scheme, data, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "tma") {
+		return data
+	}
+	return r.Header.Get("X-Telegram-Init-Data")
+}
+
+// Require - пропускает запрос, только если вызывающий обладает правом scope.
+// Ключ API или tg_id вызывающего кладется в контекст запроса.
+func (a *Auth) Require(scope string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if key := apiKeyFromRequest(r); key != "" {
+				a.serveWithAPIKey(w, r, key, scope, next)
+				return
+			}
+			if initData := initDataFromRequest(r); initData != "" {
+				a.serveWithTelegram(w, r, initData, scope, next)
+				return
+			}
+			sendError(w, r, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "error.unauthorized")
+		}
+	}
+}
+
+func (a *Auth) serveWithAPIKey(w http.ResponseWriter, r *http.Request, key, scope string, next http.HandlerFunc) {
+	apiKey, err := a.Service.AuthenticateAPIKey(r.Context(), key)
+	if err != nil {
+		handleServiceError(w, r, err, "api_key")
+		return
+	}
+	if !auth.HasScope(apiKey.Scopes, scope) {
+		slog.WarnContext(r.Context(), "API key lacks scope", "api_key", apiKey.Name, "scope", scope, "method", r.Method, "path", r.URL.Path)
+		sendError(w, r, http.StatusForbidden, domain.ErrCodeMissingScope, "error.missing_scope", scope)
+		return
+	}
+	next(w, r.WithContext(auth.WithAPIKey(r.Context(), apiKey)))
+}
+
+func (a *Auth) serveWithTelegram(w http.ResponseWriter, r *http.Request, initData, scope string, next http.HandlerFunc) {
+	if !slices.Contains(auth.UserScopes, scope) {
+		sendError(w, r, http.StatusForbidden, domain.ErrCodeForbidden, "error.forbidden")
+		return
+	}
+
+	tgUser, err := a.Telegram.Validate(initData)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Telegram auth failed", "error", err)
+		if errors.Is(err, auth.ErrInitDataExpired) {
+			sendError(w, r, http.StatusUnauthorized, domain.ErrCodeInitDataExpired, "error.init_data_expired")
+			return
+		}
+		sendError(w, r, http.StatusUnauthorized, domain.ErrCodeInitDataInvalid, "error.init_data_invalid")
+		return
+	}
+
+	// Забаненные модератором пользователи не допускаются
+	u, err := a.Service.GetUser(r.Context(), int(tgUser.ID))
+	if err != nil && !errors.Is(err, service.ErrNotFound) {
+		handleServiceError(w, r, err, "user")
+		return
+	}
+	if err == nil && u.BannedAt != nil {
+		slog.WarnContext(r.Context(), "Banned user request", "tg_id", tgUser.ID, "method", r.Method, "path", r.URL.Path)
+		sendError(w, r, http.StatusForbidden, domain.ErrCodeUserBanned, "error.user_banned")
+		return
+	}
+	ctx := auth.WithTgID(r.Context(), tgUser.ID)
+	// Выбранный пользователем язык важнее Accept-Language
+	if err == nil && u.Locale != "" {
+		ctx = i18n.WithLang(ctx, u.Locale)
+	}
+	next(w, r.WithContext(ctx))
+}
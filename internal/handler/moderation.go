@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"bot-api/internal/domain"
+)
+
+// --- Методы Moderation (админка) ---
+
+func (h *Handler) ModerationQueueHandler(w http.ResponseWriter, r *http.Request) {
+	afterReport, err := queryInt(r, "after_report")
+	if err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidParam, "error.invalid_param", "after_report")
+		return
+	}
+	afterAnquette, err := queryInt(r, "after_anquette")
+	if err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidParam, "error.invalid_param", "after_anquette")
+		return
+	}
+	limit, err := queryInt(r, "limit")
+	if err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidParam, "error.invalid_param", "limit")
+		return
+	}
+
+	queue, err := h.Service.ModerationQueue(r.Context(), afterReport, afterAnquette, limit)
+	if err != nil {
+		handleServiceError(w, r, err, "moderation")
+		return
+	}
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "ok", Data: queue})
+}
+
+func (h *Handler) CreateModerationDecisionHandler(w http.ResponseWriter, r *http.Request) {
+	var req domain.ModerationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidJSON, "error.invalid_json")
+		return
+	}
+
+	d, err := h.Service.Moderate(r.Context(), req)
+	if err != nil {
+		handleServiceError(w, r, err, "moderation")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Moderation decision", "decision_id", d.ID, "action", d.Action, "moderator", d.Moderator, "anquette_id", d.AnquetteID, "tg_id", d.TgID)
+	sendJSON(w, r, http.StatusCreated, domain.APIResponse{Status: "created", ID: d.ID, Data: d})
+}
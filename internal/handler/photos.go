@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"bot-api/internal/domain"
+	"bot-api/internal/service"
+)
+
+// Запас на заголовки multipart сверх размера самого фото
+const multipartOverhead = 1 << 20
+
+// --- Методы Photo ---
+
+// UploadPhotoHandler - принимает multipart/form-data с файлом в поле "photo"
+func (h *Handler) UploadPhotoHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidID, "error.invalid_id")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, service.MaxPhotoSize+multipartOverhead)
+	file, _, err := r.FormFile("photo")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			sendError(w, r, http.StatusRequestEntityTooLarge, domain.ErrCodeFileTooLarge, "error.file_too_large")
+			return
+		}
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidFile, "error.photo_missing")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidFile, "error.file_unreadable")
+		return
+	}
+
+	p, err := h.Service.UploadPhoto(r.Context(), id, data)
+	if err != nil {
+		handleServiceError(w, r, err, "anquette")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Photo created", "photo_id", p.ID, "anquette_id", id)
+	sendJSON(w, r, http.StatusCreated, domain.APIResponse{Status: "created", ID: p.ID, Data: p})
+}
+
+func (h *Handler) ListPhotosHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidID, "error.invalid_id")
+		return
+	}
+
+	photos, err := h.Service.ListPhotos(r.Context(), id)
+	if err != nil {
+		handleServiceError(w, r, err, "anquette")
+		return
+	}
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "ok", Data: photos})
+}
+
+// GetPhotoFileHandler - отдает сам файл фото или его копию из ?variant= (medium, thumb)
+func (h *Handler) GetPhotoFileHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidID, "error.invalid_id")
+		return
+	}
+
+	p, rc, err := h.Service.OpenPhoto(r.Context(), id, r.URL.Query().Get("variant"))
+	if err != nil {
+		handleServiceError(w, r, err, "photo")
+		return
+	}
+	defer rc.Close()
+
+	w.Header().Set("Content-Type", p.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(p.Size, 10))
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	if _, err := io.Copy(w, rc); err != nil {
+		slog.WarnContext(r.Context(), "Error writing photo", "photo_id", id, "error", err)
+	}
+}
+
+func (h *Handler) DeletePhotoHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidID, "error.invalid_id")
+		return
+	}
+	photoID, err := strconv.Atoi(r.PathValue("photoID"))
+	if err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidID, "error.invalid_id")
+		return
+	}
+
+	if err := h.Service.DeletePhoto(r.Context(), id, photoID); err != nil {
+		handleServiceError(w, r, err, "photo")
+		return
+	}
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "deleted"})
+}
+
+func (h *Handler) ReorderPhotosHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidID, "error.invalid_id")
+		return
+	}
+
+	var req domain.PhotoOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidJSON, "error.invalid_json")
+		return
+	}
+
+	if err := h.Service.ReorderPhotos(r.Context(), id, req.PhotoIDs); err != nil {
+		handleServiceError(w, r, err, "anquette")
+		return
+	}
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "updated"})
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"bot-api/internal/domain"
+)
+
+// --- Методы Reaction ---
+
+func (h *Handler) CreateReactionHandler(w http.ResponseWriter, r *http.Request) {
+	var req domain.ReactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidJSON, "error.invalid_json")
+		return
+	}
+
+	res, err := h.Service.React(r.Context(), req.TgID, req.AnquetteID, req.Kind)
+	if err != nil {
+		handleServiceError(w, r, err, "reaction")
+		return
+	}
+
+	if res.Match != nil {
+		slog.InfoContext(r.Context(), "Match created", "match_id", res.Match.ID)
+	}
+	sendJSON(w, r, http.StatusCreated, domain.APIResponse{Status: "created", ID: res.Reaction.ID, Data: res})
+}
+
+// --- Методы Match ---
+
+func (h *Handler) ListMatchesHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidID, "error.invalid_id")
+		return
+	}
+
+	before, err := queryInt(r, "before")
+	if err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidParam, "error.invalid_param", "before")
+		return
+	}
+	limit, err := queryInt(r, "limit")
+	if err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidParam, "error.invalid_param", "limit")
+		return
+	}
+
+	matches, err := h.Service.ListMatches(r.Context(), id, before, limit)
+	if err != nil {
+		handleServiceError(w, r, err, "user")
+		return
+	}
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "ok", Data: matches})
+}
+
+func (h *Handler) DeleteMatchHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidID, "error.invalid_id")
+		return
+	}
+
+	if err := h.Service.DeleteMatch(r.Context(), id); err != nil {
+		handleServiceError(w, r, err, "match")
+		return
+	}
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "deleted"})
+}
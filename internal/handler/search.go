@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"bot-api/internal/domain"
+)
+
+// --- Методы Search ---
+
+// SearchAnquettesHandler - полнотекстовый поиск анкет: ?q=...&status=...&tg_id=...&limit=...
+func (h *Handler) SearchAnquettesHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := domain.SearchFilter{Query: q.Get("q"), Status: q.Get("status")}
+	var err error
+	if f.Limit, err = queryInt(r, "limit"); err != nil {
+		sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidParam, "error.invalid_param", "limit")
+		return
+	}
+	if v := q.Get("tg_id"); v != "" {
+		if f.ViewerTgID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			sendError(w, r, http.StatusBadRequest, domain.ErrCodeInvalidParam, "error.invalid_param", "tg_id")
+			return
+		}
+	}
+
+	hits, err := h.Service.SearchAnquettes(r.Context(), f)
+	if err != nil {
+		handleServiceError(w, r, err, "anquette")
+		return
+	}
+	sendJSON(w, r, http.StatusOK, domain.APIResponse{Status: "ok", Data: hits})
+}
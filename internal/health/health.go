@@ -0,0 +1,216 @@
+// Package health - состояние процесса для проверок оркестратора и мониторинга:
+// жив ли процесс, готов ли он принимать запросы и из чего он собран.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Commit и BuildTime задаются при сборке:
+//
+//	go build -ldflags "-X bot-api/internal/health.Commit=$(git rev-parse HEAD) -X bot-api/internal/health.BuildTime=$(date -u +%FT%TZ)"
+//
+// Без них берутся данные VCS, которые go build записывает в бинарник сам.
+var (
+	Commit    string
+	BuildTime string
+)
+
+// checkTimeout - сколько ждать одну проверку готовности
+const checkTimeout = 2 * time.Second
+
+// Состояния фоновых задач
+const (
+	WorkerRunning = "running"
+	WorkerDone    = "done"
+	WorkerStopped = "stopped" // прервана остановкой сервера
+	WorkerFailed  = "failed"
+)
+
+// BuildInfo - версия сборки и схемы БД
+type BuildInfo struct {
+	Commit        string `json:"commit"`
+	BuildTime     string `json:"build_time"`
+	GoVersion     string `json:"go_version"`
+	SchemaVersion int    `json:"schema_version"`
+}
+
+// Report - результат проверки готовности: состояние каждой проверки и задачи ("ok" или причина)
+type Report struct {
+	Ready   bool              `json:"ready"`
+	Checks  map[string]string `json:"checks"`
+	Workers map[string]string `json:"workers,omitempty"`
+}
+
+// Check - проверка готовности; nil - в порядке
+type Check func(ctx context.Context) error
+
+type namedCheck struct {
+	name string
+	fn   Check
+}
+
+// Checker - состояние процесса. Готовность складывается из проверок (AddCheck),
+// хода миграций, фоновых задач и остановки сервера.
+type Checker struct {
+	// FailWhileMigrating - не готов, пока идут миграции. Без него процесс
+	// считается готовым сразу, хотя API до конца миграций отвечает 503.
+	FailWhileMigrating bool
+	// Schema - текущая версия схемы БД; готовность требует версии не ниже SchemaLatest
+	Schema       func(ctx context.Context) (int, error)
+	SchemaLatest int
+
+	migrating atomic.Bool
+	draining  atomic.Bool
+
+	mu      sync.Mutex
+	checks  []namedCheck
+	workers map[string]string
+}
+
+func New() *Checker {
+	return &Checker{FailWhileMigrating: true, workers: map[string]string{}}
+}
+
+// AddCheck - добавляет проверку готовности
+func (c *Checker) AddCheck(name string, fn Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, namedCheck{name: name, fn: fn})
+}
+
+// SetMigrating - начались (true) или закончились (false) миграции
+func (c *Checker) SetMigrating(v bool) {
+	c.migrating.Store(v)
+}
+
+// Migrating - идут ли миграции
+func (c *Checker) Migrating() bool {
+	return c.migrating.Load()
+}
+
+// SetDraining - сервер останавливается: новые запросы лучше направлять в другие экземпляры
+func (c *Checker) SetDraining() {
+	c.draining.Store(true)
+}
+
+// Worker - отмечает фоновую задачу запущенной. Задача вызывает done, когда
+// заканчивается; ошибка, кроме отмены контекста, делает процесс неготовым:
+// значит, задача не доделала свою работу и нужен перезапуск.
+func (c *Checker) Worker(name string) (done func(err error)) {
+	c.setWorker(name, WorkerRunning)
+	return func(err error) {
+		switch {
+		case err == nil:
+			c.setWorker(name, WorkerDone)
+		case errors.Is(err, context.Canceled):
+			c.setWorker(name, WorkerStopped)
+		default:
+			c.setWorker(name, WorkerFailed+": "+err.Error())
+		}
+	}
+}
+
+func (c *Checker) setWorker(name, state string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.workers[name] = state
+}
+
+// Ready - выполняет проверки готовности
+func (c *Checker) Ready(ctx context.Context) Report {
+	c.mu.Lock()
+	checks := append([]namedCheck(nil), c.checks...)
+	workers := make(map[string]string, len(c.workers))
+	for name, state := range c.workers {
+		workers[name] = state
+	}
+	c.mu.Unlock()
+
+	r := Report{Ready: true, Checks: map[string]string{}, Workers: workers}
+	fail := func(name, reason string) {
+		r.Ready = false
+		r.Checks[name] = reason
+	}
+
+	if c.draining.Load() {
+		fail("shutdown", "in progress")
+	}
+	switch {
+	case c.migrating.Load() && c.FailWhileMigrating:
+		fail("migrations", "running")
+	case c.migrating.Load():
+		r.Checks["migrations"] = "running"
+	case c.Schema != nil:
+		if v, err := c.Schema(ctx); err != nil {
+			fail("migrations", err.Error())
+		} else if v < c.SchemaLatest {
+			fail("migrations", fmt.Sprintf("schema version %d, expected %d", v, c.SchemaLatest))
+		} else {
+			r.Checks["migrations"] = "ok"
+		}
+	}
+	for _, ch := range checks {
+		cctx, cancel := context.WithTimeout(ctx, checkTimeout)
+		err := ch.fn(cctx)
+		cancel()
+		if err != nil {
+			fail(ch.name, err.Error())
+		} else {
+			r.Checks[ch.name] = "ok"
+		}
+	}
+
+	for _, state := range workers {
+		if strings.HasPrefix(state, WorkerFailed) {
+			r.Ready = false
+		}
+	}
+	return r
+}
+
+// Version - версия сборки и текущая версия схемы БД (0, если ее не удалось узнать)
+func (c *Checker) Version(ctx context.Context) BuildInfo {
+	info := Build()
+	if c.Schema != nil {
+		if v, err := c.Schema(ctx); err == nil {
+			info.SchemaVersion = v
+		}
+	}
+	return info
+}
+
+// Build - версия сборки: из -ldflags, иначе из данных VCS бинарника
+func Build() BuildInfo {
+	info := BuildInfo{Commit: Commit, BuildTime: BuildTime, GoVersion: runtime.Version()}
+	if bi, ok := debug.ReadBuildInfo(); ok && info.Commit == "" {
+		modified := false
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				info.Commit = s.Value
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = s.Value
+				}
+			case "vcs.modified":
+				modified = s.Value == "true"
+			}
+		}
+		if modified && info.Commit != "" {
+			info.Commit += "-dirty"
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	return info
+}
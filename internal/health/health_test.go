@@ -0,0 +1,80 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newTestChecker(schema int) *Checker {
+	c := New()
+	c.Schema = func(context.Context) (int, error) { return schema, nil }
+	c.SchemaLatest = 15
+	return c
+}
+
+func TestReady_Migrating(t *testing.T) {
+	c := newTestChecker(3)
+	c.SetMigrating(true)
+	if r := c.Ready(context.Background()); r.Ready || r.Checks["migrations"] != "running" {
+		t.Errorf("Во время миграций ожидали неготовность: %+v", r)
+	}
+
+	// Без FailWhileMigrating миграции отмечаются в отчете, но не мешают готовности
+	c.FailWhileMigrating = false
+	if r := c.Ready(context.Background()); !r.Ready || r.Checks["migrations"] != "running" {
+		t.Errorf("Ожидали готовность во время миграций: %+v", r)
+	}
+}
+
+func TestReady_Schema(t *testing.T) {
+	if r := newTestChecker(14).Ready(context.Background()); r.Ready || !strings.Contains(r.Checks["migrations"], "14") {
+		t.Errorf("Ожидали неготовность при отставшей схеме: %+v", r)
+	}
+	if r := newTestChecker(15).Ready(context.Background()); !r.Ready || r.Checks["migrations"] != "ok" {
+		t.Errorf("Ожидали готовность при актуальной схеме: %+v", r)
+	}
+}
+
+func TestReady_ChecksAndWorkers(t *testing.T) {
+	c := newTestChecker(15)
+	dbErr := errors.New("database is locked")
+	c.AddCheck("database", func(context.Context) error { return dbErr })
+	if r := c.Ready(context.Background()); r.Ready || r.Checks["database"] != dbErr.Error() {
+		t.Errorf("Ожидали неготовность при ошибке проверки: %+v", r)
+	}
+
+	c = newTestChecker(15)
+	running, stopped, failed := c.Worker("photos"), c.Worker("cities"), c.Worker("interests")
+	if r := c.Ready(context.Background()); !r.Ready || r.Workers["photos"] != WorkerRunning {
+		t.Errorf("Запущенные задачи не должны мешать готовности: %+v", r)
+	}
+	running(nil)
+	stopped(context.Canceled)
+	if r := c.Ready(context.Background()); !r.Ready || r.Workers["photos"] != WorkerDone || r.Workers["cities"] != WorkerStopped {
+		t.Errorf("Завершенные и остановленные задачи не должны мешать готовности: %+v", r)
+	}
+	failed(errors.New("disk full"))
+	if r := c.Ready(context.Background()); r.Ready || r.Workers["interests"] != "failed: disk full" {
+		t.Errorf("Ожидали неготовность при упавшей задаче: %+v", r)
+	}
+}
+
+func TestReady_Draining(t *testing.T) {
+	c := newTestChecker(15)
+	c.SetDraining()
+	if r := c.Ready(context.Background()); r.Ready || r.Checks["shutdown"] == "" {
+		t.Errorf("Ожидали неготовность при остановке: %+v", r)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	Commit, BuildTime = "abc123", "2026-10-17T12:00:00Z"
+	t.Cleanup(func() { Commit, BuildTime = "", "" })
+
+	info := newTestChecker(15).Version(context.Background())
+	if info.Commit != "abc123" || info.BuildTime != "2026-10-17T12:00:00Z" || info.SchemaVersion != 15 || info.GoVersion == "" {
+		t.Errorf("Неверная версия: %+v", info)
+	}
+}
@@ -0,0 +1,120 @@
+// Package i18n - каталоги сообщений API на русском и английском
+// и выбор языка вызывающего.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Поддерживаемые языки
+const (
+	RU = "ru"
+	EN = "en"
+)
+
+// Default - язык, если вызывающий не указал поддерживаемый
+const Default = RU
+
+// Supported - поддерживаемые языки
+var Supported = []string{RU, EN}
+
+var catalogs = map[string]map[string]string{
+	RU: ru,
+	EN: en,
+}
+
+// Key - ключ каталога, переданный аргументом сообщения. T переводит его
+// на тот же язык, что и само сообщение: T(EN, "violation.required", Key("field.name")).
+type Key string
+
+// IsSupported - есть ли каталог для языка
+func IsSupported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// T - сообщение key на языке lang с подстановкой args через fmt.Sprintf.
+// Для "a.b.c" без перевода пробуется "a.b", затем каталог языка Default;
+// если сообщения нет нигде, возвращается сам ключ.
+func T(lang, key string, args ...any) string {
+	args = slices.Clone(args)
+	for i, a := range args {
+		if k, ok := a.(Key); ok {
+			args[i] = T(lang, string(k))
+		}
+	}
+
+	msg, ok := lookup(lang, key)
+	if !ok && lang != Default {
+		msg, ok = lookup(Default, key)
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+func lookup(lang, key string) (string, bool) {
+	catalog := catalogs[lang]
+	for {
+		if msg, ok := catalog[key]; ok {
+			return msg, true
+		}
+		i := strings.LastIndexByte(key, '.')
+		if i < 0 {
+			return "", false
+		}
+		key = key[:i]
+	}
+}
+
+// Negotiate - лучший поддерживаемый язык из заголовка Accept-Language
+// ("en-US,en;q=0.9,ru;q=0.8") или Default
+func Negotiate(acceptLanguage string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		base, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if IsSupported(base) && q > 0 {
+			candidates = append(candidates, candidate{lang: base, q: q})
+		}
+	}
+	if len(candidates) == 0 {
+		return Default
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].lang
+}
+
+type langKey struct{}
+
+// WithLang - кладет язык вызывающего в контекст
+func WithLang(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, langKey{}, lang)
+}
+
+// LangFromContext - язык вызывающего, если он известен
+func LangFromContext(ctx context.Context) (string, bool) {
+	lang, ok := ctx.Value(langKey{}).(string)
+	return lang, ok
+}
@@ -0,0 +1,68 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	cases := []struct{ header, want string }{
+		{"", Default},
+		{"en", EN},
+		{"en-US,en;q=0.9", EN},
+		{"ru-RU,ru;q=0.9,en;q=0.8", RU},
+		{"de,en;q=0.5,ru;q=0.3", EN},
+		{"en;q=0.2,ru;q=0.8", RU},
+		{"en;q=0", Default},
+		{"de, fr", Default},
+		{"*", Default},
+	}
+	for _, tc := range cases {
+		if got := Negotiate(tc.header); got != tc.want {
+			t.Errorf("Negotiate(%q) = %q, ожидали %q", tc.header, got, tc.want)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := T(EN, "error.not_found.user"); got != "User not found" {
+		t.Errorf("Ожидали перевод, получили %q", got)
+	}
+	// Нет уточненного сообщения - берется родительский ключ
+	if got := T(EN, "error.not_found.nothing"); got != "Not found" {
+		t.Errorf("Ожидали родительское сообщение, получили %q", got)
+	}
+	// Неизвестный язык - каталог по умолчанию
+	if got := T("de", "error.not_found.user"); got != "Пользователь не найден" {
+		t.Errorf("Ожидали сообщение на %s, получили %q", Default, got)
+	}
+	if got := T(EN, "no.such.key"); got != "no.such.key" {
+		t.Errorf("Ожидали сам ключ, получили %q", got)
+	}
+	if got := T(EN, "error.missing_scope", "admin"); got != "Insufficient permissions: admin is required" {
+		t.Errorf("Ожидали подстановку аргумента, получили %q", got)
+	}
+}
+
+func TestT_KeyArgs(t *testing.T) {
+	args := []any{Key("field.name")}
+	if got := T(EN, "violation.required", args...); got != `Please fill in "Name"` {
+		t.Errorf("Ожидали переведенный аргумент, получили %q", got)
+	}
+	if got := T(RU, "violation.required", args...); got != "Заполните поле «Имя»" {
+		t.Errorf("Ожидали переведенный аргумент, получили %q", got)
+	}
+	if args[0] != Key("field.name") {
+		t.Errorf("Аргументы не должны изменяться: %v", args)
+	}
+}
+
+func TestLangFromContext(t *testing.T) {
+	if _, ok := LangFromContext(context.Background()); ok {
+		t.Error("Язык в пустом контексте")
+	}
+	lang, ok := LangFromContext(WithLang(context.Background(), EN))
+	if !ok || lang != EN {
+		t.Errorf("Ожидали %s, получили %q", EN, lang)
+	}
+}
@@ -0,0 +1,196 @@
+package i18n
+
+// Ключи: error.* - ошибки API, error.not_found.<ресурс> и error.already_exists.<ресурс> -
+// уточнения для ресурса, violation.* - нарушения валидации, field.* - названия полей.
+
+var ru = map[string]string{
+	"error.invalid_json":            "Неверный JSON формат",
+	"error.invalid_id":              "ID должен быть числом",
+	"error.invalid_param":           "Параметр %s должен быть числом",
+	"error.invalid_date":            "Параметр %s должен быть датой (YYYY-MM-DD или RFC 3339)",
+	"error.unavailable":             "Сервис запускается, повторите запрос позже",
+	"error.photo_missing":           "Ожидали файл в поле photo",
+	"error.file_unreadable":         "Не удалось прочитать файл",
+	"error.file_too_large":          "Файл слишком большой",
+	"error.unauthorized":            "Требуется авторизация",
+	"error.init_data_invalid":       "Неверные данные авторизации Telegram",
+	"error.init_data_expired":       "Данные авторизации Telegram устарели",
+	"error.forbidden":               "Доступ запрещен",
+	"error.missing_scope":           "Недостаточно прав: нужен %s",
+	"error.user_banned":             "Пользователь заблокирован",
+	"error.validation_failed":       "Ошибка валидации",
+	"error.internal":                "Внутренняя ошибка сервера",
+	"error.not_found":               "Не найдено",
+	"error.already_exists":          "Уже существует",
+	"error.not_found.user":          "Пользователь не найден",
+	"error.not_found.anquette":      "Анкета не найдена",
+	"error.not_found.match":         "Мэтч не найден",
+	"error.not_found.photo":         "Фото не найдено",
+	"error.not_found.api_key":       "Ключ API не найден",
+	"error.not_found.report":        "Жалоба не найдена",
+	"error.already_exists.user":     "Пользователь уже существует",
+	"error.already_exists.reaction": "Вы уже оценили эту анкету",
+	"error.already_exists.api_key":  "Ключ API с таким именем уже существует",
+
+	"violation.required":  "Заполните поле «%s»",
+	"violation.too_short": "«%s»: не короче %d символов",
+	"violation.too_long":  "«%s»: не длиннее %d символов",
+
+	"violation.age.out_of_range":     "Возраст должен быть от %d до %d лет",
+	"violation.age_max.out_of_range": "Максимальный возраст меньше минимального",
+	"violation.gender.required":      "Укажите пол",
+	"violation.gender.invalid":       "Пол должен быть m (мужской) или f (женский)",
+	"violation.city.invalid":         "Название города может содержать только буквы, пробелы и дефисы",
+	"violation.tg_id.invalid":        "tg_id должен быть положительным числом",
+	"violation.tg_username.invalid":  "Имя пользователя Telegram: 5-32 латинские буквы, цифры или _",
+	"violation.anquette_id.invalid":  "anquette_id не может быть отрицательным",
+	"violation.locale.invalid":       "Поддерживаются языки: %s",
+	"violation.cursor.invalid":       "Курсор не может быть отрицательным",
+	"violation.cursor.malformed":     "Неверный курсор: начните список заново",
+	"violation.q.no_words":           "В запросе нет слов для поиска",
+	"violation.status.invalid":       "Статус может быть только одним из: %s",
+
+	"violation.latitude.out_of_range":          "Широта должна быть от -90 до 90",
+	"violation.longitude.out_of_range":         "Долгота должна быть от -180 до 180",
+	"violation.location.required":              "Чтобы искать по расстоянию, поделитесь геопозицией",
+	"violation.max_distance_km.out_of_range":   "Расстояние поиска - от 0 до %d км",
+	"violation.after_distance_km.out_of_range": "Курсор расстояния не может быть отрицательным",
+	"violation.after_score.out_of_range":       "Курсор оценки должен быть от 0 до 1",
+	"violation.after_score.score_only":         "after_score работает только с sort=score",
+	"violation.sort.invalid":                   "Сортировка может быть только одной из: %s",
+
+	"violation.interests.too_many":  "Выберите не больше %d интересов",
+	"violation.goal.invalid":        "Цель знакомства может быть только одной из: %s",
+	"violation.dealbreaker.invalid": "Стоп-фактор может быть только одним из: %s",
+	"violation.interests.unknown":   "Неизвестный интерес: %s",
+
+	"violation.content.profanity":  "Уберите нецензурные и оскорбительные слова",
+	"violation.content.suspicious": "Уберите слова, которые могут быть оскорбительными",
+	"violation.content.spam":       "Реклама и спам в анкете запрещены",
+	"violation.content.link":       "Ссылки и контакты в анкете запрещены",
+	"violation.content.phone":      "Номера телефонов в анкете запрещены",
+
+	"violation.photo.unreadable":  "Не удалось прочитать изображение или оно слишком большое",
+	"violation.photo.size":        "Размер фото - до %d МБ",
+	"violation.photo.type":        "Поддерживаются фото JPEG, PNG и WebP",
+	"violation.photo.limit":       "В анкете может быть не больше %d фото",
+	"violation.photo_ids.invalid": "Перечислите каждое фото анкеты ровно один раз",
+
+	"violation.api_key.name":    "Укажите имя ключа и права",
+	"violation.api_key.scope":   "Неизвестное право: %s",
+	"violation.reaction.kind":   "Реакция должна быть like или dislike",
+	"violation.reaction.own":    "Нельзя оценить свою анкету",
+	"violation.block.self":      "Нельзя заблокировать самого себя",
+	"violation.report.self":     "Нельзя пожаловаться на самого себя",
+	"violation.report.reason":   "Неизвестная причина жалобы",
+	"violation.report.note":     "Комментарий: не длиннее %d символов",
+	"violation.target.required": "Укажите пользователя или анкету",
+
+	"violation.moderation.action":   "Решение должно быть approve, reject или ban",
+	"violation.moderation.reason":   "Укажите причину отказа",
+	"violation.moderation.anquette": "У пользователя нет анкеты",
+	"violation.moderation.owner":    "У анкеты нет владельца",
+	"violation.moderation.target":   "Укажите жалобу, анкету или пользователя",
+
+	"field.name":             "Имя",
+	"field.city":             "Город",
+	"field.description":      "О себе",
+	"field.q":                "Запрос",
+	"field.preferences.text": "Кого ищу",
+}
+
+var en = map[string]string{
+	"error.invalid_json":            "Invalid JSON",
+	"error.invalid_id":              "ID must be a number",
+	"error.invalid_param":           "Parameter %s must be a number",
+	"error.invalid_date":            "Parameter %s must be a date (YYYY-MM-DD or RFC 3339)",
+	"error.unavailable":             "The service is starting, please retry later",
+	"error.photo_missing":           "Expected a file in the photo field",
+	"error.file_unreadable":         "Could not read the file",
+	"error.file_too_large":          "File is too large",
+	"error.unauthorized":            "Authentication required",
+	"error.init_data_invalid":       "Invalid Telegram authentication data",
+	"error.init_data_expired":       "Telegram authentication data has expired",
+	"error.forbidden":               "Access denied",
+	"error.missing_scope":           "Insufficient permissions: %s is required",
+	"error.user_banned":             "User is banned",
+	"error.validation_failed":       "Validation failed",
+	"error.internal":                "Internal server error",
+	"error.not_found":               "Not found",
+	"error.already_exists":          "Already exists",
+	"error.not_found.user":          "User not found",
+	"error.not_found.anquette":      "Profile not found",
+	"error.not_found.match":         "Match not found",
+	"error.not_found.photo":         "Photo not found",
+	"error.not_found.api_key":       "API key not found",
+	"error.not_found.report":        "Report not found",
+	"error.already_exists.user":     "User already exists",
+	"error.already_exists.reaction": "You have already rated this profile",
+	"error.already_exists.api_key":  "An API key with this name already exists",
+
+	"violation.required":  "Please fill in \"%s\"",
+	"violation.too_short": "\"%s\" must be at least %d characters",
+	"violation.too_long":  "\"%s\" must be at most %d characters",
+
+	"violation.age.out_of_range":     "Age must be between %d and %d",
+	"violation.age_max.out_of_range": "Maximum age is less than minimum age",
+	"violation.gender.required":      "Please specify gender",
+	"violation.gender.invalid":       "Gender must be m (male) or f (female)",
+	"violation.city.invalid":         "City name may only contain letters, spaces and hyphens",
+	"violation.tg_id.invalid":        "tg_id must be a positive number",
+	"violation.tg_username.invalid":  "Telegram username: 5-32 Latin letters, digits or _",
+	"violation.anquette_id.invalid":  "anquette_id must not be negative",
+	"violation.locale.invalid":       "Supported languages: %s",
+	"violation.cursor.invalid":       "Cursor must not be negative",
+	"violation.cursor.malformed":     "Invalid cursor: start the list over",
+	"violation.q.no_words":           "The query has no words to search for",
+	"violation.status.invalid":       "Status must be one of: %s",
+
+	"violation.latitude.out_of_range":          "Latitude must be between -90 and 90",
+	"violation.longitude.out_of_range":         "Longitude must be between -180 and 180",
+	"violation.location.required":              "Share your location to search by distance",
+	"violation.max_distance_km.out_of_range":   "Search distance must be between 0 and %d km",
+	"violation.after_distance_km.out_of_range": "Distance cursor must not be negative",
+	"violation.after_score.out_of_range":       "Score cursor must be between 0 and 1",
+	"violation.after_score.score_only":         "after_score only works with sort=score",
+	"violation.sort.invalid":                   "Sort must be one of: %s",
+
+	"violation.interests.too_many":  "Choose at most %d interests",
+	"violation.goal.invalid":        "Goal must be one of: %s",
+	"violation.dealbreaker.invalid": "Dealbreaker must be one of: %s",
+	"violation.interests.unknown":   "Unknown interest: %s",
+
+	"violation.content.profanity":  "Please remove profanity and insults",
+	"violation.content.suspicious": "Please remove words that may be offensive",
+	"violation.content.spam":       "Ads and spam are not allowed in profiles",
+	"violation.content.link":       "Links and contacts are not allowed in profiles",
+	"violation.content.phone":      "Phone numbers are not allowed in profiles",
+
+	"violation.photo.unreadable":  "Could not read the image or it is too large",
+	"violation.photo.size":        "Photo size is limited to %d MB",
+	"violation.photo.type":        "Supported photo formats: JPEG, PNG and WebP",
+	"violation.photo.limit":       "A profile can have at most %d photos",
+	"violation.photo_ids.invalid": "List every photo of the profile exactly once",
+
+	"violation.api_key.name":    "API key needs a name and scopes",
+	"violation.api_key.scope":   "Unknown scope: %s",
+	"violation.reaction.kind":   "Reaction must be like or dislike",
+	"violation.reaction.own":    "You cannot rate your own profile",
+	"violation.block.self":      "You cannot block yourself",
+	"violation.report.self":     "You cannot report yourself",
+	"violation.report.reason":   "Unknown report reason",
+	"violation.report.note":     "Comment must be at most %d characters",
+	"violation.target.required": "Specify a user or a profile",
+
+	"violation.moderation.action":   "Decision must be approve, reject or ban",
+	"violation.moderation.reason":   "Please give a reason for rejection",
+	"violation.moderation.anquette": "User has no profile",
+	"violation.moderation.owner":    "Profile has no owner",
+	"violation.moderation.target":   "Specify a report, a profile or a user",
+
+	"field.name":             "Name",
+	"field.city":             "City",
+	"field.description":      "About",
+	"field.q":                "Query",
+	"field.preferences.text": "Looking for",
+}
@@ -0,0 +1,148 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // декодер WebP для image.Decode
+)
+
+// Имена уменьшенных копий фото
+const (
+	VariantMedium = "medium"
+	VariantThumb  = "thumb"
+)
+
+const (
+	ThumbSize   = 320  // сторона квадратной миниатюры для карточек ленты
+	MediumSize  = 1080 // максимальная сторона превью
+	MaxPixels   = 40_000_000
+	jpegQuality = 90
+)
+
+var (
+	ErrUnsupportedImage = errors.New("imaging: unsupported or corrupt image")
+	ErrImageTooLarge    = errors.New("imaging: image dimensions are too large")
+)
+
+// Variant - закодированное изображение
+type Variant struct {
+	Name        string
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// Result - очищенный оригинал и его уменьшенные копии
+type Result struct {
+	Original Variant
+	Variants []Variant
+}
+
+// Process - декодирует фото и заново кодирует его, что отбрасывает EXIF
+// (в том числе GPS) и прочие метаданные. Ориентация из EXIF перед этим
+// применяется к пикселям. Оригинал остается PNG для PNG, иначе становится JPEG;
+// превью и миниатюра всегда JPEG.
+func Process(data []byte) (Result, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxPixels {
+		return Result{}, fmt.Errorf("%w: %dx%d", ErrImageTooLarge, cfg.Width, cfg.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	img := toNRGBA(src)
+	if format == "jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	var res Result
+	if format == "png" {
+		res.Original, err = encodePNG("original", img)
+	} else {
+		res.Original, err = encodeJPEG("original", img)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	medium, err := encodeJPEG(VariantMedium, fit(img, MediumSize))
+	if err != nil {
+		return Result{}, err
+	}
+	thumb, err := encodeJPEG(VariantThumb, cropSquare(img, ThumbSize))
+	if err != nil {
+		return Result{}, err
+	}
+	res.Variants = []Variant{medium, thumb}
+	return res, nil
+}
+
+func toNRGBA(src image.Image) *image.NRGBA {
+	b := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
+
+// fit - уменьшает изображение так, чтобы большая сторона была не больше size
+func fit(img *image.NRGBA, size int) *image.NRGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	if w <= size && h <= size {
+		return img
+	}
+	if w >= h {
+		w, h = size, max(h*size/w, 1)
+	} else {
+		w, h = max(w*size/h, 1), size
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), xdraw.Src, nil)
+	return dst
+}
+
+// cropSquare - вырезает центральный квадрат и масштабирует его до size×size
+func cropSquare(img *image.NRGBA, size int) *image.NRGBA {
+	b := img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	x0, y0 := b.Min.X+(b.Dx()-side)/2, b.Min.Y+(b.Dy()-side)/2
+	crop := image.Rect(x0, y0, x0+side, y0+side)
+
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, crop, xdraw.Src, nil)
+	return dst
+}
+
+// encodeJPEG - JPEG не поддерживает прозрачность, поэтому фон заливается белым
+func encodeJPEG(name string, img *image.NRGBA) (Variant, error) {
+	flat := image.NewRGBA(img.Bounds())
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return Variant{}, fmt.Errorf("imaging: failed to encode %s as jpeg: %w", name, err)
+	}
+	return Variant{Name: name, Data: buf.Bytes(), ContentType: "image/jpeg", Width: img.Bounds().Dx(), Height: img.Bounds().Dy()}, nil
+}
+
+func encodePNG(name string, img *image.NRGBA) (Variant, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return Variant{}, fmt.Errorf("imaging: failed to encode %s as png: %w", name, err)
+	}
+	return Variant{Name: name, Data: buf.Bytes(), ContentType: "image/png", Width: img.Bounds().Dx(), Height: img.Bounds().Dy()}, nil
+}
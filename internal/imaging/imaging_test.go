@@ -0,0 +1,103 @@
+package imaging_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"bot-api/internal/imaging"
+)
+
+func testImage(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+// jpegWithEXIF - JPEG с сегментом APP1, где записаны Orientation и фейковые GPS-данные
+func jpegWithEXIF(t *testing.T, w, h int, orientation uint16) []byte {
+	var enc bytes.Buffer
+	if err := jpeg.Encode(&enc, testImage(w, h), nil); err != nil {
+		t.Fatalf("Не удалось закодировать JPEG: %v", err)
+	}
+
+	tiff := &bytes.Buffer{}
+	tiff.WriteString("II")
+	binary.Write(tiff, binary.LittleEndian, uint16(42))
+	binary.Write(tiff, binary.LittleEndian, uint32(8)) // IFD0 сразу за заголовком
+	binary.Write(tiff, binary.LittleEndian, uint16(1)) // одна запись
+	binary.Write(tiff, binary.LittleEndian, []uint16{0x0112, 3})
+	binary.Write(tiff, binary.LittleEndian, uint32(1))
+	binary.Write(tiff, binary.LittleEndian, []uint16{orientation, 0})
+	binary.Write(tiff, binary.LittleEndian, uint32(0))
+	tiff.WriteString("GPS 44.7235N 37.7686E")
+
+	app1 := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	out := []byte{0xFF, 0xD8, 0xFF, 0xE1}
+	out = binary.BigEndian.AppendUint16(out, uint16(len(app1)+2))
+	out = append(out, app1...)
+	return append(out, enc.Bytes()[2:]...)
+}
+
+func TestProcess_StripsEXIFAndAppliesOrientation(t *testing.T) {
+	data := jpegWithEXIF(t, 60, 40, 6) // 6 - повернуть на 90° по часовой
+
+	res, err := imaging.Process(data)
+	if err != nil {
+		t.Fatalf("Process провалился: %v", err)
+	}
+
+	if bytes.Contains(res.Original.Data, []byte("Exif")) || bytes.Contains(res.Original.Data, []byte("GPS")) {
+		t.Error("Метаданные EXIF остались в обработанном фото")
+	}
+	if res.Original.Width != 40 || res.Original.Height != 60 {
+		t.Errorf("Ожидали 40x60 после поворота, получили %dx%d", res.Original.Width, res.Original.Height)
+	}
+	if res.Original.ContentType != "image/jpeg" {
+		t.Errorf("Ожидали image/jpeg, получили %s", res.Original.ContentType)
+	}
+}
+
+func TestProcess_Variants(t *testing.T) {
+	var enc bytes.Buffer
+	png.Encode(&enc, testImage(2000, 1000))
+
+	res, err := imaging.Process(enc.Bytes())
+	if err != nil {
+		t.Fatalf("Process провалился: %v", err)
+	}
+
+	if res.Original.ContentType != "image/png" || res.Original.Width != 2000 {
+		t.Errorf("Неверный оригинал: %s %dx%d", res.Original.ContentType, res.Original.Width, res.Original.Height)
+	}
+	sizes := map[string][2]int{}
+	for _, v := range res.Variants {
+		sizes[v.Name] = [2]int{v.Width, v.Height}
+		if _, err := jpeg.DecodeConfig(bytes.NewReader(v.Data)); err != nil {
+			t.Errorf("Копия %s не является JPEG: %v", v.Name, err)
+		}
+	}
+	if sizes[imaging.VariantMedium] != [2]int{imaging.MediumSize, imaging.MediumSize / 2} {
+		t.Errorf("Неверный размер превью: %v", sizes[imaging.VariantMedium])
+	}
+	if sizes[imaging.VariantThumb] != [2]int{imaging.ThumbSize, imaging.ThumbSize} {
+		t.Errorf("Неверный размер миниатюры: %v", sizes[imaging.VariantThumb])
+	}
+}
+
+func TestProcess_Garbage(t *testing.T) {
+	_, err := imaging.Process([]byte("\x89PNG\r\n\x1a\nnot really a png"))
+
+	if !errors.Is(err, imaging.ErrUnsupportedImage) {
+		t.Errorf("Ожидали imaging.ErrUnsupportedImage, получили %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+)
+
+// jpegOrientation - значение тега EXIF Orientation (1..8) из JPEG; 1, если тега нет
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 { // начало данных изображения или конец файла
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+size]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + size
+	}
+	return 1
+}
+
+// tiffOrientation - ищет тег 0x0112 в IFD0 блока TIFF из сегмента APP1
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < entries; n++ {
+		e := ifd + 2 + n*12
+		if e+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[e:]) == 0x0112 {
+			if v := int(order.Uint16(tiff[e+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// applyOrientation - поворачивает и отражает пиксели так, как того требует
+// EXIF Orientation, чтобы фото не перевернулось после удаления EXIF
+func applyOrientation(img *image.NRGBA, orientation int) *image.NRGBA {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // отражение по горизонтали
+				sx, sy = w-1-x, y
+			case 3: // поворот на 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // отражение по вертикали
+				sx, sy = x, h-1-y
+			case 5: // транспонирование
+				sx, sy = y, x
+			case 6: // поворот на 90° по часовой
+				sx, sy = y, h-1-x
+			case 7: // транспонирование по побочной диагонали
+				sx, sy = w-1-y, h-1-x
+			case 8: // поворот на 90° против часовой
+				sx, sy = w-1-y, x
+			}
+			si, di := img.PixOffset(sx, sy), dst.PixOffset(x, y)
+			copy(dst.Pix[di:di+4], img.Pix[si:si+4])
+		}
+	}
+	return dst
+}
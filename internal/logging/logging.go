@@ -0,0 +1,91 @@
+// Package logging - структурные логи (log/slog) с ID запроса. Записи,
+// сделанные с контекстом запроса (slog.InfoContext(ctx, ...) и т.п.),
+// получают атрибут request_id, по которому запрос прослеживается через
+// handler, service и repository.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// KeyRequestID - атрибут записи с ID запроса
+const KeyRequestID = "request_id"
+
+// maxRequestIDLen - ID клиента длиннее этого заменяется своим
+const maxRequestIDLen = 128
+
+// New - логгер, который пишет в w записи уровня level и выше ("debug",
+// "info", "warn", "error") в формате format ("text" или "json")
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("logging: invalid level %q: %w", level, err)
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	var h slog.Handler
+	switch format {
+	case "text":
+		h = slog.NewTextHandler(w, opts)
+	case "json":
+		h = slog.NewJSONHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("logging: invalid format %q", format)
+	}
+	return slog.New(contextHandler{h}), nil
+}
+
+// contextHandler - добавляет к записи request_id из контекста
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String(KeyRequestID, id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+type requestIDKey struct{}
+
+// WithRequestID - контекст с ID запроса
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID - ID запроса из контекста; пусто, если его нет
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID - случайный ID запроса (32 шестнадцатеричных символа)
+func NewRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ValidRequestID - подходит ли ID, присланный клиентом: непустой, не длиннее
+// maxRequestIDLen и только из видимых символов ASCII, чтобы не ломать строки лога
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	return !strings.ContainsFunc(id, func(r rune) bool { return r <= ' ' || r > '~' })
+}
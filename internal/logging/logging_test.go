@@ -0,0 +1,62 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNew_RequestIDAndLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "info", "json")
+	if err != nil {
+		t.Fatalf("New провалился: %v", err)
+	}
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	logger.DebugContext(ctx, "не попадет в лог")
+	logger.With("layer", "service").WarnContext(ctx, "operation failed", "user_id", 5)
+	logger.Info("без запроса")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Ожидали 2 записи, получили %d: %s", len(lines), buf.String())
+	}
+	var rec map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatalf("Запись не JSON: %v", err)
+	}
+	if rec[KeyRequestID] != "req-1" || rec["layer"] != "service" || rec["level"] != "WARN" {
+		t.Errorf("Неверная запись: %v", rec)
+	}
+	if strings.Contains(lines[1], KeyRequestID) {
+		t.Errorf("Запись без контекста запроса не должна содержать request_id: %s", lines[1])
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	if _, err := New(&bytes.Buffer{}, "verbose", "text"); err == nil {
+		t.Error("Ожидали ошибку неизвестного уровня")
+	}
+	if _, err := New(&bytes.Buffer{}, "info", "xml"); err == nil {
+		t.Error("Ожидали ошибку неизвестного формата")
+	}
+}
+
+func TestValidRequestID(t *testing.T) {
+	for id, want := range map[string]bool{
+		"9f1c2d3e-bot":           true,
+		NewRequestID():           true,
+		"":                       false,
+		"two words":              false,
+		"line\nbreak":            false,
+		"кириллица":              false,
+		strings.Repeat("a", 129): false,
+	} {
+		if got := ValidRequestID(id); got != want {
+			t.Errorf("ValidRequestID(%q) = %v, ожидали %v", id, got, want)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+// Package metrics - счетчики, гистограммы и показатели, которые отдаются
+// в текстовом формате Prometheus (https://prometheus.io/docs/instrumenting/exposition_formats/).
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefBuckets - границы гистограммы по умолчанию, в секундах (как в клиенте Prometheus)
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Типы метрик
+const (
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+)
+
+// sample - одна строка вывода: суффикс имени, метки в виде {a="b"} и значение
+type sample struct {
+	suffix string
+	labels string
+	value  float64
+}
+
+type family struct {
+	name, help, typ string
+	collect         func(ctx context.Context) ([]sample, error)
+}
+
+// Registry - набор метрик. Отдает их по HTTP в порядке регистрации.
+type Registry struct {
+	mu       sync.Mutex
+	families []family
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// register - добавляет метрику; повторное имя - ошибка программы
+func (r *Registry) register(f family) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if slices.ContainsFunc(r.families, func(x family) bool { return x.name == f.name }) {
+		panic("metrics: duplicate metric " + f.name)
+	}
+	r.families = append(r.families, f)
+}
+
+// NewCounter - счетчик с метками labels
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{series: newSeries[float64](labels)}
+	r.register(family{name: name, help: help, typ: typeCounter, collect: c.collect})
+	return c
+}
+
+// NewHistogram - гистограмма с границами buckets (по возрастанию) и метками labels
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{buckets: buckets, series: newSeries[*histogramValue](labels)}
+	r.register(family{name: name, help: help, typ: typeHistogram, collect: h.collect})
+	return h
+}
+
+// GaugeFunc - показатель, значение которого вычисляет fn при каждом запросе метрик
+func (r *Registry) GaugeFunc(name, help string, fn func(ctx context.Context) (float64, error)) {
+	r.register(family{name: name, help: help, typ: typeGauge, collect: valueFunc(fn)})
+}
+
+// CounterFunc - счетчик, который ведется вне Registry (например, в database/sql)
+func (r *Registry) CounterFunc(name, help string, fn func(ctx context.Context) (float64, error)) {
+	r.register(family{name: name, help: help, typ: typeCounter, collect: valueFunc(fn)})
+}
+
+func valueFunc(fn func(ctx context.Context) (float64, error)) func(ctx context.Context) ([]sample, error) {
+	return func(ctx context.Context) ([]sample, error) {
+		v, err := fn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return []sample{{value: v}}, nil
+	}
+}
+
+// WriteTo - все метрики в текстовом формате. Метрика, значение которой
+// не удалось получить, пропускается, чтобы не терять остальные.
+func (r *Registry) WriteTo(ctx context.Context, w io.Writer) error {
+	r.mu.Lock()
+	families := slices.Clone(r.families)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		samples, err := f.collect(ctx)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to collect metric", "metric", f.name, "error", err)
+			continue
+		}
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n", f.name, escapeHelp(f.help), f.name, f.typ)
+		for _, s := range samples {
+			fmt.Fprintf(bw, "%s%s%s %s\n", f.name, s.suffix, s.labels, formatValue(s.value))
+		}
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP - отдает метрики Prometheus
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := r.WriteTo(req.Context(), w); err != nil {
+		slog.WarnContext(req.Context(), "failed to write metrics", "error", err)
+	}
+}
+
+// series - значения метрики по наборам значений меток
+type series[V any] struct {
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]V
+	keys   map[string][]string // ключ -> значения меток
+}
+
+func newSeries[V any](labels []string) series[V] {
+	return series[V]{labels: labels, values: map[string]V{}, keys: map[string][]string{}}
+}
+
+// update - вызывает fn со значением для labelValues под блокировкой.
+// Число значений меток должно совпадать с числом меток - иначе это ошибка программы.
+func (s *series[V]) update(labelValues []string, fn func(v *V)) {
+	if len(labelValues) != len(s.labels) {
+		panic(fmt.Sprintf("metrics: expected %d label values, got %d", len(s.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.values[key]
+	if !ok {
+		s.keys[key] = slices.Clone(labelValues)
+	}
+	fn(&v)
+	s.values[key] = v
+}
+
+// each - вызывает fn для каждого набора меток в порядке их значений
+func (s *series[V]) each(fn func(labelValues []string, v V)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.values))
+	for k := range s.values {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		fn(s.keys[k], s.values[k])
+	}
+}
+
+// Counter - счетчик; только растет
+type Counter struct {
+	series series[float64]
+}
+
+// Inc - увеличивает на 1 счетчик с метками labelValues
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add - увеличивает счетчик на v (v >= 0)
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		panic("metrics: counter cannot decrease")
+	}
+	c.series.update(labelValues, func(x *float64) { *x += v })
+}
+
+func (c *Counter) collect(context.Context) ([]sample, error) {
+	var out []sample
+	c.series.each(func(lv []string, v float64) {
+		out = append(out, sample{labels: formatLabels(c.series.labels, lv), value: v})
+	})
+	return out, nil
+}
+
+// Histogram - распределение значений по корзинам
+type Histogram struct {
+	buckets []float64
+	series  series[*histogramValue]
+}
+
+type histogramValue struct {
+	counts []uint64 // по корзинам, без накопления; последняя - +Inf
+	sum    float64
+	count  uint64
+}
+
+// Observe - добавляет значение v в гистограмму с метками labelValues
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.series.update(labelValues, func(x **histogramValue) {
+		if *x == nil {
+			*x = &histogramValue{counts: make([]uint64, len(h.buckets)+1)}
+		}
+		i, _ := slices.BinarySearch(h.buckets, v)
+		(*x).counts[i]++
+		(*x).sum += v
+		(*x).count++
+	})
+}
+
+func (h *Histogram) collect(context.Context) ([]sample, error) {
+	var out []sample
+	names := append(slices.Clone(h.series.labels), "le")
+	h.series.each(func(lv []string, v *histogramValue) {
+		var cumulative uint64
+		for i, n := range v.counts {
+			cumulative += n
+			le := math.Inf(1)
+			if i < len(h.buckets) {
+				le = h.buckets[i]
+			}
+			out = append(out, sample{suffix: "_bucket", labels: formatLabels(names, append(slices.Clone(lv), formatValue(le))), value: float64(cumulative)})
+		}
+		labels := formatLabels(h.series.labels, lv)
+		out = append(out, sample{suffix: "_sum", labels: labels, value: v.sum}, sample{suffix: "_count", labels: labels, value: float64(v.count)})
+	})
+	return out, nil
+}
+
+// formatLabels - {name="value",...}; пусто, если меток нет
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(labelEscaper.Replace(values[i]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_WriteTo(t *testing.T) {
+	reg := NewRegistry()
+	requests := reg.NewCounter("http_requests_total", "Число запросов", "route", "status")
+	duration := reg.NewHistogram("http_request_duration_seconds", "Длительность запросов", []float64{0.25, 1}, "route")
+	reg.GaugeFunc("users", "Число пользователей", func(context.Context) (float64, error) { return 42, nil })
+	reg.GaugeFunc("broken", "Не вычисляется", func(context.Context) (float64, error) { return 0, errors.New("db closed") })
+
+	requests.Inc("GET /users/{id}", "200")
+	requests.Add(2, "GET /users/{id}", "200")
+	requests.Inc(`say "hi"`+"\n", "500")
+	duration.Observe(0.125, "GET /users/{id}")
+	duration.Observe(0.25, "GET /users/{id}")
+	duration.Observe(3, "GET /users/{id}")
+
+	var b strings.Builder
+	if err := reg.WriteTo(context.Background(), &b); err != nil {
+		t.Fatalf("WriteTo провалился: %v", err)
+	}
+	want := `# HELP http_requests_total Число запросов
+# TYPE http_requests_total counter
+http_requests_total{route="GET /users/{id}",status="200"} 3
+http_requests_total{route="say \"hi\"\n",status="500"} 1
+# HELP http_request_duration_seconds Длительность запросов
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{route="GET /users/{id}",le="0.25"} 2
+http_request_duration_seconds_bucket{route="GET /users/{id}",le="1"} 2
+http_request_duration_seconds_bucket{route="GET /users/{id}",le="+Inf"} 3
+http_request_duration_seconds_sum{route="GET /users/{id}"} 3.375
+http_request_duration_seconds_count{route="GET /users/{id}"} 3
+# HELP users Число пользователей
+# TYPE users gauge
+users 42
+`
+	if got := b.String(); got != want {
+		t.Errorf("Неверный вывод метрик:\n%s\nожидали:\n%s", got, want)
+	}
+}
+
+func TestRegistry_ServeHTTP(t *testing.T) {
+	reg := NewRegistry()
+	reg.NewCounter("jobs_total", "Число задач").Inc()
+
+	rr := httptest.NewRecorder()
+	reg.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Неверный Content-Type: %s", ct)
+	}
+	if !strings.Contains(rr.Body.String(), "jobs_total 1\n") {
+		t.Errorf("Нет значения счетчика: %s", rr.Body.String())
+	}
+}
+
+func TestCounter_WrongLabels(t *testing.T) {
+	c := NewRegistry().NewCounter("errors_total", "Ошибки", "kind")
+	defer func() {
+		if recover() == nil {
+			t.Error("Ожидали панику при неверном числе меток")
+		}
+	}()
+	c.Inc()
+}
@@ -0,0 +1,264 @@
+// Package ranking - оценка совместимости кандидата со зрителем для
+// упорядочивания ленты. Оценка - взвешенное среднее сигналов от 0 до 1;
+// веса настраиваются, вклад каждого сигнала виден в объяснении.
+package ranking
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"bot-api/internal/domain"
+)
+
+// Signal - один из признаков совместимости
+type Signal string
+
+const (
+	SignalInterests    Signal = "interests"    // доля общих интересов
+	SignalAgeFit       Signal = "age_fit"      // близость возраста кандидата к середине искомого зрителем диапазона
+	SignalDistance     Signal = "distance"     // близость
+	SignalCompleteness Signal = "completeness" // заполненность анкеты
+	SignalRecency      Signal = "recency"      // как давно кандидат был активен
+	SignalReciprocity  Signal = "reciprocity"  // насколько вероятна взаимность
+)
+
+// Signals - все сигналы в порядке вывода в объяснении
+var Signals = []Signal{
+	SignalInterests, SignalAgeFit, SignalDistance,
+	SignalCompleteness, SignalRecency, SignalReciprocity,
+}
+
+// Параметры сигналов
+const (
+	ageSlackYears          = 5   // за столько лет вне диапазона возраст перестает подходить
+	distanceScaleKm        = 20  // на таком расстоянии distance = 0.5
+	sameCityDistance       = 0.5 // distance без координат, но в одном городе
+	recencyScaleDays       = 3   // через столько дней recency = 0.5
+	completeDescriptionLen = 200 // описание такой длины считается полным
+	completePhotoCount     = 3
+)
+
+// Weights - вес каждого сигнала. Сигналы без веса не учитываются.
+type Weights map[Signal]float64
+
+// DefaultWeights - веса по умолчанию
+func DefaultWeights() Weights {
+	return Weights{
+		SignalInterests:    3,
+		SignalAgeFit:       2,
+		SignalDistance:     2,
+		SignalCompleteness: 1,
+		SignalRecency:      1.5,
+		SignalReciprocity:  2,
+	}
+}
+
+// ParseWeights - веса по умолчанию, измененные строкой вида "interests=5,recency=0"
+func ParseWeights(s string) (Weights, error) {
+	w := DefaultWeights()
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("ranking: expected signal=weight, got %q", part)
+		}
+		sig := Signal(strings.TrimSpace(name))
+		if !slices.Contains(Signals, sig) {
+			return nil, fmt.Errorf("ranking: unknown signal %q", sig)
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || f < 0 || math.IsInf(f, 0) || math.IsNaN(f) {
+			return nil, fmt.Errorf("ranking: invalid weight %q for %s", value, sig)
+		}
+		w[sig] = f
+	}
+	return w, nil
+}
+
+// Viewer - тот, для кого строится лента
+type Viewer struct {
+	Age       int
+	Gender    string
+	CityID    string
+	Interests []string
+	// Искомый возраст; 0 - без ограничения
+	AgeMin, AgeMax int
+}
+
+// Candidate - анкета из ленты и то, что известно о ее владельце
+type Candidate struct {
+	Age       int
+	Gender    string
+	CityID    string
+	Interests []string
+	// Кого ищет кандидат: возраст (0 - без ограничения) и пол (пусто - любой)
+	AgeMin, AgeMax int
+	Genders        []string
+
+	DistanceKm int // 0 - неизвестно
+
+	DescriptionLength int // в символах
+	PhotoCount        int
+	HasLocation       bool
+
+	LastActiveAt   time.Time // нулевое - неизвестно
+	LikesGiven     int
+	ReactionsGiven int
+}
+
+// Scorer - оценивает кандидатов с заданными весами
+type Scorer struct {
+	Weights Weights
+	Now     func() time.Time
+}
+
+func New(w Weights) *Scorer {
+	return &Scorer{Weights: w, Now: time.Now}
+}
+
+// Score - оценка кандидата для зрителя с вкладом каждого сигнала
+func (s *Scorer) Score(v Viewer, c Candidate) domain.ScoreExplanation {
+	values := map[Signal]float64{
+		SignalInterests:    overlap(v.Interests, c.Interests),
+		SignalAgeFit:       ageCentered(c.Age, v.AgeMin, v.AgeMax),
+		SignalDistance:     distance(v, c),
+		SignalCompleteness: completeness(c),
+		SignalRecency:      s.recency(c.LastActiveAt),
+		SignalReciprocity:  reciprocity(v, c),
+	}
+
+	var total float64
+	for _, sig := range Signals {
+		total += s.Weights[sig]
+	}
+
+	exp := domain.ScoreExplanation{Signals: make([]domain.SignalScore, 0, len(Signals))}
+	for _, sig := range Signals {
+		w := s.Weights[sig]
+		contribution := 0.0
+		if total > 0 {
+			contribution = w * values[sig] / total
+		}
+		exp.Score += contribution
+		exp.Signals = append(exp.Signals, domain.SignalScore{
+			Signal: string(sig), Value: round(values[sig]), Weight: w, Contribution: round(contribution),
+		})
+	}
+	exp.Score = round(exp.Score)
+	return exp
+}
+
+// overlap - коэффициент Жаккара двух наборов интересов
+func overlap(a, b []string) float64 {
+	inA := map[string]bool{}
+	for _, x := range a {
+		inA[x] = true
+	}
+	inB := map[string]bool{}
+	shared := 0
+	for _, x := range b {
+		if inB[x] {
+			continue
+		}
+		inB[x] = true
+		if inA[x] {
+			shared++
+		}
+	}
+	union := len(inA) + len(inB) - shared
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
+
+// ageFit - 1 внутри [minAge, maxAge], линейно до 0 за ageSlackYears вне его
+func ageFit(age, minAge, maxAge int) float64 {
+	gap := 0
+	switch {
+	case minAge > 0 && age < minAge:
+		gap = minAge - age
+	case maxAge > 0 && age > maxAge:
+		gap = age - maxAge
+	}
+	return math.Max(0, 1-float64(gap)/ageSlackYears)
+}
+
+// ageCentered - 1 в середине [minAge, maxAge], 1/2 на границах и линейно до 0
+// за ageSlackYears вне диапазона. Лента и так отбирает кандидатов по искомому
+// возрасту, поэтому сигнал различает их по тому, насколько точно возраст попадает.
+// Без одной из границ - как ageFit.
+func ageCentered(age, minAge, maxAge int) float64 {
+	if minAge <= 0 || maxAge <= minAge {
+		return ageFit(age, minAge, maxAge)
+	}
+	if age < minAge || age > maxAge {
+		return ageFit(age, minAge, maxAge) / 2
+	}
+	mid, half := float64(minAge+maxAge)/2, float64(maxAge-minAge)/2
+	return 1 - math.Abs(float64(age)-mid)/half/2
+}
+
+func distance(v Viewer, c Candidate) float64 {
+	if c.DistanceKm > 0 {
+		return 1 / (1 + float64(c.DistanceKm)/distanceScaleKm)
+	}
+	if v.CityID != "" && v.CityID == c.CityID {
+		return sameCityDistance
+	}
+	return 0
+}
+
+// completeness - доля заполненных частей анкеты
+func completeness(c Candidate) float64 {
+	parts := []float64{
+		math.Min(1, float64(c.DescriptionLength)/completeDescriptionLen),
+		math.Min(1, float64(c.PhotoCount)/completePhotoCount),
+		flag(c.HasLocation),
+		flag(c.CityID != ""),
+		flag(len(c.Interests) > 0),
+	}
+	var sum float64
+	for _, p := range parts {
+		sum += p
+	}
+	return sum / float64(len(parts))
+}
+
+func (s *Scorer) recency(t time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+	days := math.Max(0, s.Now().Sub(t).Hours()/24)
+	return 1 / (1 + days/recencyScaleDays)
+}
+
+// reciprocity - подходит ли зритель под то, кого ищет кандидат, с поправкой
+// на то, как часто кандидат ставит лайки (новичкам - 1/2)
+func reciprocity(v Viewer, c Candidate) float64 {
+	fit := ageFit(v.Age, c.AgeMin, c.AgeMax)
+	if len(c.Genders) > 0 && v.Gender != "" && !slices.Contains(c.Genders, v.Gender) {
+		fit = 0
+	}
+	likeRate := float64(c.LikesGiven+1) / float64(c.ReactionsGiven+2)
+	return fit * (0.5 + likeRate/2)
+}
+
+func flag(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// round - 4 знака после запятой, чтобы объяснение было читаемым
+func round(x float64) float64 {
+	return math.Round(x*1e4) / 1e4
+}
@@ -0,0 +1,115 @@
+package ranking
+
+import (
+	"testing"
+	"time"
+)
+
+var now = time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+func newTestScorer(w Weights) *Scorer {
+	s := New(w)
+	s.Now = func() time.Time { return now }
+	return s
+}
+
+func signal(t *testing.T, s *Scorer, v Viewer, c Candidate, sig Signal) float64 {
+	t.Helper()
+	for _, ss := range s.Score(v, c).Signals {
+		if ss.Signal == string(sig) {
+			return ss.Value
+		}
+	}
+	t.Fatalf("Нет сигнала %s в объяснении", sig)
+	return 0
+}
+
+func TestScore_BetterCandidateFirst(t *testing.T) {
+	s := newTestScorer(DefaultWeights())
+	v := Viewer{Age: 25, Gender: "m", CityID: "moscow", Interests: []string{"music", "it"}, AgeMin: 20, AgeMax: 30}
+
+	good := Candidate{
+		Age: 24, Gender: "f", CityID: "moscow", Interests: []string{"music", "it"},
+		AgeMin: 20, AgeMax: 30, Genders: []string{"m"}, DistanceKm: 3,
+		DescriptionLength: 300, PhotoCount: 3, HasLocation: true,
+		LastActiveAt: now.Add(-time.Hour), LikesGiven: 5, ReactionsGiven: 6,
+	}
+	poor := Candidate{Age: 40, Gender: "f", Genders: []string{"f"}, LastActiveAt: now.Add(-60 * 24 * time.Hour)}
+
+	g, p := s.Score(v, good), s.Score(v, poor)
+	if g.Score <= p.Score {
+		t.Errorf("Ожидали, что подходящий кандидат выше: %v <= %v", g.Score, p.Score)
+	}
+	if g.Score > 1 || p.Score < 0 {
+		t.Errorf("Оценка вне 0..1: %v, %v", g.Score, p.Score)
+	}
+	if len(g.Signals) != len(Signals) {
+		t.Errorf("Ожидали %d сигналов в объяснении, получили %+v", len(Signals), g.Signals)
+	}
+
+	// Сумма вкладов равна оценке
+	var sum float64
+	for _, ss := range g.Signals {
+		sum += ss.Contribution
+	}
+	if d := sum - g.Score; d > 0.001 || d < -0.001 {
+		t.Errorf("Сумма вкладов %v не равна оценке %v", sum, g.Score)
+	}
+}
+
+func TestScore_Signals(t *testing.T) {
+	s := newTestScorer(DefaultWeights())
+	v := Viewer{Age: 25, Gender: "m", AgeMin: 20, AgeMax: 30, Interests: []string{"a", "b"}}
+
+	if got := signal(t, s, v, Candidate{Interests: []string{"b", "c", "c"}}, SignalInterests); got != 0.3333 {
+		t.Errorf("interests: ожидали 1/3, получили %v", got)
+	}
+	for age, want := range map[int]float64{25: 1, 28: 0.7, 20: 0.5, 30: 0.5, 33: 0.2, 40: 0} {
+		if got := signal(t, s, v, Candidate{Age: age}, SignalAgeFit); got != want {
+			t.Errorf("age_fit: для возраста %d в диапазоне 20-30 ожидали %v, получили %v", age, want, got)
+		}
+	}
+	if got := signal(t, s, v, Candidate{DistanceKm: distanceScaleKm}, SignalDistance); got != 0.5 {
+		t.Errorf("distance: ожидали 0.5, получили %v", got)
+	}
+	if got := signal(t, s, v, Candidate{LastActiveAt: now.Add(-recencyScaleDays * 24 * time.Hour)}, SignalRecency); got != 0.5 {
+		t.Errorf("recency: ожидали 0.5, получили %v", got)
+	}
+	if got := signal(t, s, v, Candidate{Genders: []string{"f"}}, SignalReciprocity); got != 0 {
+		t.Errorf("reciprocity: кандидат ищет другой пол, получили %v", got)
+	}
+	if got := signal(t, s, v, Candidate{}, SignalReciprocity); got != 0.75 {
+		t.Errorf("reciprocity: ожидали 0.75 для новичка без предпочтений, получили %v", got)
+	}
+}
+
+func TestScore_Weights(t *testing.T) {
+	// Учитывается только расстояние
+	w := Weights{SignalDistance: 1}
+	s := newTestScorer(w)
+
+	near := s.Score(Viewer{}, Candidate{DistanceKm: 1, PhotoCount: 10})
+	far := s.Score(Viewer{}, Candidate{DistanceKm: 100})
+	if near.Score <= far.Score {
+		t.Errorf("Ожидали, что ближний кандидат выше: %v <= %v", near.Score, far.Score)
+	}
+	if zero := newTestScorer(Weights{}).Score(Viewer{}, Candidate{DistanceKm: 1}); zero.Score != 0 {
+		t.Errorf("Без весов оценка должна быть 0, получили %v", zero.Score)
+	}
+}
+
+func TestParseWeights(t *testing.T) {
+	w, err := ParseWeights("interests=5, recency=0")
+	if err != nil {
+		t.Fatalf("ParseWeights провалился: %v", err)
+	}
+	if w[SignalInterests] != 5 || w[SignalRecency] != 0 || w[SignalDistance] != DefaultWeights()[SignalDistance] {
+		t.Errorf("Неверные веса: %v", w)
+	}
+
+	for _, bad := range []string{"looks=1", "interests", "interests=-1", "interests=много"} {
+		if _, err := ParseWeights(bad); err == nil {
+			t.Errorf("Ожидали ошибку для %q", bad)
+		}
+	}
+}
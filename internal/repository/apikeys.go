@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"bot-api/internal/domain"
+)
+
+// --- Методы APIKey ---
+
+// Права хранятся строкой через пробел
+const apiKeyColumns = "id, name, scopes, created_at, revoked_at"
+
+func scanAPIKey(row interface{ Scan(...any) error }) (domain.APIKey, error) {
+	var (
+		k       domain.APIKey
+		scopes  string
+		revoked sql.NullTime
+	)
+	if err := row.Scan(&k.ID, &k.Name, &scopes, &k.CreatedAt, &revoked); err != nil {
+		return domain.APIKey{}, err
+	}
+	k.Scopes = strings.Fields(scopes)
+	if revoked.Valid {
+		k.RevokedAt = &revoked.Time
+	}
+	return k, nil
+}
+
+// InsertAPIKey - сохраняет ключ по его хешу. Занятое имя возвращает ErrDuplicate.
+func (s *Storage) InsertAPIKey(ctx context.Context, req domain.APIKeyRequest, keyHash string) (domain.APIKey, error) {
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO api_keys (name, key_hash, scopes) VALUES (?, ?, ?) ON CONFLICT DO NOTHING`,
+		req.Name, keyHash, strings.Join(req.Scopes, " "),
+	)
+	if err != nil {
+		return domain.APIKey{}, fmt.Errorf("repository: failed to insert api key: %w", err)
+	}
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return domain.APIKey{}, ErrDuplicate
+	}
+	return s.GetAPIKeyByHash(ctx, keyHash)
+}
+
+func (s *Storage) GetAPIKeyByHash(ctx context.Context, keyHash string) (domain.APIKey, error) {
+	row := s.db.QueryRowContext(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE key_hash = ?", keyHash)
+	k, err := scanAPIKey(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.APIKey{}, sql.ErrNoRows
+		}
+		return domain.APIKey{}, fmt.Errorf("repository: failed scanning api key: %w", err)
+	}
+	return k, nil
+}
+
+func (s *Storage) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+apiKeyColumns+" FROM api_keys ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []domain.APIKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed scanning api key: %w", err)
+		}
+		keys = append(keys, k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: failed iterating api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey - отзывает действующий ключ. Отсутствующий или уже отозванный ключ - sql.ErrNoRows.
+func (s *Storage) RevokeAPIKey(ctx context.Context, id int) error {
+	res, err := s.db.ExecContext(ctx, "UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL", time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("repository: failed to execute revoke api key: %w", err)
+	}
+
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"bot-api/internal/domain"
+)
+
+// blockedBetween - условие "между пользователями ? и ? есть блокировка в любую сторону"
+const blockedBetween = `EXISTS (SELECT 1 FROM blocks
+         WHERE (blocker_tg_id = ? AND blocked_tg_id = ?) OR (blocker_tg_id = ? AND blocked_tg_id = ?))`
+
+// --- Методы Block ---
+
+// InsertBlock - блокирует пользователя; повторная блокировка ничего не меняет
+func (s *Storage) InsertBlock(ctx context.Context, blockerTgID, blockedTgID int64) (domain.Block, error) {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO blocks (blocker_tg_id, blocked_tg_id) VALUES (?, ?)
+         ON CONFLICT (blocker_tg_id, blocked_tg_id) DO NOTHING`,
+		blockerTgID, blockedTgID,
+	)
+	if err != nil {
+		return domain.Block{}, fmt.Errorf("repository: failed to insert block: %w", err)
+	}
+
+	var b domain.Block
+	err = s.db.QueryRowContext(ctx,
+		"SELECT blocker_tg_id, blocked_tg_id, created_at FROM blocks WHERE blocker_tg_id = ? AND blocked_tg_id = ?",
+		blockerTgID, blockedTgID,
+	).Scan(&b.BlockerTgID, &b.BlockedTgID, &b.CreatedAt)
+	if err != nil {
+		return domain.Block{}, fmt.Errorf("repository: failed scanning block: %w", err)
+	}
+	return b, nil
+}
+
+// IsBlocked - заблокировал ли кто-то из пары другого
+func (s *Storage) IsBlocked(ctx context.Context, tgID1, tgID2 int64) (bool, error) {
+	var blocked bool
+	err := s.db.QueryRowContext(ctx, "SELECT "+blockedBetween, tgID1, tgID2, tgID2, tgID1).Scan(&blocked)
+	if err != nil {
+		return false, fmt.Errorf("repository: failed to check block: %w", err)
+	}
+	return blocked, nil
+}
+
+// --- Методы Report ---
+
+const reportColumns = "id, reporter_tg_id, reported_tg_id, reported_anquette_id, reason, note, status, created_at"
+
+func scanReport(row interface{ Scan(...any) error }) (domain.Report, error) {
+	var r domain.Report
+	err := row.Scan(&r.ID, &r.ReporterTgID, &r.ReportedTgID, &r.ReportedAnquetteID, &r.Reason, &r.Note, &r.Status, &r.CreatedAt)
+	return r, err
+}
+
+func (s *Storage) InsertReport(ctx context.Context, r domain.Report) (domain.Report, error) {
+	row := s.db.QueryRowContext(ctx,
+		`INSERT INTO reports (reporter_tg_id, reported_tg_id, reported_anquette_id, reason, note)
+         VALUES (?, ?, ?, ?, ?)
+         RETURNING `+reportColumns,
+		r.ReporterTgID, r.ReportedTgID, r.ReportedAnquetteID, r.Reason, r.Note,
+	)
+	out, err := scanReport(row)
+	if err != nil {
+		return domain.Report{}, fmt.Errorf("repository: failed to insert report: %w", err)
+	}
+	return out, nil
+}
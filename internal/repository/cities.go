@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"bot-api/internal/domain"
+)
+
+// --- Методы City ---
+
+// ListAnquettesWithoutCityID - анкеты с нераспознанным городом по возрастанию ID после afterID
+func (s *Storage) ListAnquettesWithoutCityID(ctx context.Context, afterID, limit int) ([]domain.Anquette, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+anquetteColumns+" FROM anquettes WHERE city_id = '' AND city != '' AND id > ? ORDER BY id LIMIT ?",
+		afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query anquettes without city: %w", err)
+	}
+	defer rows.Close()
+
+	anquettes := []domain.Anquette{}
+	for rows.Next() {
+		a, err := scanAnquette(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed scanning anquette: %w", err)
+		}
+		anquettes = append(anquettes, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: failed iterating anquettes: %w", err)
+	}
+	return anquettes, nil
+}
+
+// SetAnquetteCity - сохраняет каноническое название и ID города анкеты
+func (s *Storage) SetAnquetteCity(ctx context.Context, id int, city, cityID string) error {
+	res, err := s.db.ExecContext(ctx, "UPDATE anquettes SET city = ?, city_id = ? WHERE id = ?", city, cityID, id)
+	if err != nil {
+		return fmt.Errorf("repository: failed to set anquette city: %w", err)
+	}
+
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"bot-api/internal/domain"
+)
+
+// earthRadiusKm - средний радиус Земли
+const earthRadiusKm = 6371.0
+
+// distanceKmExpr - расстояние от точки (lat, lat, lon) до анкеты по дуге
+// большого круга, округленное до целых километров и не меньше 1.
+// Для анкеты без координат - NULL.
+const distanceKmExpr = `MAX(1, CAST(ROUND(6371.0 * ACOS(MIN(1.0, MAX(-1.0,
+        SIN(RADIANS(?)) * SIN(RADIANS(lat)) + COS(RADIANS(?)) * COS(RADIANS(lat)) * COS(RADIANS(lon - ?))
+    )))) AS INTEGER))`
+
+// feedStatsColumns - данные о кандидате f для ранжирования (domain.FeedStats)
+const feedStatsColumns = `(SELECT COUNT(*) FROM photos p WHERE p.anquette_id = f.id) AS photo_count,
+    (SELECT MAX(unixepoch(u.last_active_at)) FROM users u WHERE u.anquette_id = f.id) AS last_active,
+    (SELECT COUNT(*) FROM reactions r JOIN users u ON u.tg_id = r.from_tg_id
+        WHERE u.anquette_id = f.id AND r.kind = '` + domain.ReactionLike + `') AS likes_given,
+    (SELECT COUNT(*) FROM reactions r JOIN users u ON u.tg_id = r.from_tg_id WHERE u.anquette_id = f.id) AS reactions_given`
+
+// boundingBox - условия на lat/lon, отсекающие по индексу анкеты дальше km от p.
+// Рядом с полюсами и через 180-й меридиан долгота не ограничивается.
+func boundingBox(p domain.GeoPoint, km int) ([]string, []interface{}) {
+	dLat := float64(km) / earthRadiusKm * 180 / math.Pi
+	conds := []string{"lat BETWEEN ? AND ?"}
+	args := []interface{}{p.Lat - dLat, p.Lat + dLat}
+
+	cosLat := math.Cos(p.Lat * math.Pi / 180)
+	if cosLat < 0.01 {
+		return conds, args
+	}
+	dLon := dLat / cosLat
+	if p.Lon-dLon < -180 || p.Lon+dLon > 180 {
+		return conds, args
+	}
+	return append(conds, "lon BETWEEN ? AND ?"), append(args, p.Lon-dLon, p.Lon+dLon)
+}
+
+// --- Методы Feed ---
+
+// notBlockedCond - условие на анкету (anquettes.id), отсекающее анкеты
+// пользователей, с которыми у зрителя есть блокировка в любую сторону;
+// принимает tg_id зрителя дважды. NOT EXISTS, а не NOT IN: у пользователя
+// без анкеты anquette_id равен NULL, а NOT IN с NULL в подзапросе
+// не пропускает ни одной строки.
+const notBlockedCond = `NOT EXISTS (SELECT 1 FROM users u JOIN blocks b
+                ON (b.blocker_tg_id = ? AND b.blocked_tg_id = u.tg_id) OR (b.blocked_tg_id = ? AND b.blocker_tg_id = u.tg_id)
+                WHERE u.anquette_id = anquettes.id)`
+
+// GetFeed - выбирает следующую пачку активных анкет-кандидатов по фильтру.
+// Анкеты отдаются по возрастанию ID, начиная после f.AfterID, а с
+// FeedSortDistance - по возрастанию расстояния, после (f.AfterDistanceKm, f.AfterID).
+// С FeedSortScore курсор не используется: выбираются кандидаты, которые были
+// активны позже всех, а окончательный порядок задает ранжирование в сервисе.
+// Анкеты, на которые зритель уже отреагировал, и анкеты пользователей,
+// с которыми у зрителя есть блокировка в любую сторону, пропускаются.
+// Расстояние считается, только если задан f.Origin; с MaxDistanceKm или
+// FeedSortDistance анкеты без координат в выдачу не попадают.
+func (s *Storage) GetFeed(ctx context.Context, f domain.FeedFilter) ([]domain.FeedItem, error) {
+	byDistance := f.Origin != nil && f.Sort == domain.FeedSortDistance
+	byScore := f.Sort == domain.FeedSortScore
+	conds := []string{"status = ?"}
+	args := []interface{}{domain.AnquetteStatusActive}
+
+	if !byDistance && !byScore {
+		conds = append(conds, "id > ?")
+		args = append(args, f.AfterID)
+	}
+	if f.ExcludeAnquetteID != 0 {
+		conds = append(conds, "id != ?")
+		args = append(args, f.ExcludeAnquetteID)
+	}
+	if f.ViewerTgID != 0 {
+		conds = append(conds,
+			"id NOT IN (SELECT to_anquette_id FROM reactions WHERE from_tg_id = ?)",
+			notBlockedCond,
+		)
+		args = append(args, f.ViewerTgID, f.ViewerTgID, f.ViewerTgID)
+	}
+	if f.Gender != "" {
+		conds = append(conds, "gender = ? COLLATE NOCASE")
+		args = append(args, f.Gender)
+	}
+	if f.CityID != "" {
+		conds = append(conds, "city_id = ?")
+		args = append(args, f.CityID)
+	} else if f.City != "" {
+		conds = append(conds, "city = ? COLLATE NOCASE")
+		args = append(args, f.City)
+	}
+	if len(f.Interests) > 0 {
+		conds = append(conds, "id IN (SELECT anquette_id FROM anquette_interests WHERE interest_id IN (?"+
+			strings.Repeat(", ?", len(f.Interests)-1)+"))")
+		for _, id := range f.Interests {
+			args = append(args, id)
+		}
+	}
+	if f.Goal != "" {
+		conds = append(conds, "goal = ?")
+		args = append(args, f.Goal)
+	}
+	if f.ViewerGender != "" {
+		conds = append(conds, "(pref_genders = '' OR instr(pref_genders, ?) > 0)")
+		args = append(args, f.ViewerGender)
+	}
+	if f.ViewerAge > 0 {
+		conds = append(conds, "pref_age_min <= ?", "(pref_age_max = 0 OR pref_age_max >= ?)")
+		args = append(args, f.ViewerAge, f.ViewerAge)
+	}
+	if f.WithPhotos {
+		conds = append(conds, "id IN (SELECT anquette_id FROM photos)")
+	}
+	if f.AgeMin > 0 {
+		conds = append(conds, "age >= ?")
+		args = append(args, f.AgeMin)
+	}
+	if f.AgeMax > 0 {
+		conds = append(conds, "age <= ?")
+		args = append(args, f.AgeMax)
+	}
+
+	distance := "NULL"
+	var distanceArgs []interface{}
+	outer := []string{"1"}
+	var outerArgs []interface{}
+	order := "id"
+	if byScore {
+		order = "last_active DESC NULLS LAST, id DESC"
+	}
+	if f.Origin != nil {
+		distance = distanceKmExpr
+		distanceArgs = []interface{}{f.Origin.Lat, f.Origin.Lat, f.Origin.Lon}
+		if f.MaxDistanceKm > 0 || byDistance {
+			conds = append(conds, "lat IS NOT NULL", "lon IS NOT NULL")
+		}
+		if f.MaxDistanceKm > 0 {
+			// Сравнивается округленное расстояние, поэтому рамка на километр шире
+			boxConds, boxArgs := boundingBox(*f.Origin, f.MaxDistanceKm+1)
+			conds = append(conds, boxConds...)
+			args = append(args, boxArgs...)
+			outer = append(outer, "distance_km <= ?")
+			outerArgs = append(outerArgs, f.MaxDistanceKm)
+		}
+		if byDistance {
+			outer = append(outer, "(distance_km > ? OR (distance_km = ? AND id > ?))")
+			outerArgs = append(outerArgs, f.AfterDistanceKm, f.AfterDistanceKm, f.AfterID)
+			order = "distance_km, id"
+		}
+	}
+
+	query := "SELECT f.*, " + feedStatsColumns + " FROM (SELECT " + anquetteColumns + ", " + distance + " AS distance_km FROM anquettes WHERE " +
+		strings.Join(conds, " AND ") + ") f WHERE " + strings.Join(outer, " AND ") + " ORDER BY " + order + " LIMIT ?"
+	args = append(append(append(distanceArgs, args...), outerArgs...), f.Limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query feed: %w", err)
+	}
+	defer rows.Close()
+
+	feed := []domain.FeedItem{}
+	for rows.Next() {
+		var (
+			item         domain.FeedItem
+			dist, active sql.NullInt64
+		)
+		item.Anquette, err = scanAnquette(rows, &dist,
+			&item.Stats.PhotoCount, &active, &item.Stats.LikesGiven, &item.Stats.ReactionsGiven)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed scanning feed anquette: %w", err)
+		}
+		item.DistanceKm = int(dist.Int64)
+		if active.Valid {
+			t := time.Unix(active.Int64, 0).UTC()
+			item.Stats.LastActiveAt = &t
+		}
+		feed = append(feed, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: failed iterating feed: %w", err)
+	}
+	return feed, nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"bot-api/internal/domain"
+)
+
+// --- Методы Interest ---
+
+// ListInterests - справочник интересов в порядке вывода
+func (s *Storage) ListInterests(ctx context.Context) ([]domain.Interest, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT id, category, name, name_en FROM interests ORDER BY position, id")
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query interests: %w", err)
+	}
+	defer rows.Close()
+
+	interests := []domain.Interest{}
+	for rows.Next() {
+		var i domain.Interest
+		if err := rows.Scan(&i.ID, &i.Category, &i.Name, &i.NameEn); err != nil {
+			return nil, fmt.Errorf("repository: failed scanning interest: %w", err)
+		}
+		interests = append(interests, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: failed iterating interests: %w", err)
+	}
+	return interests, nil
+}
+
+// SetAnquetteInterests - заменяет интересы анкеты; ID должны быть из справочника
+func (s *Storage) SetAnquetteInterests(ctx context.Context, id int, interestIDs []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repository: failed to begin set interests: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRowContext(ctx, "SELECT 1 FROM anquettes WHERE id = ?", id).Scan(&exists); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sql.ErrNoRows
+		}
+		return fmt.Errorf("repository: failed to check anquette: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM anquette_interests WHERE anquette_id = ?", id); err != nil {
+		return fmt.Errorf("repository: failed to clear anquette interests: %w", err)
+	}
+	for _, interestID := range interestIDs {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO anquette_interests (anquette_id, interest_id) VALUES (?, ?)", id, interestID); err != nil {
+			return fmt.Errorf("repository: failed to insert anquette interest: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repository: failed to commit set interests: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"bot-api/internal/domain"
+)
+
+// ErrInvalidCursor - курсор списка поврежден или выдан для другой сортировки
+var ErrInvalidCursor = errors.New("repository: invalid list cursor")
+
+// listTimeLayout - формат CURRENT_TIMESTAMP, в котором хранится created_at
+const listTimeLayout = "2006-01-02 15:04:05"
+
+// listSort - выражение ключа сортировки; пустое - порядок по ID записи
+type listSort struct {
+	expr string
+}
+
+var (
+	userSorts = map[string]listSort{
+		domain.ListSortCreated:    {},
+		domain.ListSortLastActive: {expr: "COALESCE(last_active_at, '')"},
+	}
+	anquetteSorts = map[string]listSort{
+		domain.ListSortCreated: {},
+		domain.ListSortAge:     {expr: "age"},
+	}
+)
+
+// listCursor - позиция в списке: ключ сортировки и ID последней записи страницы
+type listCursor struct {
+	Sort  string `json:"s"`
+	Value any    `json:"v,omitempty"`
+	ID    int64  `json:"id"`
+}
+
+func encodeCursor(c listCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s, sort string) (listCursor, error) {
+	var c listCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || json.Unmarshal(b, &c) != nil || c.Sort != sort {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// listQuery - условия WHERE списка и их аргументы
+type listQuery struct {
+	conds []string
+	args  []any
+}
+
+func (q *listQuery) add(cond string, args ...any) {
+	q.conds = append(q.conds, cond)
+	q.args = append(q.args, args...)
+}
+
+// anquetteFilter - условия ListFilter на колонки anquettes
+func anquetteFilter(f domain.ListFilter) listQuery {
+	var q listQuery
+	if f.CityID != "" {
+		q.add("city_id = ?", f.CityID)
+	} else if f.City != "" {
+		q.add("city = ? COLLATE NOCASE", f.City)
+	}
+	if f.Gender != "" {
+		q.add("gender = ?", f.Gender)
+	}
+	if f.AgeMin > 0 {
+		q.add("age >= ?", f.AgeMin)
+	}
+	if f.AgeMax > 0 {
+		q.add("age <= ?", f.AgeMax)
+	}
+	return q
+}
+
+// page - добавляет к q условие курсора и возвращает "WHERE ... ORDER BY ... LIMIT ?"
+// (на одну запись больше f.Limit, чтобы понять, есть ли следующая страница).
+// Сортировка по ключу f.Sort (с "-" - по убыванию), затем по idCol, чтобы порядок был однозначным.
+func (q *listQuery) page(f domain.ListFilter, sorts map[string]listSort, idCol string) (string, listSort, error) {
+	key := strings.TrimPrefix(f.Sort, "-")
+	desc := strings.HasPrefix(f.Sort, "-")
+	srt, ok := sorts[key]
+	if !ok {
+		return "", srt, fmt.Errorf("repository: unknown list sort %q", f.Sort)
+	}
+
+	cmp, dir := ">", "ASC"
+	if desc {
+		cmp, dir = "<", "DESC"
+	}
+	if f.Cursor != "" {
+		c, err := decodeCursor(f.Cursor, f.Sort)
+		if err != nil {
+			return "", srt, err
+		}
+		if srt.expr == "" {
+			q.add(idCol+" "+cmp+" ?", c.ID)
+		} else {
+			q.add(fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND %[3]s %[2]s ?))", srt.expr, cmp, idCol), c.Value, c.Value, c.ID)
+		}
+	}
+
+	where := "1"
+	if len(q.conds) > 0 {
+		where = strings.Join(q.conds, " AND ")
+	}
+	order := idCol + " " + dir
+	if srt.expr != "" {
+		order = srt.expr + " " + dir + ", " + order
+	}
+	q.args = append(q.args, f.Limit+1)
+	return " WHERE " + where + " ORDER BY " + order + " LIMIT ?", srt, nil
+}
+
+// sortValue - выражение ключа сортировки для SELECT (NULL, если сортировка по ID)
+func (s listSort) sortValue() string {
+	if s.expr == "" {
+		return "NULL"
+	}
+	return s.expr
+}
+
+// --- Методы списков (админка, аналитика) ---
+
+// ListUsers - страница пользователей по ListFilter. Город, пол и возраст
+// проверяются по анкете пользователя; пользователи без анкеты под них не подходят.
+func (s *Storage) ListUsers(ctx context.Context, f domain.ListFilter) (domain.Page[domain.User], error) {
+	page := domain.Page[domain.User]{Items: []domain.User{}}
+
+	q := listQuery{}
+	if aq := anquetteFilter(f); len(aq.conds) > 0 {
+		q.add("anquette_id IN (SELECT id FROM anquettes WHERE "+strings.Join(aq.conds, " AND ")+")", aq.args...)
+	}
+	if f.CreatedAfter != nil {
+		q.add("created_at > ?", f.CreatedAfter.UTC().Format(listTimeLayout))
+	}
+	tail, srt, err := q.page(f, userSorts, "rowid")
+	if err != nil {
+		return page, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT "+userColumns+", "+srt.sortValue()+", rowid FROM users"+tail, q.args...)
+	if err != nil {
+		return page, fmt.Errorf("repository: failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	var last listCursor
+	for rows.Next() {
+		var c listCursor
+		u, err := scanUser(rows, &c.Value, &c.ID)
+		if err != nil {
+			return page, fmt.Errorf("repository: failed scanning user: %w", err)
+		}
+		if len(page.Items) == f.Limit {
+			page.NextCursor = encodeCursor(last)
+			break
+		}
+		c.Sort = f.Sort
+		last = c
+		page.Items = append(page.Items, u)
+	}
+	if err := rows.Err(); err != nil {
+		return page, fmt.Errorf("repository: failed iterating users: %w", err)
+	}
+	return page, nil
+}
+
+// ListAnquettes - страница анкет любого статуса по ListFilter
+func (s *Storage) ListAnquettes(ctx context.Context, f domain.ListFilter) (domain.Page[domain.Anquette], error) {
+	page := domain.Page[domain.Anquette]{Items: []domain.Anquette{}}
+
+	q := anquetteFilter(f)
+	if f.CreatedAfter != nil {
+		q.add("created_at > ?", f.CreatedAfter.UTC().Format(listTimeLayout))
+	}
+	tail, srt, err := q.page(f, anquetteSorts, "id")
+	if err != nil {
+		return page, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT "+anquetteColumns+", "+srt.sortValue()+" FROM anquettes"+tail, q.args...)
+	if err != nil {
+		return page, fmt.Errorf("repository: failed to query anquettes: %w", err)
+	}
+	defer rows.Close()
+
+	var last listCursor
+	for rows.Next() {
+		var c listCursor
+		a, err := scanAnquette(rows, &c.Value)
+		if err != nil {
+			return page, fmt.Errorf("repository: failed scanning anquette: %w", err)
+		}
+		if len(page.Items) == f.Limit {
+			page.NextCursor = encodeCursor(last)
+			break
+		}
+		c.Sort, c.ID = f.Sort, int64(a.ID)
+		last = c
+		page.Items = append(page.Items, a)
+	}
+	if err := rows.Err(); err != nil {
+		return page, fmt.Errorf("repository: failed iterating anquettes: %w", err)
+	}
+	return page, nil
+}
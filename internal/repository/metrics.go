@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"bot-api/internal/domain"
+	"bot-api/internal/metrics"
+)
+
+// RegisterMetrics - добавляет в reg состояние пула соединений database/sql
+// и показатели сервиса: пользователи, активные анкеты, мэтчи за сутки.
+// Показатели считаются запросами к БД при каждом сборе метрик.
+func (s *Storage) RegisterMetrics(reg *metrics.Registry) {
+	pool := func(fn func(st sql.DBStats) float64) func(context.Context) (float64, error) {
+		return func(context.Context) (float64, error) { return fn(s.db.Stats()), nil }
+	}
+	reg.GaugeFunc("db_max_open_connections", "Maximum number of open connections to the database.",
+		pool(func(st sql.DBStats) float64 { return float64(st.MaxOpenConnections) }))
+	reg.GaugeFunc("db_open_connections", "Established connections, both in use and idle.",
+		pool(func(st sql.DBStats) float64 { return float64(st.OpenConnections) }))
+	reg.GaugeFunc("db_in_use_connections", "Connections currently in use.",
+		pool(func(st sql.DBStats) float64 { return float64(st.InUse) }))
+	reg.GaugeFunc("db_idle_connections", "Idle connections.",
+		pool(func(st sql.DBStats) float64 { return float64(st.Idle) }))
+	reg.CounterFunc("db_wait_count_total", "Connections waited for.",
+		pool(func(st sql.DBStats) float64 { return float64(st.WaitCount) }))
+	reg.CounterFunc("db_wait_duration_seconds_total", "Time blocked waiting for a new connection.",
+		pool(func(st sql.DBStats) float64 { return st.WaitDuration.Seconds() }))
+	reg.CounterFunc("db_max_idle_closed_total", "Connections closed due to SetMaxIdleConns.",
+		pool(func(st sql.DBStats) float64 { return float64(st.MaxIdleClosed) }))
+	reg.CounterFunc("db_max_idle_time_closed_total", "Connections closed due to SetConnMaxIdleTime.",
+		pool(func(st sql.DBStats) float64 { return float64(st.MaxIdleTimeClosed) }))
+	reg.CounterFunc("db_max_lifetime_closed_total", "Connections closed due to SetConnMaxLifetime.",
+		pool(func(st sql.DBStats) float64 { return float64(st.MaxLifetimeClosed) }))
+
+	reg.GaugeFunc("dating_users", "Registered users.",
+		s.count("users", "SELECT COUNT(*) FROM users"))
+	reg.GaugeFunc("dating_anquettes_active", "Anquettes shown in the feed.",
+		s.count("active anquettes", "SELECT COUNT(*) FROM anquettes WHERE status = ?", domain.AnquetteStatusActive))
+	reg.GaugeFunc("dating_matches_last_24h", "Matches created in the last 24 hours.",
+		s.count("matches", "SELECT COUNT(*) FROM matches WHERE created_at >= datetime('now', '-1 day')"))
+}
+
+// count - показатель из запроса query, возвращающего одно число
+func (s *Storage) count(what, query string, args ...any) func(context.Context) (float64, error) {
+	return func(ctx context.Context) (float64, error) {
+		var n int64
+		if err := s.db.QueryRowContext(ctx, query, args...).Scan(&n); err != nil {
+			return 0, fmt.Errorf("repository: failed to count %s: %w", what, err)
+		}
+		return float64(n), nil
+	}
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Файлы миграций именуются как NNNN_описание.sql и применяются по возрастанию номера
+//
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// ErrSchemaTooNew - в БД применены миграции, о которых не знает этот бинарник
+var ErrSchemaTooNew = errors.New("repository: database schema is newer than the binary")
+
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// loadMigrations - читает встроенные миграции и сортирует их по версии
+func loadMigrations() ([]migration, error) {
+	files, err := fs.Glob(migrationsFS, "migrations/*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to list migrations: %w", err)
+	}
+
+	migrations := make([]migration, 0, len(files))
+	seen := make(map[int]string)
+	for _, file := range files {
+		base := strings.TrimSuffix(strings.TrimPrefix(file, "migrations/"), ".sql")
+		num, name, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(num)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("repository: bad migration file name %q", file)
+		}
+		if prev, dup := seen[version]; dup {
+			return nil, fmt.Errorf("repository: migrations %q and %q share version %d", prev, file, version)
+		}
+		seen[version] = file
+
+		body, err := migrationsFS.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed to read migration %q: %w", file, err)
+		}
+		migrations = append(migrations, migration{version: version, name: name, sql: string(body)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	return migrations, nil
+}
+
+// Migrate - применяет недостающие миграции по порядку, каждую в своей транзакции.
+// Примененные версии записываются в schema_migrations. Если в БД есть версия
+// новее последней встроенной миграции, возвращается ErrSchemaTooNew.
+func (s *Storage) Migrate(ctx context.Context) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);`)
+	if err != nil {
+		return fmt.Errorf("repository: failed to create schema_migrations table: %w", err)
+	}
+
+	current, err := s.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	latest := latestVersion(migrations)
+	if current > latest {
+		return fmt.Errorf("%w: database is at version %d, binary knows up to %d", ErrSchemaTooNew, current, latest)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := s.applyMigration(ctx, m); err != nil {
+			return err
+		}
+		slog.InfoContext(ctx, "Применена миграция", "version", m.version, "name", m.name)
+	}
+
+	slog.InfoContext(ctx, "Схема БД актуальна", "version", latest)
+	return nil
+}
+
+// SchemaVersion - версия последней примененной миграции (0 - миграций еще не было)
+func (s *Storage) SchemaVersion(ctx context.Context) (int, error) {
+	var version int
+	err := s.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("repository: failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// LatestSchemaVersion - версия последней встроенной миграции
+func LatestSchemaVersion() (int, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return 0, err
+	}
+	return latestVersion(migrations), nil
+}
+
+func latestVersion(migrations []migration) int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].version
+}
+
+func (s *Storage) applyMigration(ctx context.Context, m migration) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repository: failed to begin migration %d: %w", m.version, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.sql); err != nil {
+		return fmt.Errorf("repository: migration %04d_%s failed: %w", m.version, m.name, err)
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES (?, ?)", m.version, m.name); err != nil {
+		return fmt.Errorf("repository: failed to record migration %d: %w", m.version, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repository: failed to commit migration %d: %w", m.version, err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+-- Исходная схема. IF NOT EXISTS оставлен, чтобы принять базы,
+-- созданные до появления миграций через CreateTables.
+
+CREATE TABLE IF NOT EXISTS users (
+	tg_id INTEGER NOT NULL UNIQUE,
+	tg_username TEXT,
+	anquette_id INTEGER
+);
+
+CREATE TABLE IF NOT EXISTS anquettes (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	age INTEGER NOT NULL,
+	city TEXT,
+	gender TEXT,
+	preferences TEXT,
+	description TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS reactions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	from_tg_id INTEGER NOT NULL,
+	to_anquette_id INTEGER NOT NULL,
+	kind TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (from_tg_id, to_anquette_id)
+);
+
+CREATE TABLE IF NOT EXISTS matches (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user1_tg_id INTEGER NOT NULL,
+	user2_tg_id INTEGER NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (user1_tg_id, user2_tg_id),
+	CHECK (user1_tg_id < user2_tg_id)
+);
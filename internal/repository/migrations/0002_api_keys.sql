@@ -0,0 +1,9 @@
+-- Ключи API для ботов и админки. Хранится только SHA-256 от ключа.
+CREATE TABLE api_keys (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE,
+	key_hash TEXT NOT NULL UNIQUE,
+	scopes TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	revoked_at TIMESTAMP
+);
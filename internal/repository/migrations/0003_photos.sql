@@ -0,0 +1,12 @@
+-- Фото анкет. Сами файлы лежат в BlobStore под storage_key.
+CREATE TABLE photos (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	anquette_id INTEGER NOT NULL,
+	position INTEGER NOT NULL,
+	storage_key TEXT NOT NULL UNIQUE,
+	content_type TEXT NOT NULL,
+	size INTEGER NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_photos_anquette ON photos (anquette_id, position);
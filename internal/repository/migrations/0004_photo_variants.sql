@@ -0,0 +1,15 @@
+-- Размеры очищенного от EXIF оригинала и его уменьшенные копии.
+-- width = 0 у фото, загруженных до появления обработки.
+ALTER TABLE photos ADD COLUMN width INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE photos ADD COLUMN height INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE photo_variants (
+	photo_id INTEGER NOT NULL,
+	variant TEXT NOT NULL,
+	storage_key TEXT NOT NULL UNIQUE,
+	content_type TEXT NOT NULL,
+	size INTEGER NOT NULL,
+	width INTEGER NOT NULL,
+	height INTEGER NOT NULL,
+	PRIMARY KEY (photo_id, variant)
+);
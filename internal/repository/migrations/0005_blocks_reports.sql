@@ -0,0 +1,23 @@
+-- Блокировки действуют в обе стороны: пара не видит друг друга в ленте и мэтчах.
+CREATE TABLE blocks (
+	blocker_tg_id INTEGER NOT NULL,
+	blocked_tg_id INTEGER NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (blocker_tg_id, blocked_tg_id)
+);
+
+CREATE INDEX idx_blocks_blocked ON blocks (blocked_tg_id);
+
+-- Жалобы попадают в очередь модерации со статусом pending.
+CREATE TABLE reports (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	reporter_tg_id INTEGER NOT NULL,
+	reported_tg_id INTEGER NOT NULL,
+	reported_anquette_id INTEGER NOT NULL DEFAULT 0,
+	reason TEXT NOT NULL,
+	note TEXT NOT NULL DEFAULT '',
+	status TEXT NOT NULL DEFAULT 'pending',
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_reports_status ON reports (status, id);
@@ -0,0 +1,19 @@
+-- Статус анкеты: в ленте показываются только active.
+ALTER TABLE anquettes ADD COLUMN status TEXT NOT NULL DEFAULT 'active';
+
+CREATE INDEX idx_anquettes_status ON anquettes (status, id);
+
+-- Забаненный пользователь не может войти через Telegram, его анкета скрыта.
+ALTER TABLE users ADD COLUMN banned_at TIMESTAMP;
+
+-- Журнал решений модераторов.
+CREATE TABLE moderation_decisions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	action TEXT NOT NULL,
+	report_id INTEGER NOT NULL DEFAULT 0,
+	anquette_id INTEGER NOT NULL DEFAULT 0,
+	tg_id INTEGER NOT NULL DEFAULT 0,
+	reason TEXT NOT NULL DEFAULT '',
+	moderator TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
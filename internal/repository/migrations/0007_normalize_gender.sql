@@ -0,0 +1,7 @@
+-- Пол анкеты теперь хранится как m или f. lower() в SQLite понимает только
+-- латиницу, поэтому кириллические варианты перечислены в обоих регистрах.
+UPDATE anquettes SET gender = 'm'
+WHERE lower(trim(gender)) IN ('m', 'male') OR trim(gender) IN ('м', 'М', 'муж', 'Муж', 'мужской', 'Мужской');
+
+UPDATE anquettes SET gender = 'f'
+WHERE lower(trim(gender)) IN ('f', 'female') OR trim(gender) IN ('ж', 'Ж', 'жен', 'Жен', 'женский', 'Женский');
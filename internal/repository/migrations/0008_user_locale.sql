@@ -0,0 +1,2 @@
+-- Язык сообщений API для пользователя; пустая строка - по Accept-Language.
+ALTER TABLE users ADD COLUMN locale TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+-- Координаты анкеты (градусы WGS 84); NULL - пользователь не делился геопозицией.
+ALTER TABLE anquettes ADD COLUMN lat REAL;
+ALTER TABLE anquettes ADD COLUMN lon REAL;
+
+CREATE INDEX IF NOT EXISTS idx_anquettes_lat_lon ON anquettes(lat, lon);
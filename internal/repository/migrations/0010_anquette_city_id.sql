@@ -0,0 +1,5 @@
+-- Город анкеты из справочника (gazetteer); пусто, если название не распознано.
+-- Старые анкеты заполняются при запуске сервиса (NormalizeCities).
+ALTER TABLE anquettes ADD COLUMN city_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_anquettes_city_id ON anquettes(city_id);
@@ -0,0 +1,5 @@
+-- Когда пользователь последний раз смотрел ленту или ставил реакцию.
+-- Используется при ранжировании ленты.
+ALTER TABLE users ADD COLUMN last_active_at TIMESTAMP;
+
+CREATE INDEX idx_users_anquette ON users (anquette_id);
@@ -0,0 +1,67 @@
+-- Справочник интересов. Список ведется вручную: новые интересы добавляются
+-- миграциями, ID не меняются. position - порядок вывода в каталоге.
+CREATE TABLE interests (
+	id TEXT PRIMARY KEY,
+	category TEXT NOT NULL,
+	name TEXT NOT NULL,
+	name_en TEXT NOT NULL,
+	position INTEGER NOT NULL
+);
+
+-- Интересы анкет (многие ко многим)
+CREATE TABLE anquette_interests (
+	anquette_id INTEGER NOT NULL,
+	interest_id TEXT NOT NULL REFERENCES interests (id),
+	PRIMARY KEY (anquette_id, interest_id)
+);
+
+CREATE INDEX idx_anquette_interests_interest ON anquette_interests (interest_id, anquette_id);
+
+INSERT INTO interests (id, category, name, name_en, position) VALUES
+	('music', 'culture', 'Музыка', 'Music', 1),
+	('concerts', 'culture', 'Концерты', 'Concerts', 2),
+	('movies', 'culture', 'Кино', 'Movies', 3),
+	('series', 'culture', 'Сериалы', 'TV series', 4),
+	('books', 'culture', 'Книги', 'Books', 5),
+	('theatre', 'culture', 'Театр', 'Theatre', 6),
+	('art', 'culture', 'Искусство', 'Art', 7),
+	('museums', 'culture', 'Музеи', 'Museums', 8),
+	('photography', 'culture', 'Фотография', 'Photography', 9),
+	('anime', 'culture', 'Аниме', 'Anime', 10),
+
+	('sport', 'sport', 'Спорт', 'Sport', 20),
+	('gym', 'sport', 'Тренажерный зал', 'Gym', 21),
+	('running', 'sport', 'Бег', 'Running', 22),
+	('yoga', 'sport', 'Йога', 'Yoga', 23),
+	('football', 'sport', 'Футбол', 'Football', 24),
+	('cycling', 'sport', 'Велосипед', 'Cycling', 25),
+	('swimming', 'sport', 'Плавание', 'Swimming', 26),
+	('skiing', 'sport', 'Лыжи и сноуборд', 'Skiing and snowboarding', 27),
+	('martial-arts', 'sport', 'Единоборства', 'Martial arts', 28),
+	('dancing', 'sport', 'Танцы', 'Dancing', 29),
+
+	('it', 'tech', 'IT', 'IT', 40),
+	('programming', 'tech', 'Программирование', 'Programming', 41),
+	('gaming', 'tech', 'Видеоигры', 'Video games', 42),
+	('science', 'tech', 'Наука', 'Science', 43),
+	('cars', 'tech', 'Автомобили', 'Cars', 44),
+
+	('travel', 'lifestyle', 'Путешествия', 'Travel', 60),
+	('hiking', 'lifestyle', 'Походы', 'Hiking', 61),
+	('nature', 'lifestyle', 'Природа', 'Nature', 62),
+	('cooking', 'lifestyle', 'Кулинария', 'Cooking', 63),
+	('coffee', 'lifestyle', 'Кофе', 'Coffee', 64),
+	('wine', 'lifestyle', 'Вино', 'Wine', 65),
+	('restaurants', 'lifestyle', 'Рестораны', 'Restaurants', 66),
+	('pets', 'lifestyle', 'Домашние животные', 'Pets', 67),
+	('fashion', 'lifestyle', 'Мода', 'Fashion', 68),
+	('volunteering', 'lifestyle', 'Волонтерство', 'Volunteering', 69),
+
+	('board-games', 'hobby', 'Настольные игры', 'Board games', 80),
+	('drawing', 'hobby', 'Рисование', 'Drawing', 81),
+	('crafts', 'hobby', 'Рукоделие', 'Crafts', 82),
+	('gardening', 'hobby', 'Садоводство', 'Gardening', 83),
+	('fishing', 'hobby', 'Рыбалка', 'Fishing', 84),
+	('languages', 'hobby', 'Иностранные языки', 'Languages', 85),
+	('psychology', 'hobby', 'Психология', 'Psychology', 86),
+	('business', 'hobby', 'Бизнес', 'Business', 87);
@@ -0,0 +1,82 @@
+-- Предпочтения анкеты вместо свободного текста. Исходный текст остается в
+-- preferences_text, распознанное из него переносится в новые колонки.
+-- Списки (pref_genders, dealbreakers) хранятся через запятую; 0 и '' - без ограничения.
+ALTER TABLE anquettes RENAME COLUMN preferences TO preferences_text;
+ALTER TABLE anquettes ADD COLUMN pref_genders TEXT NOT NULL DEFAULT '';
+ALTER TABLE anquettes ADD COLUMN pref_age_min INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE anquettes ADD COLUMN pref_age_max INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE anquettes ADD COLUMN pref_max_distance_km INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE anquettes ADD COLUMN goal TEXT NOT NULL DEFAULT '';
+ALTER TABLE anquettes ADD COLUMN dealbreakers TEXT NOT NULL DEFAULT '';
+
+-- Пол: короткие ответы целиком (как в 0007) и слова внутри фразы.
+-- lower() и LIKE в SQLite не различают регистр только у латиницы,
+-- поэтому кириллица перечислена в обоих регистрах.
+-- "парн" встречается внутри других слов («напарника»), поэтому формы слова
+-- "парень" ищутся целиком: в pref_words знаки препинания заменены пробелами
+-- и текст обрамлен пробелами, так что слово - это '% слово %'.
+CREATE TEMP TABLE pref_words AS
+SELECT id, ' ' || replace(replace(replace(replace(replace(replace(replace(replace(replace(
+	preferences_text, ',', ' '), '.', ' '), '!', ' '), '?', ' '), ';', ' '), ':', ' '), '(', ' '), ')', ' '), char(10), ' ') || ' ' AS t
+FROM anquettes WHERE preferences_text IS NOT NULL;
+
+UPDATE anquettes SET pref_genders = 'f'
+WHERE lower(trim(preferences_text)) IN ('f', 'female', 'woman', 'women', 'girls')
+   OR trim(preferences_text) IN ('ж', 'Ж', 'жен', 'Жен', 'женский', 'Женский')
+   OR preferences_text LIKE '%девуш%' OR preferences_text LIKE '%Девуш%'
+   OR preferences_text LIKE '%женщин%' OR preferences_text LIKE '%Женщин%';
+
+UPDATE anquettes SET pref_genders = CASE WHEN pref_genders = 'f' THEN 'f,m' ELSE 'm' END
+WHERE lower(trim(preferences_text)) IN ('m', 'male', 'man', 'men', 'guys')
+   OR trim(preferences_text) IN ('м', 'М', 'муж', 'Муж', 'мужской', 'Мужской')
+   OR preferences_text LIKE '%мужчин%' OR preferences_text LIKE '%Мужчин%'
+   OR id IN (SELECT id FROM pref_words WHERE
+	t LIKE '% парень %' OR t LIKE '% Парень %' OR t LIKE '% парня %' OR t LIKE '% Парня %'
+	OR t LIKE '% парню %' OR t LIKE '% Парню %' OR t LIKE '% парнем %' OR t LIKE '% Парнем %'
+	OR t LIKE '% парне %' OR t LIKE '% Парне %' OR t LIKE '% парни %' OR t LIKE '% Парни %'
+	OR t LIKE '% парней %' OR t LIKE '% Парней %' OR t LIKE '% парням %' OR t LIKE '% Парням %'
+	OR t LIKE '% парнями %' OR t LIKE '% Парнями %' OR t LIKE '% парнях %' OR t LIKE '% Парнях %');
+
+DROP TABLE pref_words;
+
+-- Цель знакомства: если подходит несколько, берется первая по порядку
+UPDATE anquettes SET goal = 'study_buddy'
+WHERE preferences_text LIKE '%учеб%' OR preferences_text LIKE '%Учеб%'
+   OR preferences_text LIKE '%учёб%' OR preferences_text LIKE '%Учёб%'
+   OR preferences_text LIKE '%study%';
+
+UPDATE anquettes SET goal = 'friendship'
+WHERE goal = '' AND (
+	preferences_text LIKE '%друж%' OR preferences_text LIKE '%Друж%'
+	OR preferences_text LIKE '%друз%' OR preferences_text LIKE '%Друз%'
+	OR preferences_text LIKE '%friend%');
+
+UPDATE anquettes SET goal = 'dating'
+WHERE goal = '' AND (
+	preferences_text LIKE '%отношени%' OR preferences_text LIKE '%Отношени%'
+	OR preferences_text LIKE '%свидан%' OR preferences_text LIKE '%Свидан%'
+	OR preferences_text LIKE '%любов%' OR preferences_text LIKE '%Любов%'
+	OR preferences_text LIKE '%семь%' OR preferences_text LIKE '%Семь%'
+	OR preferences_text LIKE '%dating%' OR preferences_text LIKE '%relationship%');
+
+-- Возраст: первый правдоподобный (18-99, от меньшего к большему) диапазон вида
+-- "20-25" в первых 200 символах. Пара не должна продолжать число или другой
+-- диапазон: иначе телефон "345-67-89" дал бы 45-67 или 67-89.
+WITH RECURSIVE pos(i) AS (
+	SELECT 1 UNION ALL SELECT i + 1 FROM pos WHERE i < 200
+), pairs AS (
+	SELECT a.id, pos.i,
+		CAST(substr(a.preferences_text, pos.i, 2) AS INTEGER) AS lo,
+		CAST(substr(a.preferences_text, pos.i + 3, 2) AS INTEGER) AS hi
+	FROM anquettes a JOIN pos ON substr(a.preferences_text, pos.i, 5) GLOB '[0-9][0-9]-[0-9][0-9]'
+	WHERE substr(a.preferences_text, pos.i - 1, 1) NOT GLOB '[-0-9]'
+	  AND substr(a.preferences_text, pos.i + 5, 1) NOT GLOB '[-0-9]'
+), ranges AS (
+	-- у SQLite остальные колонки берутся из строки с MIN(i)
+	SELECT id, lo, hi, MIN(i) FROM pairs
+	WHERE lo >= 18 AND hi >= lo
+	GROUP BY id
+)
+UPDATE anquettes SET pref_age_min = ranges.lo, pref_age_max = ranges.hi
+FROM ranges
+WHERE anquettes.id = ranges.id;
@@ -0,0 +1,12 @@
+-- Время создания пользователей и анкет для списков админки и аналитики.
+-- SQLite не добавляет колонку с DEFAULT CURRENT_TIMESTAMP, поэтому время
+-- выставляется при вставке; у записей, созданных раньше, оно неизвестно (NULL).
+ALTER TABLE users ADD COLUMN created_at TIMESTAMP;
+ALTER TABLE anquettes ADD COLUMN created_at TIMESTAMP;
+
+-- Индексы под фильтры и сортировки ListUsers и ListAnquettes
+CREATE INDEX idx_users_created_at ON users (created_at);
+CREATE INDEX idx_users_last_active ON users (last_active_at);
+CREATE INDEX idx_anquettes_created_at ON anquettes (created_at);
+CREATE INDEX idx_anquettes_age ON anquettes (age, id);
+CREATE INDEX idx_anquettes_gender_age ON anquettes (gender, age);
@@ -0,0 +1,33 @@
+-- Полнотекстовый индекс анкет (FTS5). rowid совпадает с anquettes.id.
+-- Текст хранится с "ё" -> "е" (см. search.Normalize): unicode61 не считает их
+-- одной буквой. Стемминга в индексе нет - основы слов строит запрос.
+CREATE VIRTUAL TABLE anquettes_fts USING fts5(
+	name,
+	description,
+	tokenize = 'unicode61 remove_diacritics 2'
+);
+
+INSERT INTO anquettes_fts (rowid, name, description)
+SELECT id,
+	replace(replace(name, 'ё', 'е'), 'Ё', 'Е'),
+	replace(replace(description, 'ё', 'е'), 'Ё', 'Е')
+FROM anquettes;
+
+-- Индекс обновляется триггерами при любом изменении анкет
+CREATE TRIGGER anquettes_fts_insert AFTER INSERT ON anquettes BEGIN
+	INSERT INTO anquettes_fts (rowid, name, description) VALUES (
+		new.id,
+		replace(replace(new.name, 'ё', 'е'), 'Ё', 'Е'),
+		replace(replace(new.description, 'ё', 'е'), 'Ё', 'Е'));
+END;
+
+CREATE TRIGGER anquettes_fts_update AFTER UPDATE OF name, description ON anquettes BEGIN
+	UPDATE anquettes_fts SET
+		name = replace(replace(new.name, 'ё', 'е'), 'Ё', 'Е'),
+		description = replace(replace(new.description, 'ё', 'е'), 'Ё', 'Е')
+	WHERE rowid = new.id;
+END;
+
+CREATE TRIGGER anquettes_fts_delete AFTER DELETE ON anquettes BEGIN
+	DELETE FROM anquettes_fts WHERE rowid = old.id;
+END;
@@ -0,0 +1,2 @@
+-- Индекс под метрику числа мэтчей за последние сутки
+CREATE INDEX idx_matches_created_at ON matches (created_at);
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"bot-api/internal/domain"
+)
+
+// --- Методы Moderation ---
+
+func (s *Storage) GetReport(ctx context.Context, id int) (domain.Report, error) {
+	row := s.db.QueryRowContext(ctx, "SELECT "+reportColumns+" FROM reports WHERE id = ?", id)
+	r, err := scanReport(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Report{}, sql.ErrNoRows
+		}
+		return domain.Report{}, fmt.Errorf("repository: failed scanning report: %w", err)
+	}
+	return r, nil
+}
+
+// ListPendingReports - нерассмотренные жалобы от старых к новым, начиная после afterID
+func (s *Storage) ListPendingReports(ctx context.Context, afterID, limit int) ([]domain.Report, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+reportColumns+" FROM reports WHERE status = ? AND id > ? ORDER BY id LIMIT ?",
+		domain.ReportStatusPending, afterID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []domain.Report{}
+	for rows.Next() {
+		r, err := scanReport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed scanning report: %w", err)
+		}
+		reports = append(reports, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: failed iterating reports: %w", err)
+	}
+	return reports, nil
+}
+
+// ListAnquettesByStatus - анкеты в статусе status по возрастанию ID, начиная после afterID
+func (s *Storage) ListAnquettesByStatus(ctx context.Context, status string, afterID, limit int) ([]domain.Anquette, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+anquetteColumns+" FROM anquettes WHERE status = ? AND id > ? ORDER BY id LIMIT ?",
+		status, afterID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query anquettes by status: %w", err)
+	}
+	defer rows.Close()
+
+	anquettes := []domain.Anquette{}
+	for rows.Next() {
+		a, err := scanAnquette(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed scanning anquette: %w", err)
+		}
+		anquettes = append(anquettes, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: failed iterating anquettes: %w", err)
+	}
+	return anquettes, nil
+}
+
+// LastRejectionReason - причина последнего отказа модератора по анкете anquetteID;
+// пусто, если анкету не отклоняли
+func (s *Storage) LastRejectionReason(ctx context.Context, anquetteID int) (string, error) {
+	var reason string
+	err := s.db.QueryRowContext(ctx,
+		"SELECT reason FROM moderation_decisions WHERE anquette_id = ? AND action = ? ORDER BY id DESC LIMIT 1",
+		anquetteID, domain.ModerationReject,
+	).Scan(&reason)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("repository: failed to get rejection reason: %w", err)
+	}
+	return reason, nil
+}
+
+// ApplyModerationDecision - записывает решение модератора и применяет его в одной транзакции:
+// анкета d.AnquetteID переводится в anquetteStatus (если он задан; одобрение меняет
+// статус только у анкеты на модерации), при бане пользователь d.TgID помечается
+// забаненным, а жалобы на затронутых пользователя и анкету закрываются.
+func (s *Storage) ApplyModerationDecision(ctx context.Context, d domain.ModerationDecision, anquetteStatus string) (domain.ModerationDecision, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.ModerationDecision{}, fmt.Errorf("repository: failed to begin moderation decision: %w", err)
+	}
+	defer tx.Rollback()
+
+	if anquetteStatus != "" && d.AnquetteID != 0 {
+		query, args := "UPDATE anquettes SET status = ? WHERE id = ?", []any{anquetteStatus, d.AnquetteID}
+		// Одобрение жалобы на отклоненную или скрытую анкету только закрывает
+		// жалобу и не возвращает анкету в ленту
+		if d.Action == domain.ModerationApprove {
+			query += " AND status = ?"
+			args = append(args, domain.AnquetteStatusPending)
+		}
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return domain.ModerationDecision{}, fmt.Errorf("repository: failed to set anquette status: %w", err)
+		}
+	}
+	if d.Action == domain.ModerationBan && d.TgID != 0 {
+		_, err := tx.ExecContext(ctx, "UPDATE users SET banned_at = CURRENT_TIMESTAMP WHERE tg_id = ? AND banned_at IS NULL", d.TgID)
+		if err != nil {
+			return domain.ModerationDecision{}, fmt.Errorf("repository: failed to ban user: %w", err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE reports SET status = ?
+         WHERE status = ? AND (id = ? OR (? != 0 AND reported_anquette_id = ?) OR (? != 0 AND reported_tg_id = ?))`,
+		domain.ReportStatusResolved, domain.ReportStatusPending,
+		d.ReportID, d.AnquetteID, d.AnquetteID, d.TgID, d.TgID,
+	)
+	if err != nil {
+		return domain.ModerationDecision{}, fmt.Errorf("repository: failed to resolve reports: %w", err)
+	}
+
+	var out domain.ModerationDecision
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO moderation_decisions (action, report_id, anquette_id, tg_id, reason, moderator)
+         VALUES (?, ?, ?, ?, ?, ?)
+         RETURNING id, action, report_id, anquette_id, tg_id, reason, moderator, created_at`,
+		d.Action, d.ReportID, d.AnquetteID, d.TgID, d.Reason, d.Moderator,
+	).Scan(&out.ID, &out.Action, &out.ReportID, &out.AnquetteID, &out.TgID, &out.Reason, &out.Moderator, &out.CreatedAt)
+	if err != nil {
+		return domain.ModerationDecision{}, fmt.Errorf("repository: failed to insert moderation decision: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.ModerationDecision{}, fmt.Errorf("repository: failed to commit moderation decision: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"bot-api/internal/domain"
+)
+
+// --- Методы Photo ---
+
+const photoColumns = "id, anquette_id, position, storage_key, content_type, size, width, height, created_at"
+
+func scanPhoto(row interface{ Scan(...any) error }) (domain.Photo, error) {
+	var p domain.Photo
+	err := row.Scan(&p.ID, &p.AnquetteID, &p.Position, &p.StorageKey, &p.ContentType, &p.Size, &p.Width, &p.Height, &p.CreatedAt)
+	return p, err
+}
+
+// InsertPhoto - добавляет фото вместе с его копиями в конец списка фото анкеты
+func (s *Storage) InsertPhoto(ctx context.Context, p domain.Photo) (domain.Photo, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Photo{}, fmt.Errorf("repository: failed to begin insert photo: %w", err)
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRowContext(ctx,
+		`INSERT INTO photos (anquette_id, position, storage_key, content_type, size, width, height)
+         VALUES (?, (SELECT COALESCE(MAX(position) + 1, 0) FROM photos WHERE anquette_id = ?), ?, ?, ?, ?, ?)
+         RETURNING `+photoColumns,
+		p.AnquetteID, p.AnquetteID, p.StorageKey, p.ContentType, p.Size, p.Width, p.Height,
+	)
+	out, err := scanPhoto(row)
+	if err != nil {
+		return domain.Photo{}, fmt.Errorf("repository: failed to insert photo: %w", err)
+	}
+	if err := insertPhotoVariants(ctx, tx, out.ID, p.Variants); err != nil {
+		return domain.Photo{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.Photo{}, fmt.Errorf("repository: failed to commit insert photo: %w", err)
+	}
+	out.Variants = p.Variants
+	return out, nil
+}
+
+func insertPhotoVariants(ctx context.Context, tx *sql.Tx, photoID int, variants []domain.PhotoVariant) error {
+	for _, v := range variants {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO photo_variants (photo_id, variant, storage_key, content_type, size, width, height)
+             VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			photoID, v.Name, v.StorageKey, v.ContentType, v.Size, v.Width, v.Height,
+		)
+		if err != nil {
+			return fmt.Errorf("repository: failed to insert photo variant %s: %w", v.Name, err)
+		}
+	}
+	return nil
+}
+
+// UpdateProcessedPhoto - заменяет файл фото обработанной версией и сохраняет его копии
+func (s *Storage) UpdateProcessedPhoto(ctx context.Context, p domain.Photo) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repository: failed to begin update photo: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		"UPDATE photos SET storage_key = ?, content_type = ?, size = ?, width = ?, height = ? WHERE id = ?",
+		p.StorageKey, p.ContentType, p.Size, p.Width, p.Height, p.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("repository: failed to execute update photo: %w", err)
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM photo_variants WHERE photo_id = ?", p.ID); err != nil {
+		return fmt.Errorf("repository: failed to delete photo variants: %w", err)
+	}
+	if err := insertPhotoVariants(ctx, tx, p.ID, p.Variants); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repository: failed to commit update photo: %w", err)
+	}
+	return nil
+}
+
+func (s *Storage) GetPhoto(ctx context.Context, id int) (domain.Photo, error) {
+	row := s.db.QueryRowContext(ctx, "SELECT "+photoColumns+" FROM photos WHERE id = ?", id)
+	p, err := scanPhoto(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Photo{}, sql.ErrNoRows
+		}
+		return domain.Photo{}, fmt.Errorf("repository: failed scanning photo: %w", err)
+	}
+
+	photos := []domain.Photo{p}
+	if err := s.loadPhotoVariants(ctx, photos); err != nil {
+		return domain.Photo{}, err
+	}
+	return photos[0], nil
+}
+
+// ListPhotos - фото анкеты в порядке показа
+func (s *Storage) ListPhotos(ctx context.Context, anquetteID int) ([]domain.Photo, error) {
+	return s.queryPhotos(ctx, "SELECT "+photoColumns+" FROM photos WHERE anquette_id = ? ORDER BY position", anquetteID)
+}
+
+// ListUnprocessedPhotos - фото, загруженные до появления обработки (width = 0), с ID больше afterID
+func (s *Storage) ListUnprocessedPhotos(ctx context.Context, afterID, limit int) ([]domain.Photo, error) {
+	return s.queryPhotos(ctx, "SELECT "+photoColumns+" FROM photos WHERE width = 0 AND id > ? ORDER BY id LIMIT ?", afterID, limit)
+}
+
+func (s *Storage) queryPhotos(ctx context.Context, query string, args ...any) ([]domain.Photo, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query photos: %w", err)
+	}
+	defer rows.Close()
+
+	photos := []domain.Photo{}
+	for rows.Next() {
+		p, err := scanPhoto(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed scanning photo: %w", err)
+		}
+		photos = append(photos, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: failed iterating photos: %w", err)
+	}
+	if err := s.loadPhotoVariants(ctx, photos); err != nil {
+		return nil, err
+	}
+	return photos, nil
+}
+
+// loadPhotoVariants - заполняет Variants у переданных фото одним запросом
+func (s *Storage) loadPhotoVariants(ctx context.Context, photos []domain.Photo) error {
+	if len(photos) == 0 {
+		return nil
+	}
+	index := make(map[int]int, len(photos))
+	args := make([]any, len(photos))
+	for i, p := range photos {
+		index[p.ID] = i
+		args[i] = p.ID
+		photos[i].Variants = []domain.PhotoVariant{}
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT photo_id, variant, storage_key, content_type, size, width, height
+         FROM photo_variants
+         WHERE photo_id IN (?`+strings.Repeat(", ?", len(photos)-1)+`)
+         ORDER BY photo_id, width DESC`,
+		args...,
+	)
+	if err != nil {
+		return fmt.Errorf("repository: failed to query photo variants: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			photoID int
+			v       domain.PhotoVariant
+		)
+		if err := rows.Scan(&photoID, &v.Name, &v.StorageKey, &v.ContentType, &v.Size, &v.Width, &v.Height); err != nil {
+			return fmt.Errorf("repository: failed scanning photo variant: %w", err)
+		}
+		i := index[photoID]
+		photos[i].Variants = append(photos[i].Variants, v)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("repository: failed iterating photo variants: %w", err)
+	}
+	return nil
+}
+
+// DeletePhoto - удаляет фото с копиями и сдвигает следующие за ним,
+// чтобы позиции шли без пропусков
+func (s *Storage) DeletePhoto(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repository: failed to begin delete photo: %w", err)
+	}
+	defer tx.Rollback()
+
+	var anquetteID, position int
+	err = tx.QueryRowContext(ctx, "DELETE FROM photos WHERE id = ? RETURNING anquette_id, position", id).Scan(&anquetteID, &position)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sql.ErrNoRows
+		}
+		return fmt.Errorf("repository: failed to execute delete photo: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM photo_variants WHERE photo_id = ?", id); err != nil {
+		return fmt.Errorf("repository: failed to delete photo variants: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE photos SET position = position - 1 WHERE anquette_id = ? AND position > ?", anquetteID, position)
+	if err != nil {
+		return fmt.Errorf("repository: failed to shift photo positions: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repository: failed to commit delete photo: %w", err)
+	}
+	return nil
+}
+
+// DeleteAnquettePhotos - удаляет все фото анкеты вместе с копиями
+func (s *Storage) DeleteAnquettePhotos(ctx context.Context, anquetteID int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repository: failed to begin delete anquette photos: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM photo_variants WHERE photo_id IN (SELECT id FROM photos WHERE anquette_id = ?)", anquetteID)
+	if err != nil {
+		return fmt.Errorf("repository: failed to delete anquette photo variants: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM photos WHERE anquette_id = ?", anquetteID); err != nil {
+		return fmt.Errorf("repository: failed to delete anquette photos: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repository: failed to commit delete anquette photos: %w", err)
+	}
+	return nil
+}
+
+// ReorderPhotos - присваивает фото позиции в порядке photoIDs.
+// Проверка, что photoIDs - ровно все фото анкеты, лежит на вызывающем.
+func (s *Storage) ReorderPhotos(ctx context.Context, anquetteID int, photoIDs []int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repository: failed to begin reorder photos: %w", err)
+	}
+	defer tx.Rollback()
+
+	for pos, id := range photoIDs {
+		res, err := tx.ExecContext(ctx, "UPDATE photos SET position = ? WHERE id = ? AND anquette_id = ?", pos, id, anquetteID)
+		if err != nil {
+			return fmt.Errorf("repository: failed to update photo position: %w", err)
+		}
+		if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+			return sql.ErrNoRows
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repository: failed to commit reorder photos: %w", err)
+	}
+	return nil
+}
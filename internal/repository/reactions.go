@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"bot-api/internal/domain"
+)
+
+// querier - *sql.DB или *sql.Tx: запросы, которые выполняются и отдельно, и в транзакции
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// --- Методы Reaction ---
+
+// InsertReaction - сохраняет реакцию. Повторная реакция на ту же анкету возвращает ErrDuplicate.
+func (s *Storage) InsertReaction(ctx context.Context, r domain.ReactionRequest) (domain.Reaction, error) {
+	return insertReaction(ctx, s.db, r)
+}
+
+// InsertReactionAndMatch - сохраняет реакцию r и, если пользователь matchTgID уже лайкнул
+// анкету matchAnquetteID, создает мэтч r.TgID и matchTgID. Все в одной транзакции:
+// если мэтч создать не удалось, реакция тоже не сохраняется и запрос можно повторить.
+// Повторная реакция на ту же анкету возвращает ErrDuplicate; мэтча нет - nil.
+func (s *Storage) InsertReactionAndMatch(ctx context.Context, r domain.ReactionRequest, matchTgID int64, matchAnquetteID int) (domain.Reaction, *domain.Match, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Reaction{}, nil, fmt.Errorf("repository: failed to begin reaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	reaction, err := insertReaction(ctx, tx, r)
+	if err != nil {
+		return domain.Reaction{}, nil, err
+	}
+	mutual, err := hasLiked(ctx, tx, matchTgID, matchAnquetteID)
+	if err != nil {
+		return domain.Reaction{}, nil, err
+	}
+	var match *domain.Match
+	if mutual {
+		m, err := insertMatch(ctx, tx, r.TgID, matchTgID)
+		if err != nil {
+			return domain.Reaction{}, nil, err
+		}
+		match = &m
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.Reaction{}, nil, fmt.Errorf("repository: failed to commit reaction: %w", err)
+	}
+	return reaction, match, nil
+}
+
+func insertReaction(ctx context.Context, q querier, r domain.ReactionRequest) (domain.Reaction, error) {
+	res, err := q.ExecContext(ctx,
+		`INSERT INTO reactions (from_tg_id, to_anquette_id, kind)
+         VALUES (?, ?, ?)
+         ON CONFLICT (from_tg_id, to_anquette_id) DO NOTHING`,
+		r.TgID, r.AnquetteID, r.Kind,
+	)
+	if err != nil {
+		return domain.Reaction{}, fmt.Errorf("repository: failed to insert reaction: %w", err)
+	}
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return domain.Reaction{}, ErrDuplicate
+	}
+
+	var out domain.Reaction
+	err = q.QueryRowContext(ctx,
+		"SELECT id, from_tg_id, to_anquette_id, kind, created_at FROM reactions WHERE from_tg_id = ? AND to_anquette_id = ?",
+		r.TgID, r.AnquetteID,
+	).Scan(&out.ID, &out.FromTgID, &out.ToAnquetteID, &out.Kind, &out.CreatedAt)
+	if err != nil {
+		return domain.Reaction{}, fmt.Errorf("repository: failed scanning reaction: %w", err)
+	}
+	return out, nil
+}
+
+// hasLiked - проверяет, лайкал ли пользователь fromTgID анкету toAnquetteID
+func hasLiked(ctx context.Context, q querier, fromTgID int64, toAnquetteID int) (bool, error) {
+	var liked bool
+	err := q.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM reactions WHERE from_tg_id = ? AND to_anquette_id = ? AND kind = ?)",
+		fromTgID, toAnquetteID, domain.ReactionLike,
+	).Scan(&liked)
+	if err != nil {
+		return false, fmt.Errorf("repository: failed to check like: %w", err)
+	}
+	return liked, nil
+}
+
+// --- Методы Match ---
+
+// insertMatch - создает мэтч пары пользователей, если его еще нет, и возвращает его
+func insertMatch(ctx context.Context, q querier, tgID1, tgID2 int64) (domain.Match, error) {
+	if tgID1 > tgID2 {
+		tgID1, tgID2 = tgID2, tgID1
+	}
+
+	_, err := q.ExecContext(ctx,
+		`INSERT INTO matches (user1_tg_id, user2_tg_id)
+         VALUES (?, ?)
+         ON CONFLICT (user1_tg_id, user2_tg_id) DO NOTHING`,
+		tgID1, tgID2,
+	)
+	if err != nil {
+		return domain.Match{}, fmt.Errorf("repository: failed to insert match: %w", err)
+	}
+
+	var m domain.Match
+	err = q.QueryRowContext(ctx,
+		"SELECT id, user1_tg_id, user2_tg_id, created_at FROM matches WHERE user1_tg_id = ? AND user2_tg_id = ?",
+		tgID1, tgID2,
+	).Scan(&m.ID, &m.UserTgID1, &m.UserTgID2, &m.CreatedAt)
+	if err != nil {
+		return domain.Match{}, fmt.Errorf("repository: failed scanning match: %w", err)
+	}
+	return m, nil
+}
+
+func (s *Storage) GetMatch(ctx context.Context, id int) (domain.Match, error) {
+	var m domain.Match
+	row := s.db.QueryRowContext(ctx, "SELECT id, user1_tg_id, user2_tg_id, created_at FROM matches WHERE id = ?", id)
+	err := row.Scan(&m.ID, &m.UserTgID1, &m.UserTgID2, &m.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Match{}, sql.ErrNoRows
+		}
+		return domain.Match{}, fmt.Errorf("repository: failed scanning match: %w", err)
+	}
+	return m, nil
+}
+
+// ListMatches - мэтчи пользователя от новых к старым вместе с данными партнера.
+// Мэтчи с заблокированными в любую сторону пользователями не показываются.
+// beforeID - курсор: ID последнего показанного мэтча (0 - с начала).
+func (s *Storage) ListMatches(ctx context.Context, tgID int64, beforeID, limit int) ([]domain.MatchView, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT m.id, m.created_at, u.tg_id, u.tg_username,
+                a.id, a.name, a.age, a.city, a.gender, a.description,
+                COALESCE(a.pref_genders, ''), COALESCE(a.pref_age_min, 0), COALESCE(a.pref_age_max, 0),
+                COALESCE(a.pref_max_distance_km, 0), COALESCE(a.goal, ''), COALESCE(a.dealbreakers, ''),
+                COALESCE(a.preferences_text, '')
+         FROM matches m
+         JOIN users u ON u.tg_id = CASE WHEN m.user1_tg_id = ? THEN m.user2_tg_id ELSE m.user1_tg_id END
+         LEFT JOIN anquettes a ON a.id = u.anquette_id
+         WHERE (m.user1_tg_id = ? OR m.user2_tg_id = ?) AND (? = 0 OR m.id < ?)
+           AND NOT EXISTS (SELECT 1 FROM blocks b
+               WHERE (b.blocker_tg_id = m.user1_tg_id AND b.blocked_tg_id = m.user2_tg_id)
+                  OR (b.blocker_tg_id = m.user2_tg_id AND b.blocked_tg_id = m.user1_tg_id))
+         ORDER BY m.id DESC
+         LIMIT ?`,
+		tgID, tgID, tgID, beforeID, beforeID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query matches: %w", err)
+	}
+	defer rows.Close()
+
+	matches := []domain.MatchView{}
+	for rows.Next() {
+		var (
+			m                               domain.MatchView
+			anquetteID                      sql.NullInt64
+			a                               domain.Anquette
+			age                             sql.NullInt64
+			name, city, gender, description sql.NullString
+			genders, dealbreakers           string
+		)
+		p := &a.Preferences
+		err := rows.Scan(&m.ID, &m.CreatedAt, &m.Partner.TgID, &m.Partner.TgUsername,
+			&anquetteID, &name, &age, &city, &gender, &description,
+			&genders, &p.AgeMin, &p.AgeMax, &p.MaxDistanceKm, &p.Goal, &dealbreakers, &p.Text)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed scanning match: %w", err)
+		}
+		if anquetteID.Valid {
+			a.ID, a.Name, a.Age = int(anquetteID.Int64), name.String, int(age.Int64)
+			a.City, a.Gender, a.Description = city.String, gender.String, description.String
+			p.Genders, p.Dealbreakers = splitList(genders), splitList(dealbreakers)
+			m.Partner.Anquette = &a
+		}
+		matches = append(matches, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: failed iterating matches: %w", err)
+	}
+	return matches, nil
+}
+
+// DeleteMatch - удаляет мэтч. Реакции обеих сторон остаются,
+// поэтому пара больше не встретится в ленте.
+func (s *Storage) DeleteMatch(ctx context.Context, id int) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM matches WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("repository: failed to execute delete match: %w", err)
+	}
+
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
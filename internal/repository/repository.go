@@ -5,25 +5,73 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"log"
+	"sort"
+	"strings"
 
 	"bot-api/internal/domain"
 
 	_ "modernc.org/sqlite"
 )
 
+// ErrDuplicate - запись с таким уникальным ключом уже существует
+var ErrDuplicate = errors.New("repository: duplicate record")
+
 // UserRepository - интерфейс с экспортированными именами функций
 type UserRepository interface {
-	CreateTables() error
+	Migrate(ctx context.Context) error
 
 	InsertUser(ctx context.Context, u domain.UserRequest) (int, error)
 	GetUser(ctx context.Context, tg_id int) (domain.User, error)
 	UpdateUser(ctx context.Context, tg_id int, u domain.UserRequest) error
+	GetUserByAnquette(ctx context.Context, anquetteID int) (domain.User, error)
+	TouchUser(ctx context.Context, tgID int64) error
 
 	InsertAnquette(ctx context.Context, a domain.AnquetteRequest) (int, error)
 	GetAnquette(ctx context.Context, id int) (domain.Anquette, error)
 	UpdateAnquette(ctx context.Context, id int, a domain.AnquetteRequest) error
 	DeleteAnquette(ctx context.Context, id int) error
+	SetAnquetteLocation(ctx context.Context, id int, p *domain.GeoPoint) error
+	SetAnquetteInterests(ctx context.Context, id int, interestIDs []string) error
+	ListInterests(ctx context.Context) ([]domain.Interest, error)
+	ListAnquettesWithoutCityID(ctx context.Context, afterID, limit int) ([]domain.Anquette, error)
+	SetAnquetteCity(ctx context.Context, id int, city, cityID string) error
+
+	GetFeed(ctx context.Context, f domain.FeedFilter) ([]domain.FeedItem, error)
+
+	ListUsers(ctx context.Context, f domain.ListFilter) (domain.Page[domain.User], error)
+	ListAnquettes(ctx context.Context, f domain.ListFilter) (domain.Page[domain.Anquette], error)
+	SearchAnquettes(ctx context.Context, f domain.SearchFilter) ([]domain.SearchHit, error)
+
+	InsertReaction(ctx context.Context, r domain.ReactionRequest) (domain.Reaction, error)
+	InsertReactionAndMatch(ctx context.Context, r domain.ReactionRequest, matchTgID int64, matchAnquetteID int) (domain.Reaction, *domain.Match, error)
+	GetMatch(ctx context.Context, id int) (domain.Match, error)
+	ListMatches(ctx context.Context, tgID int64, beforeID, limit int) ([]domain.MatchView, error)
+	DeleteMatch(ctx context.Context, id int) error
+
+	InsertBlock(ctx context.Context, blockerTgID, blockedTgID int64) (domain.Block, error)
+	IsBlocked(ctx context.Context, tgID1, tgID2 int64) (bool, error)
+	InsertReport(ctx context.Context, r domain.Report) (domain.Report, error)
+
+	GetReport(ctx context.Context, id int) (domain.Report, error)
+	ListPendingReports(ctx context.Context, afterID, limit int) ([]domain.Report, error)
+	ListAnquettesByStatus(ctx context.Context, status string, afterID, limit int) ([]domain.Anquette, error)
+	SetAnquetteStatus(ctx context.Context, id int, status string) error
+	ApplyModerationDecision(ctx context.Context, d domain.ModerationDecision, anquetteStatus string) (domain.ModerationDecision, error)
+	LastRejectionReason(ctx context.Context, anquetteID int) (string, error)
+
+	InsertPhoto(ctx context.Context, p domain.Photo) (domain.Photo, error)
+	GetPhoto(ctx context.Context, id int) (domain.Photo, error)
+	ListPhotos(ctx context.Context, anquetteID int) ([]domain.Photo, error)
+	ListUnprocessedPhotos(ctx context.Context, afterID, limit int) ([]domain.Photo, error)
+	UpdateProcessedPhoto(ctx context.Context, p domain.Photo) error
+	DeletePhoto(ctx context.Context, id int) error
+	DeleteAnquettePhotos(ctx context.Context, anquetteID int) error
+	ReorderPhotos(ctx context.Context, anquetteID int, photoIDs []int) error
+
+	InsertAPIKey(ctx context.Context, req domain.APIKeyRequest, keyHash string) (domain.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (domain.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int) error
 }
 
 type Storage struct {
@@ -34,35 +82,9 @@ func NewStorage(db *sql.DB) *Storage {
 	return &Storage{db: db}
 }
 
-func (s *Storage) CreateTables() error {
-	// ... (логика создания таблиц)
-	usersTable := `
-	CREATE TABLE IF NOT EXISTS users (
-		tg_id INTEGER NOT NULL UNIQUE,
-		tg_username TEXT,
-		anquette_id INTEGER
-	);`
-	_, err := s.db.Exec(usersTable)
-	if err != nil {
-		return fmt.Errorf("repository: failed to create users table: %w", err)
-	}
-	anquettesTable := `
-	CREATE TABLE IF NOT EXISTS anquettes (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name TEXT NOT NULL,
-		age INTEGER NOT NULL,
-		city TEXT,
-		gender TEXT,
-		preferences TEXT,
-		description TEXT NOT NULL
-	);`
-	_, err = s.db.Exec(anquettesTable)
-	if err != nil {
-		return fmt.Errorf("repository: failed to create anquettes table: %w", err)
-	}
-
-	log.Println("INFO: Таблицы БД успешно инициализированы.")
-	return nil
+// Ping - доступна ли БД
+func (s *Storage) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
 }
 
 // --- Методы User с экспортированными именами ---
@@ -70,10 +92,10 @@ func (s *Storage) CreateTables() error {
 func (s *Storage) InsertUser(ctx context.Context, u domain.UserRequest) (int, error) {
 	var id int
 	err := s.db.QueryRowContext(ctx,
-		`INSERT INTO users (tg_id, tg_username, anquette_id)
-         VALUES (?, ?, ?)
+		`INSERT INTO users (tg_id, tg_username, anquette_id, locale, created_at)
+         VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
          RETURNING tg_id`,
-		u.TgID, u.TgUsername, u.AnquetteID,
+		u.TgID, u.TgUsername, u.AnquetteID, u.Locale,
 	).Scan(&id)
 	if err != nil {
 		return 0, fmt.Errorf("repository: failed to insert user: %w", err)
@@ -82,11 +104,34 @@ func (s *Storage) InsertUser(ctx context.Context, u domain.UserRequest) (int, er
 	return id, nil
 }
 
+const userColumns = "tg_id, tg_username, anquette_id, locale, banned_at, last_active_at, created_at"
+
+// scanUser - сканирует userColumns; extra - приемники для колонок, выбранных после них
+func scanUser(row interface{ Scan(...any) error }, extra ...any) (domain.User, error) {
+	var (
+		u                       domain.User
+		banned, active, created sql.NullTime
+	)
+	dest := append([]any{&u.TgID, &u.TgUsername, &u.AnquetteID, &u.Locale, &banned, &active, &created}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return domain.User{}, err
+	}
+	if created.Valid {
+		u.CreatedAt = &created.Time
+	}
+	if banned.Valid {
+		u.BannedAt = &banned.Time
+	}
+	if active.Valid {
+		u.LastActiveAt = &active.Time
+	}
+	return u, nil
+}
+
 func (s *Storage) GetUser(ctx context.Context, tg_id int) (domain.User, error) {
-	var u domain.User
-	row := s.db.QueryRowContext(ctx, "SELECT tg_id, tg_username, anquette_id FROM users WHERE tg_id = ?", tg_id)
+	row := s.db.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE tg_id = ?", tg_id)
 
-	err := row.Scan(&u.TgID, &u.TgUsername, &u.AnquetteID)
+	u, err := scanUser(row)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return domain.User{}, sql.ErrNoRows
@@ -97,8 +142,8 @@ func (s *Storage) GetUser(ctx context.Context, tg_id int) (domain.User, error) {
 }
 
 func (s *Storage) UpdateUser(ctx context.Context, tg_id int, u domain.UserRequest) error {
-	res, err := s.db.ExecContext(ctx, "UPDATE users SET tg_id = ?, tg_username = ?, anquette_id = ? WHERE tg_id = ?",
-		u.TgID, u.TgUsername, u.AnquetteID, tg_id)
+	res, err := s.db.ExecContext(ctx, "UPDATE users SET tg_id = ?, tg_username = ?, anquette_id = ?, locale = ? WHERE tg_id = ?",
+		u.TgID, u.TgUsername, u.AnquetteID, u.Locale, tg_id)
 	if err != nil {
 		return fmt.Errorf("repository: failed to execute update user: %w", err)
 	}
@@ -110,11 +155,46 @@ func (s *Storage) UpdateUser(ctx context.Context, tg_id int, u domain.UserReques
 	return nil
 }
 
+// TouchUser - отмечает, что пользователь только что был активен
+func (s *Storage) TouchUser(ctx context.Context, tgID int64) error {
+	res, err := s.db.ExecContext(ctx, "UPDATE users SET last_active_at = CURRENT_TIMESTAMP WHERE tg_id = ?", tgID)
+	if err != nil {
+		return fmt.Errorf("repository: failed to touch user: %w", err)
+	}
+
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetUserByAnquette - находит владельца анкеты
+func (s *Storage) GetUserByAnquette(ctx context.Context, anquetteID int) (domain.User, error) {
+	row := s.db.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE anquette_id = ?", anquetteID)
+
+	u, err := scanUser(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.User{}, sql.ErrNoRows
+		}
+		return domain.User{}, fmt.Errorf("repository: failed scanning anquette owner: %w", err)
+	}
+	return u, nil
+}
+
 // --- Методы Anquette с экспортированными именами ---
 
 func (s *Storage) InsertAnquette(ctx context.Context, a domain.AnquetteRequest) (int, error) {
-	res, err := s.db.ExecContext(ctx, "INSERT INTO anquettes(name, age, city, gender, preferences, description) values(?, ?, ?, ?, ?, ?)",
-		a.Name, a.Age, a.City, a.Gender, a.Preferences, a.Description)
+	status := a.Status
+	if status == "" {
+		status = domain.AnquetteStatusActive
+	}
+	p := a.Preferences
+	res, err := s.db.ExecContext(ctx, "INSERT INTO anquettes(name, age, city, city_id, gender, "+preferencesColumns+", description, status, created_at) values(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)",
+		a.Name, a.Age, a.City, a.CityID, a.Gender,
+		joinList(p.Genders), p.AgeMin, p.AgeMax, p.MaxDistanceKm, p.Goal, joinList(p.Dealbreakers), p.Text,
+		a.Description, status)
 	if err != nil {
 		return 0, fmt.Errorf("repository: failed to insert anquette: %w", err)
 	}
@@ -125,10 +205,56 @@ func (s *Storage) InsertAnquette(ctx context.Context, a domain.AnquetteRequest)
 	return int(id), nil
 }
 
-func (s *Storage) GetAnquette(ctx context.Context, id int) (domain.Anquette, error) {
+// preferencesColumns - колонки domain.Preferences; списки хранятся через запятую
+const preferencesColumns = "pref_genders, pref_age_min, pref_age_max, pref_max_distance_km, goal, dealbreakers, preferences_text"
+
+// anquetteColumns - колонки анкеты для SELECT ... FROM anquettes; интересы
+// собираются подзапросом в строку через запятую
+const anquetteColumns = `id, name, age, city, city_id, gender, ` + preferencesColumns + `, description, status, lat, lon, created_at,
+    (SELECT group_concat(interest_id) FROM anquette_interests WHERE anquette_id = anquettes.id) AS interests`
+
+// joinList и splitList - списки в колонках через запятую
+func joinList(l []string) string {
+	return strings.Join(l, ",")
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// scanAnquette - сканирует anquetteColumns; extra - приемники для колонок,
+// выбранных после них
+func scanAnquette(row interface{ Scan(...any) error }, extra ...any) (domain.Anquette, error) {
 	var a domain.Anquette
-	row := s.db.QueryRowContext(ctx, "SELECT id, name, age, city, gender, preferences, description FROM anquettes WHERE id = ?", id)
-	err := row.Scan(&a.ID, &a.Name, &a.Age, &a.City, &a.Gender, &a.Preferences, &a.Description)
+	var lat, lon sql.NullFloat64
+	var created sql.NullTime
+	var genders, dealbreakers string
+	var text, interests sql.NullString
+	p := &a.Preferences
+	dest := append([]any{&a.ID, &a.Name, &a.Age, &a.City, &a.CityID, &a.Gender,
+		&genders, &p.AgeMin, &p.AgeMax, &p.MaxDistanceKm, &p.Goal, &dealbreakers, &text,
+		&a.Description, &a.Status, &lat, &lon, &created, &interests}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return a, err
+	}
+	p.Genders, p.Dealbreakers, p.Text = splitList(genders), splitList(dealbreakers), text.String
+	if lat.Valid && lon.Valid {
+		a.Location = &domain.GeoPoint{Lat: lat.Float64, Lon: lon.Float64}
+	}
+	if created.Valid {
+		a.CreatedAt = &created.Time
+	}
+	a.Interests = splitList(interests.String)
+	sort.Strings(a.Interests)
+	return a, nil
+}
+
+func (s *Storage) GetAnquette(ctx context.Context, id int) (domain.Anquette, error) {
+	row := s.db.QueryRowContext(ctx, "SELECT "+anquetteColumns+" FROM anquettes WHERE id = ?", id)
+	a, err := scanAnquette(row)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return domain.Anquette{}, sql.ErrNoRows
@@ -138,9 +264,16 @@ func (s *Storage) GetAnquette(ctx context.Context, id int) (domain.Anquette, err
 	return a, nil
 }
 
+// UpdateAnquette - обновляет анкету; пустой a.Status оставляет статус прежним
 func (s *Storage) UpdateAnquette(ctx context.Context, id int, a domain.AnquetteRequest) error {
-	res, err := s.db.ExecContext(ctx, "UPDATE anquettes SET name = ?, age = ?, city = ?, gender = ?, preferences = ?, description = ? WHERE id = ?",
-		a.Name, a.Age, a.City, a.Gender, a.Preferences, a.Description, id)
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE anquettes SET name = ?, age = ?, city = ?, city_id = ?, gender = ?,
+             pref_genders = ?, pref_age_min = ?, pref_age_max = ?, pref_max_distance_km = ?, goal = ?, dealbreakers = ?, preferences_text = ?,
+             description = ?, status = COALESCE(NULLIF(?, ''), status) WHERE id = ?`,
+		a.Name, a.Age, a.City, a.CityID, a.Gender,
+		joinList(a.Preferences.Genders), a.Preferences.AgeMin, a.Preferences.AgeMax, a.Preferences.MaxDistanceKm,
+		a.Preferences.Goal, joinList(a.Preferences.Dealbreakers), a.Preferences.Text,
+		a.Description, a.Status, id)
 	if err != nil {
 		return fmt.Errorf("repository: failed to execute update anquette: %w", err)
 	}
@@ -152,8 +285,48 @@ func (s *Storage) UpdateAnquette(ctx context.Context, id int, a domain.AnquetteR
 	return nil
 }
 
+// SetAnquetteStatus - переводит анкету в статус domain.AnquetteStatus*
+func (s *Storage) SetAnquetteStatus(ctx context.Context, id int, status string) error {
+	res, err := s.db.ExecContext(ctx, "UPDATE anquettes SET status = ? WHERE id = ?", status, id)
+	if err != nil {
+		return fmt.Errorf("repository: failed to set anquette status: %w", err)
+	}
+
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetAnquetteLocation - сохраняет координаты анкеты; nil стирает их
+func (s *Storage) SetAnquetteLocation(ctx context.Context, id int, p *domain.GeoPoint) error {
+	var lat, lon sql.NullFloat64
+	if p != nil {
+		lat = sql.NullFloat64{Float64: p.Lat, Valid: true}
+		lon = sql.NullFloat64{Float64: p.Lon, Valid: true}
+	}
+	res, err := s.db.ExecContext(ctx, "UPDATE anquettes SET lat = ?, lon = ? WHERE id = ?", lat, lon, id)
+	if err != nil {
+		return fmt.Errorf("repository: failed to set anquette location: %w", err)
+	}
+
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteAnquette - удаляет анкету вместе с ее интересами
 func (s *Storage) DeleteAnquette(ctx context.Context, id int) error {
-	res, err := s.db.ExecContext(ctx, "DELETE FROM anquettes WHERE id = ?", id)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repository: failed to begin delete anquette: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "DELETE FROM anquettes WHERE id = ?", id)
 	if err != nil {
 		return fmt.Errorf("repository: failed to execute delete anquette: %w", err)
 	}
@@ -162,5 +335,11 @@ func (s *Storage) DeleteAnquette(ctx context.Context, id int) error {
 	if rowsAffected == 0 {
 		return sql.ErrNoRows
 	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM anquette_interests WHERE anquette_id = ?", id); err != nil {
+		return fmt.Errorf("repository: failed to delete anquette interests: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repository: failed to commit delete anquette: %w", err)
+	}
 	return nil
 }
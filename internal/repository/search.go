@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"bot-api/internal/domain"
+	"bot-api/internal/search"
+)
+
+// Параметры snippet() и bm25() для anquettes_fts
+const (
+	snippetTokens  = 16  // длина фрагмента описания в словах
+	nameWeight     = 2.0 // совпадение в имени важнее совпадения в описании
+	descriptionCol = 1
+)
+
+// --- Методы Search ---
+
+// SearchAnquettes - анкеты, в имени или описании которых есть все слова запроса,
+// по убыванию релевантности (BM25). Пустой запрос - пустой результат.
+func (s *Storage) SearchAnquettes(ctx context.Context, f domain.SearchFilter) ([]domain.SearchHit, error) {
+	hits := []domain.SearchHit{}
+	match := search.Match(f.Query)
+	if match == "" {
+		return hits, nil
+	}
+
+	var conds []string
+	args := []any{search.MarkStart, search.MarkEnd, match}
+	if f.Status != "" {
+		conds = append(conds, "status = ?")
+		args = append(args, f.Status)
+	}
+	if f.ViewerTgID != 0 {
+		conds = append(conds, notBlockedCond)
+		args = append(args, f.ViewerTgID, f.ViewerTgID)
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+	args = append(args, f.Limit)
+
+	query := "SELECT " + anquetteColumns + ", m.snippet, m.relevance FROM anquettes JOIN (" +
+		fmt.Sprintf("SELECT rowid AS fts_id, snippet(anquettes_fts, %d, ?, ?, '…', %d) AS snippet, -bm25(anquettes_fts, %g, 1.0) AS relevance",
+			descriptionCol, snippetTokens, nameWeight) +
+		" FROM anquettes_fts WHERE anquettes_fts MATCH ?) m ON m.fts_id = anquettes.id" +
+		where + " ORDER BY m.relevance DESC, anquettes.id LIMIT ?"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to search anquettes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var h domain.SearchHit
+		h.Anquette, err = scanAnquette(rows, &h.Snippet, &h.Rank)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed scanning search hit: %w", err)
+		}
+		h.Snippet = search.Highlight(h.Snippet)
+		hits = append(hits, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: failed iterating search hits: %w", err)
+	}
+	return hits, nil
+}
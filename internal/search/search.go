@@ -0,0 +1,61 @@
+// Package search - запросы полнотекстового поиска по анкетам (SQLite FTS5).
+// Текст индексируется токенайзером unicode61 без стемминга, а слова запроса
+// сводятся к основе и ищутся как префиксы: "музыку" находит "музыка", "музыкант".
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// MaxTerms - больше слов из запроса не берется
+	MaxTerms = 8
+	// minPrefixLen - более короткие основы ищутся как слово целиком,
+	// иначе "я" или "он" совпадут почти с каждой анкетой
+	minPrefixLen = 3
+)
+
+// Границы совпадения во фрагменте, которые возвращает FTS5 snippet().
+// Управляющие символы не встречаются в тексте анкет и не меняются при экранировании.
+const (
+	MarkStart = "\x01"
+	MarkEnd   = "\x02"
+)
+
+// Normalize - текст в том виде, в каком он попадает в индекс (см. миграцию anquettes_fts)
+func Normalize(s string) string {
+	return strings.NewReplacer("ё", "е", "Ё", "Е").Replace(s)
+}
+
+// Match - выражение FTS5 MATCH для запроса пользователя: все слова запроса
+// должны встретиться в анкете. Пустая строка - в запросе нет слов.
+func Match(q string) string {
+	var terms []string
+	seen := map[string]bool{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(Normalize(q)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		term := `"` + w + `"`
+		if stem := Stem(w); utf8.RuneCountInString(stem) >= minPrefixLen {
+			term = `"` + stem + `"*`
+		}
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		if terms = append(terms, term); len(terms) == MaxTerms {
+			break
+		}
+	}
+	return strings.Join(terms, " ")
+}
+
+// Highlight - фрагмент из snippet() для вывода в HTML: текст экранируется,
+// совпадения оборачиваются в <mark>
+func Highlight(snippet string) string {
+	s := html.EscapeString(snippet)
+	return strings.NewReplacer(MarkStart, "<mark>", MarkEnd, "</mark>").Replace(s)
+}
@@ -0,0 +1,44 @@
+package search
+
+import "testing"
+
+func TestStem(t *testing.T) {
+	for word, want := range map[string]string{
+		"музыку":           "музык",
+		"музыкой":          "музык",
+		"путешествия":      "путешеств",
+		"программирование": "программирован",
+		"красивейшая":      "красив",
+		"интересуюсь":      "интерес",
+		"общительность":    "общительн",
+		"длинная":          "длин",
+		"улыбнувшись":      "улыбнувш",
+		"ёлки":             "елк",
+		"кот":              "кот",
+		"it":               "it",
+	} {
+		if got := Stem(word); got != want {
+			t.Errorf("Stem(%q) = %q, ожидали %q", word, got, want)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	for q, want := range map[string]string{
+		"Люблю МУЗЫКУ и путешествия!": `"любл"* "музык"* "и" "путешеств"*`,
+		"ёлки, елки":     `"елк"*`,
+		"я\") OR name:*": `"я" "or" "name"*`,
+		" ,.!? ":         "",
+	} {
+		if got := Match(q); got != want {
+			t.Errorf("Match(%q) = %q, ожидали %q", q, got, want)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	got := Highlight("<script> и " + MarkStart + "музыка" + MarkEnd)
+	if want := "&lt;script&gt; и <mark>музыка</mark>"; got != want {
+		t.Errorf("Highlight = %q, ожидали %q", got, want)
+	}
+}
@@ -0,0 +1,149 @@
+package search
+
+import (
+	"slices"
+	"strings"
+)
+
+// Русский стеммер Snowball (https://snowballstem.org/algorithms/russian/stemmer.html).
+// Окончания отрезаются только в RV - части слова после первой гласной;
+// словообразовательные суффиксы - только в R2.
+
+// Окончания групп 1 отрезаются, только если перед ними "а" или "я"
+var (
+	perfectiveGerund1 = suffixes("в", "вши", "вшись")
+	perfectiveGerund2 = suffixes("ив", "ивши", "ившись", "ыв", "ывши", "ывшись")
+	adjective         = suffixes("ее", "ие", "ые", "ое", "ими", "ыми", "ей", "ий", "ый", "ой", "ем", "им", "ым", "ом",
+		"его", "ого", "ему", "ому", "их", "ых", "ую", "юю", "ая", "яя", "ою", "ею")
+	participle1 = suffixes("ем", "нн", "вш", "ющ", "щ")
+	participle2 = suffixes("ивш", "ывш", "ующ")
+	reflexive   = suffixes("ся", "сь")
+	verb1       = suffixes("ла", "на", "ете", "йте", "ли", "й", "л", "ем", "н", "ло", "но", "ет", "ют", "ны", "ть", "ешь", "нно")
+	verb2       = suffixes("ила", "ыла", "ена", "ейте", "уйте", "ите", "или", "ыли", "ей", "уй", "ил", "ыл", "им", "ым", "ен",
+		"ило", "ыло", "ено", "ят", "ует", "уют", "ит", "ыт", "ены", "ить", "ыть", "ишь", "ую", "ю")
+	noun = suffixes("а", "ев", "ов", "ие", "ье", "е", "иями", "ями", "ами", "еи", "ии", "и", "ией", "ей", "ой", "ий", "й",
+		"иям", "ям", "ием", "ем", "ам", "ом", "о", "у", "ах", "иях", "ях", "ы", "ь", "ию", "ью", "ю", "ия", "ья", "я")
+	derivational = suffixes("ост", "ость")
+	superlative  = suffixes("ейш", "ейше")
+)
+
+// suffixes - окончания в виде рун, от длинных к коротким
+func suffixes(s ...string) [][]rune {
+	out := make([][]rune, len(s))
+	for i, x := range s {
+		out[i] = []rune(x)
+	}
+	slices.SortStableFunc(out, func(a, b []rune) int { return len(b) - len(a) })
+	return out
+}
+
+func isVowel(r rune) bool {
+	return strings.ContainsRune("аеиоуыэюя", r)
+}
+
+// stemmer - слово и границы его областей RV и R2
+type stemmer struct {
+	w      []rune
+	rv, r2 int
+}
+
+// longest - самое длинное окончание из set, целиком лежащее в w[from:]; -1, если нет
+func (s *stemmer) longest(set [][]rune, from int) int {
+	for i, suf := range set {
+		n := len(s.w) - len(suf)
+		if n >= from && slices.Equal(s.w[n:], suf) {
+			return i
+		}
+	}
+	return -1
+}
+
+// cut - отрезает самое длинное окончание из set в RV. Для группы 1 (after)
+// перед окончанием должна стоять "а" или "я".
+func (s *stemmer) cut(set [][]rune, after bool) bool {
+	i := s.longest(set, s.rv)
+	if i < 0 {
+		return false
+	}
+	n := len(s.w) - len(set[i])
+	if after && (n <= s.rv || (s.w[n-1] != 'а' && s.w[n-1] != 'я')) {
+		return false
+	}
+	s.w = s.w[:n]
+	return true
+}
+
+// cutGroups - отрезает окончание группы 1 или 2, выбирая более длинное совпадение
+func (s *stemmer) cutGroups(g1, g2 [][]rune) bool {
+	i1, i2 := s.longest(g1, s.rv), s.longest(g2, s.rv)
+	if i2 >= 0 && (i1 < 0 || len(g2[i2]) >= len(g1[i1])) {
+		return s.cut(g2, false)
+	}
+	return i1 >= 0 && s.cut(g1, true)
+}
+
+// Stem - основа слова. Слово должно быть в нижнем регистре; "ё" приводится к "е".
+func Stem(word string) string {
+	s := stemmer{w: []rune(strings.ReplaceAll(word, "ё", "е"))}
+	s.rv, s.r2 = len(s.w), len(s.w)
+	for i, r := range s.w {
+		if isVowel(r) {
+			s.rv = i + 1
+			break
+		}
+	}
+	r1 := len(s.w)
+	for i := 1; i < len(s.w); i++ {
+		if !isVowel(s.w[i]) && isVowel(s.w[i-1]) {
+			r1 = i + 1
+			break
+		}
+	}
+	for i := r1 + 1; i < len(s.w); i++ {
+		if !isVowel(s.w[i]) && isVowel(s.w[i-1]) {
+			s.r2 = i + 1
+			break
+		}
+	}
+
+	// Шаг 1: деепричастие или (возвратная частица) прилагательное/причастие, глагол, существительное
+	if !s.cutGroups(perfectiveGerund1, perfectiveGerund2) {
+		s.cut(reflexive, false)
+		if s.cut(adjective, false) {
+			s.cutGroups(participle1, participle2)
+		} else if !s.cutGroups(verb1, verb2) {
+			s.cut(noun, false)
+		}
+	}
+
+	// Шаг 2
+	if n := len(s.w); n > s.rv && s.w[n-1] == 'и' {
+		s.w = s.w[:n-1]
+	}
+
+	// Шаг 3: словообразовательный суффикс в R2
+	if i := s.longest(derivational, s.rv); i >= 0 && len(s.w)-len(derivational[i]) >= s.r2 {
+		s.w = s.w[:len(s.w)-len(derivational[i])]
+	}
+
+	// Шаг 4: "нн" -> "н", превосходная степень, мягкий знак
+	switch {
+	case s.cut(superlative, false):
+		s.undoubleN()
+	case s.undoubleN():
+	default:
+		if n := len(s.w); n > s.rv && s.w[n-1] == 'ь' {
+			s.w = s.w[:n-1]
+		}
+	}
+	return string(s.w)
+}
+
+func (s *stemmer) undoubleN() bool {
+	n := len(s.w)
+	if n-2 >= s.rv && s.w[n-1] == 'н' && s.w[n-2] == 'н' {
+		s.w = s.w[:n-1]
+		return true
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"bot-api/internal/auth"
+	"bot-api/internal/domain"
+	"bot-api/internal/repository"
+)
+
+// --- Методы APIKey ---
+
+// CreateAPIKey - выпускает ключ с указанными правами. Ключ в открытом виде
+// возвращается только здесь, в БД сохраняется его хеш.
+func (s *ServiceImpl) CreateAPIKey(ctx context.Context, req domain.APIKeyRequest) (domain.IssuedAPIKey, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Scopes) == 0 {
+		return domain.IssuedAPIKey{}, fmt.Errorf("service: api key needs a name and scopes: %w", invalid("name", domain.CodeRequired, "violation.api_key.name"))
+	}
+	for _, scope := range req.Scopes {
+		if !slices.Contains(auth.KnownScopes, scope) {
+			return domain.IssuedAPIKey{}, fmt.Errorf("service: unknown scope %q: %w", scope, invalid("scopes", domain.CodeInvalid, "violation.api_key.scope", scope))
+		}
+	}
+	slices.Sort(req.Scopes)
+	req.Scopes = slices.Compact(req.Scopes)
+
+	key, err := auth.GenerateAPIKey()
+	if err != nil {
+		return domain.IssuedAPIKey{}, fmt.Errorf("service: %w", err)
+	}
+
+	stored, err := s.Repo.InsertAPIKey(ctx, req, auth.HashAPIKey(key))
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return domain.IssuedAPIKey{}, fmt.Errorf("service: api key name %q is taken: %w", req.Name, ErrAlreadyExists)
+		}
+		return domain.IssuedAPIKey{}, fmt.Errorf("service: failed to insert api key: %w", err)
+	}
+	return domain.IssuedAPIKey{APIKey: stored, Key: key}, nil
+}
+
+func (s *ServiceImpl) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	keys, err := s.Repo.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (s *ServiceImpl) RevokeAPIKey(ctx context.Context, id int) error {
+	err := s.Repo.RevokeAPIKey(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("service: active api key not found for revoke: %w", ErrNotFound)
+		}
+		return fmt.Errorf("service: failed to revoke api key: %w", err)
+	}
+	return nil
+}
+
+// AuthenticateAPIKey - находит действующий ключ по его значению из запроса
+func (s *ServiceImpl) AuthenticateAPIKey(ctx context.Context, key string) (domain.APIKey, error) {
+	stored, err := s.Repo.GetAPIKeyByHash(ctx, auth.HashAPIKey(key))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.APIKey{}, fmt.Errorf("service: unknown api key: %w", ErrUnauthorized)
+		}
+		return domain.APIKey{}, fmt.Errorf("service: failed to get api key: %w", err)
+	}
+	if stored.RevokedAt != nil {
+		return domain.APIKey{}, fmt.Errorf("service: api key %d is revoked: %w", stored.ID, ErrUnauthorized)
+	}
+	return stored, nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"bot-api/internal/auth"
+	"bot-api/internal/domain"
+)
+
+// Проверки владения ресурсами. Применяются, только если в контексте есть
+// tg_id, подтвержденный подписью Telegram (см. auth.WithTgID). Бан проверяется
+// при любой авторизации: иначе бот с ключом API действовал бы за забаненного.
+
+// checkCaller - пользователь Telegram может действовать только от своего имени;
+// забаненный пользователь не может действовать вовсе
+func (s *ServiceImpl) checkCaller(ctx context.Context, tgID int64) error {
+	caller, ok := auth.TgIDFromContext(ctx)
+	if ok && caller != tgID {
+		return fmt.Errorf("service: user %d cannot act as %d: %w", caller, tgID, ErrForbidden)
+	}
+	return s.checkNotBanned(ctx, tgID)
+}
+
+// checkNotBanned - ErrUserBanned, если пользователь tgID забанен модератором.
+// Незарегистрированный пользователь не забанен.
+func (s *ServiceImpl) checkNotBanned(ctx context.Context, tgID int64) error {
+	u, err := s.Repo.GetUser(ctx, int(tgID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("service: failed to get user: %w", err)
+	}
+	return checkBan(u)
+}
+
+func checkBan(u domain.User) error {
+	if u.BannedAt != nil {
+		return fmt.Errorf("service: user %d is banned: %w", u.TgID, ErrUserBanned)
+	}
+	return nil
+}
+
+// checkAnquetteOwner - изменять анкету может только пользователь, к которому она
+// привязана; анкету забаненного пользователя не может менять никто, кроме модерации
+func (s *ServiceImpl) checkAnquetteOwner(ctx context.Context, anquetteID int) error {
+	caller, ok := auth.TgIDFromContext(ctx)
+	if !ok {
+		owner, err := s.Repo.GetUserByAnquette(ctx, anquetteID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return fmt.Errorf("service: failed to get anquette owner: %w", err)
+		}
+		return checkBan(owner)
+	}
+
+	u, err := s.Repo.GetUser(ctx, int(caller))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("service: unregistered user %d: %w", caller, ErrForbidden)
+		}
+		return fmt.Errorf("service: failed to get caller: %w", err)
+	}
+	if u.AnquetteID != anquetteID {
+		return fmt.Errorf("service: anquette %d does not belong to user %d: %w", anquetteID, caller, ErrForbidden)
+	}
+	return checkBan(u)
+}
+
+// checkAnquetteVisible - пользователь Telegram видит чужую анкету, только если
+// она активна и между ним и владельцем нет блокировки; иначе анкеты для него нет
+func (s *ServiceImpl) checkAnquetteVisible(ctx context.Context, a domain.Anquette) error {
+	caller, ok := auth.TgIDFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	owner, err := s.Repo.GetUserByAnquette(ctx, a.ID)
+	hasOwner := err == nil
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("service: failed to get anquette owner: %w", err)
+	}
+	if hasOwner && owner.TgID == caller {
+		return nil
+	}
+	if a.Status != domain.AnquetteStatusActive {
+		return fmt.Errorf("service: anquette %d is %s: %w", a.ID, a.Status, ErrNotFound)
+	}
+	if hasOwner {
+		blocked, err := s.Repo.IsBlocked(ctx, caller, owner.TgID)
+		if err != nil {
+			return fmt.Errorf("service: failed to check block: %w", err)
+		}
+		if blocked {
+			return fmt.Errorf("service: anquette %d is hidden by block: %w", a.ID, ErrNotFound)
+		}
+	}
+	return nil
+}
+
+// checkAnquetteFree - нельзя привязать к себе анкету другого пользователя
+func (s *ServiceImpl) checkAnquetteFree(ctx context.Context, anquetteID int, tgID int64) error {
+	if anquetteID == 0 {
+		return nil
+	}
+
+	owner, err := s.Repo.GetUserByAnquette(ctx, anquetteID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("service: failed to get anquette owner: %w", err)
+	}
+	if owner.TgID != tgID {
+		return fmt.Errorf("service: anquette %d belongs to user %d: %w", anquetteID, owner.TgID, ErrForbidden)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"unicode/utf8"
+
+	"bot-api/internal/domain"
+)
+
+// MaxReportNoteLength - максимальная длина комментария к жалобе (в символах)
+const MaxReportNoteLength = 1000
+
+// reportReasons - допустимые причины жалобы
+var reportReasons = []string{
+	domain.ReportReasonSpam,
+	domain.ReportReasonHarassment,
+	domain.ReportReasonFake,
+	domain.ReportReasonInappropriate,
+	domain.ReportReasonUnderage,
+	domain.ReportReasonOther,
+}
+
+// --- Методы Block ---
+
+// Block - блокирует пользователя. После блокировки пара перестает видеть
+// анкеты и мэтчи друг друга.
+func (s *ServiceImpl) Block(ctx context.Context, req domain.BlockRequest) (domain.Block, error) {
+	if err := s.checkCaller(ctx, req.TgID); err != nil {
+		return domain.Block{}, err
+	}
+
+	target, err := s.resolveTarget(ctx, req.TargetTgID, req.AnquetteID)
+	if err != nil {
+		return domain.Block{}, err
+	}
+	if target == 0 {
+		return domain.Block{}, fmt.Errorf("service: anquette %d has no owner: %w", req.AnquetteID, ErrNotFound)
+	}
+	if target == req.TgID {
+		return domain.Block{}, fmt.Errorf("service: user cannot block himself: %w", invalid("target_tg_id", domain.CodeInvalid, "violation.block.self"))
+	}
+
+	b, err := s.Repo.InsertBlock(ctx, req.TgID, target)
+	if err != nil {
+		return domain.Block{}, fmt.Errorf("service: failed to insert block: %w", err)
+	}
+	return b, nil
+}
+
+// --- Методы Report ---
+
+// Report - принимает жалобу и ставит ее в очередь модерации
+func (s *ServiceImpl) Report(ctx context.Context, req domain.ReportRequest) (domain.Report, error) {
+	if err := s.checkCaller(ctx, req.TgID); err != nil {
+		return domain.Report{}, err
+	}
+	if !slices.Contains(reportReasons, req.Reason) {
+		return domain.Report{}, fmt.Errorf("service: unknown report reason %q: %w", req.Reason, invalid("reason", domain.CodeInvalid, "violation.report.reason"))
+	}
+	if utf8.RuneCountInString(req.Note) > MaxReportNoteLength {
+		return domain.Report{}, fmt.Errorf("service: report note is too long: %w", invalid("note", domain.CodeTooLong, "violation.report.note", MaxReportNoteLength))
+	}
+
+	target, err := s.resolveTarget(ctx, req.TargetTgID, req.AnquetteID)
+	if err != nil {
+		return domain.Report{}, err
+	}
+	if target == req.TgID {
+		return domain.Report{}, fmt.Errorf("service: user cannot report himself: %w", invalid("target_tg_id", domain.CodeInvalid, "violation.report.self"))
+	}
+
+	r, err := s.Repo.InsertReport(ctx, domain.Report{
+		ReporterTgID:       req.TgID,
+		ReportedTgID:       target,
+		ReportedAnquetteID: req.AnquetteID,
+		Reason:             req.Reason,
+		Note:               req.Note,
+	})
+	if err != nil {
+		return domain.Report{}, fmt.Errorf("service: failed to insert report: %w", err)
+	}
+	return r, nil
+}
+
+// resolveTarget - tg_id пользователя, на которого направлено действие: либо
+// targetTgID, либо владелец анкеты anquetteID (0, если у анкеты нет владельца).
+func (s *ServiceImpl) resolveTarget(ctx context.Context, targetTgID int64, anquetteID int) (int64, error) {
+	if targetTgID != 0 {
+		if _, err := s.GetUser(ctx, int(targetTgID)); err != nil {
+			return 0, err
+		}
+		return targetTgID, nil
+	}
+	if anquetteID == 0 {
+		return 0, fmt.Errorf("service: target_tg_id or anquette_id is required: %w", invalid("target_tg_id", domain.CodeRequired, "violation.target.required"))
+	}
+
+	if _, err := s.getAnquette(ctx, anquetteID); err != nil {
+		return 0, err
+	}
+	owner, err := s.Repo.GetUserByAnquette(ctx, anquetteID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("service: failed to get anquette owner: %w", err)
+	}
+	return owner.TgID, nil
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"bot-api/internal/domain"
+)
+
+const (
+	defaultCitiesLimit = 10
+	maxCitiesLimit     = 50
+	// Анкет за один запрос при заполнении city_id у старых анкет
+	normalizeCitiesBatch = 100
+)
+
+// CityDirectory - справочник городов (см. gazetteer.Gazetteer)
+type CityDirectory interface {
+	Lookup(name string) (domain.City, bool)
+	Search(q string, limit int) []domain.City
+}
+
+// resolveCity - заменяет название города каноническим и выставляет CityID.
+// Нераспознанное название остается как есть, без CityID.
+func (s *ServiceImpl) resolveCity(req *domain.AnquetteRequest) {
+	req.CityID = ""
+	if s.Cities == nil {
+		return
+	}
+	if c, ok := s.Cities.Lookup(req.City); ok {
+		req.City, req.CityID = c.Name, c.ID
+	}
+}
+
+// --- Методы City ---
+
+// SearchCities - города для автодополнения по началу названия
+func (s *ServiceImpl) SearchCities(ctx context.Context, q string, limit int) ([]domain.City, error) {
+	if limit <= 0 {
+		limit = defaultCitiesLimit
+	}
+	if limit > maxCitiesLimit {
+		limit = maxCitiesLimit
+	}
+
+	cities := []domain.City{}
+	if s.Cities != nil {
+		cities = append(cities, s.Cities.Search(q, limit)...)
+	}
+	return cities, nil
+}
+
+// NormalizeCities - проставляет city_id анкетам, созданным до появления
+// справочника или с городом, которого в нем тогда не было
+func (s *ServiceImpl) NormalizeCities(ctx context.Context) error {
+	if s.Cities == nil {
+		return nil
+	}
+
+	normalized, afterID := 0, 0
+	for {
+		anquettes, err := s.Repo.ListAnquettesWithoutCityID(ctx, afterID, normalizeCitiesBatch)
+		if err != nil {
+			return fmt.Errorf("service: failed to list anquettes without city: %w", err)
+		}
+		if len(anquettes) == 0 {
+			break
+		}
+		for _, a := range anquettes {
+			afterID = a.ID
+			c, ok := s.Cities.Lookup(a.City)
+			if !ok {
+				continue
+			}
+			if err := s.Repo.SetAnquetteCity(ctx, a.ID, c.Name, c.ID); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				slog.WarnContext(ctx, "failed to normalize city of anquette", "anquette_id", a.ID, "error", err)
+				continue
+			}
+			normalized++
+		}
+	}
+
+	if normalized > 0 {
+		slog.InfoContext(ctx, "Распознаны города старых анкет", "count", normalized)
+	}
+	return nil
+}
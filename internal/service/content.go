@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"bot-api/internal/domain"
+	"bot-api/internal/textfilter"
+)
+
+// ContentFilter - автоматическая проверка текста анкеты, см. textfilter.Filter
+type ContentFilter interface {
+	Check(fields ...textfilter.Field) textfilter.Result
+}
+
+// checkContent - прогоняет имя, описание и текст предпочтений анкеты через фильтр. Возвращает
+// ValidationError, если политика требует отказа, и flagged, если анкету
+// нужно отправить на модерацию.
+func (s *ServiceImpl) checkContent(ctx context.Context, req domain.AnquetteRequest) (flagged bool, err error) {
+	if s.Filter == nil {
+		return false, nil
+	}
+
+	res := s.Filter.Check(
+		textfilter.Field{Name: "name", Text: req.Name},
+		textfilter.Field{Name: "description", Text: req.Description},
+		textfilter.Field{Name: "preferences.text", Text: req.Preferences.Text},
+	)
+	switch res.Action {
+	case textfilter.Reject:
+		var v validator
+		seen := map[textfilter.Finding]bool{}
+		for _, f := range res.Findings {
+			key := textfilter.Finding{Field: f.Field, Category: f.Category}
+			if f.Action != textfilter.Reject || seen[key] {
+				continue
+			}
+			seen[key] = true
+			v.add(f.Field, domain.CodeForbiddenContent, "violation.content."+string(f.Category))
+		}
+		return false, v.err()
+	case textfilter.Flag:
+		slog.InfoContext(ctx, "Anquette flagged for review", "findings", describeFindings(res.Findings))
+		return true, nil
+	}
+	return false, nil
+}
+
+// describeFindings - "description: link, description: phone"
+func describeFindings(findings []textfilter.Finding) string {
+	parts := make([]string, 0, len(findings))
+	for _, f := range findings {
+		parts = append(parts, f.Field+": "+string(f.Category))
+	}
+	return strings.Join(parts, ", ")
+}
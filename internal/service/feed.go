@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"bot-api/internal/domain"
+)
+
+const (
+	defaultFeedLimit = 20
+	maxFeedLimit     = 50
+	// Разброс возраста по умолчанию, если диапазон не задан явно
+	defaultFeedAgeSpread = 5
+)
+
+// --- Методы Feed ---
+
+// GetFeed - возвращает следующую пачку анкет для просмотра пользователем tgID.
+// Незаданные в f параметры берутся из анкеты зрителя: пол, возраст и расстояние -
+// из Preferences, город - из CityID или City (если не задан MaxDistanceKm),
+// возраст без предпочтений - Age ± defaultFeedAgeSpread. Анкеты, чьи Preferences
+// исключают зрителя, и анкеты со стоп-факторами зрителя не показываются.
+// Расстояние до кандидатов считается от геопозиции анкеты зрителя; без нее
+// MaxDistanceKm и FeedSortDistance недоступны. С FeedSortScore анкеты
+// упорядочивает Ranker. Interests оставляет анкеты хотя бы с одним из интересов.
+func (s *ServiceImpl) GetFeed(ctx context.Context, tgID int, f domain.FeedFilter) ([]domain.FeedItem, error) {
+	if err := s.checkCaller(ctx, int64(tgID)); err != nil {
+		return nil, err
+	}
+	ranked, err := s.feed(ctx, tgID, f)
+	if err != nil {
+		return nil, err
+	}
+	s.touchUser(ctx, int64(tgID))
+
+	feed := make([]domain.FeedItem, len(ranked))
+	for i, item := range ranked {
+		feed[i] = item.FeedItem
+	}
+	return feed, nil
+}
+
+// ExplainFeed - лента пользователя tgID в порядке ранжирования (FeedSortScore)
+// с разбором оценки каждой анкеты. Для отладки весов Ranker.
+func (s *ServiceImpl) ExplainFeed(ctx context.Context, tgID int, f domain.FeedFilter) ([]domain.RankedFeedItem, error) {
+	if err := s.checkCaller(ctx, int64(tgID)); err != nil {
+		return nil, err
+	}
+	f.Sort = domain.FeedSortScore
+	return s.feed(ctx, tgID, f)
+}
+
+func (s *ServiceImpl) feed(ctx context.Context, tgID int, f domain.FeedFilter) ([]domain.RankedFeedItem, error) {
+	if err := validateFeedFilter(&f); err != nil {
+		return nil, fmt.Errorf("service: invalid feed filter: %w", err)
+	}
+	if len(f.Interests) > 0 {
+		interests, err := s.checkInterests(ctx, "interests", f.Interests)
+		if err != nil {
+			return nil, fmt.Errorf("service: invalid feed filter: %w", err)
+		}
+		f.Interests = interests
+	}
+	s.resolveFeedCity(&f)
+	if f.Limit <= 0 {
+		f.Limit = defaultFeedLimit
+	}
+	if f.Limit > maxFeedLimit {
+		f.Limit = maxFeedLimit
+	}
+
+	u, err := s.GetUser(ctx, tgID)
+	if err != nil {
+		return nil, err
+	}
+	f.ViewerTgID = u.TgID
+	f.ExcludeAnquetteID = u.AnquetteID
+
+	var viewer domain.Anquette
+	if u.AnquetteID != 0 {
+		viewer, err = s.getAnquette(ctx, u.AnquetteID)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		if err == nil {
+			applyViewerDefaults(&f, viewer)
+			f.Origin = viewer.Location
+		}
+	}
+	if f.Origin == nil && (f.MaxDistanceKm > 0 || f.Sort == domain.FeedSortDistance) {
+		return nil, fmt.Errorf("service: feed by distance without location: %w",
+			invalid("max_distance_km", domain.CodeRequired, "violation.location.required"))
+	}
+
+	if f.Sort == domain.FeedSortScore {
+		return s.rankFeed(ctx, viewer, f)
+	}
+
+	feed, err := s.Repo.GetFeed(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get feed: %w", err)
+	}
+	ranked := make([]domain.RankedFeedItem, len(feed))
+	for i, item := range feed {
+		ranked[i].FeedItem = item
+	}
+	return ranked, nil
+}
+
+// resolveFeedCity - переводит город фильтра в CityID, если он есть в справочнике
+func (s *ServiceImpl) resolveFeedCity(f *domain.FeedFilter) {
+	if s.Cities == nil || f.CityID != "" || f.City == "" {
+		return
+	}
+	if c, ok := s.Cities.Lookup(f.City); ok {
+		f.City, f.CityID = "", c.ID
+	}
+}
+
+// applyViewerDefaults - заполняет пустые поля фильтра данными анкеты зрителя
+func applyViewerDefaults(f *domain.FeedFilter, viewer domain.Anquette) {
+	pref := viewer.Preferences
+	if f.Gender == "" && len(pref.Genders) == 1 {
+		f.Gender = pref.Genders[0]
+	}
+	if f.MaxDistanceKm == 0 && pref.MaxDistanceKm > 0 && viewer.Location != nil {
+		f.MaxDistanceKm = pref.MaxDistanceKm
+	}
+	// Поиск по расстоянию заменяет совпадение города: так видны и соседние города
+	if f.City == "" && f.CityID == "" && f.MaxDistanceKm == 0 {
+		if viewer.CityID != "" {
+			f.CityID = viewer.CityID
+		} else {
+			f.City = strings.TrimSpace(viewer.City)
+		}
+	}
+	if f.AgeMin == 0 && f.AgeMax == 0 {
+		if pref.AgeMin > 0 || pref.AgeMax > 0 {
+			f.AgeMin, f.AgeMax = pref.AgeMin, pref.AgeMax
+		} else if viewer.Age > 0 {
+			f.AgeMin = max(viewer.Age-defaultFeedAgeSpread, 1)
+			f.AgeMax = viewer.Age + defaultFeedAgeSpread
+		}
+	}
+
+	// Кандидат тоже должен искать зрителя такого пола и возраста
+	if g, ok := normalizeGender(viewer.Gender); ok {
+		f.ViewerGender = g
+	}
+	f.ViewerAge = viewer.Age
+
+	for _, d := range pref.Dealbreakers {
+		switch d {
+		case domain.DealbreakerOtherGoal:
+			if f.Goal == "" {
+				f.Goal = pref.Goal
+			}
+		case domain.DealbreakerNoPhotos:
+			f.WithPhotos = true
+		case domain.DealbreakerNoSharedInterest:
+			if len(f.Interests) == 0 {
+				f.Interests = viewer.Interests
+			}
+		}
+	}
+}
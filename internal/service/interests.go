@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"bot-api/internal/domain"
+)
+
+// MaxInterests - наибольшее число интересов в анкете и в фильтре ленты
+const MaxInterests = 10
+
+// checkInterests - убирает пробелы и повторы и проверяет ID по справочнику.
+// field - поле, к которому относятся нарушения.
+func (s *ServiceImpl) checkInterests(ctx context.Context, field string, ids []string) ([]string, error) {
+	unique := make([]string, 0, len(ids))
+	seen := map[string]bool{}
+	for _, id := range ids {
+		id = strings.ToLower(strings.TrimSpace(id))
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	if len(unique) > MaxInterests {
+		return nil, invalid(field, domain.CodeOutOfRange, "violation.interests.too_many", MaxInterests)
+	}
+	if len(unique) == 0 {
+		return unique, nil
+	}
+
+	catalog, err := s.Repo.ListInterests(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to list interests: %w", err)
+	}
+	known := make(map[string]bool, len(catalog))
+	for _, i := range catalog {
+		known[i.ID] = true
+	}
+
+	var v validator
+	for _, id := range unique {
+		if !known[id] {
+			v.add(field, domain.CodeInvalid, "violation.interests.unknown", id)
+		}
+	}
+	return unique, v.err()
+}
+
+// --- Методы Interest ---
+
+// ListInterests - справочник интересов
+func (s *ServiceImpl) ListInterests(ctx context.Context) ([]domain.Interest, error) {
+	interests, err := s.Repo.ListInterests(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to list interests: %w", err)
+	}
+	return interests, nil
+}
+
+// SetAnquetteInterests - заменяет интересы анкеты; пустой список их очищает
+func (s *ServiceImpl) SetAnquetteInterests(ctx context.Context, id int, req domain.InterestsRequest) error {
+	if err := s.checkAnquetteOwner(ctx, id); err != nil {
+		return err
+	}
+	ids, err := s.checkInterests(ctx, "interests", req.Interests)
+	if err != nil {
+		return fmt.Errorf("service: invalid interests: %w", err)
+	}
+
+	if err := s.Repo.SetAnquetteInterests(ctx, id, ids); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("service: anquette not found for interests: %w", ErrNotFound)
+		}
+		return fmt.Errorf("service: failed to set anquette interests: %w", err)
+	}
+	return nil
+}
@@ -4,9 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"bot-api/internal/domain"
+	"bot-api/internal/metrics"
 	"bot-api/internal/repository"
 
 	_ "modernc.org/sqlite" // Используем modernc.org/sqlite
@@ -14,19 +19,134 @@ import (
 
 // newTestStorage - хелпер для инициализации временной БД
 func newTestStorage(t *testing.T) *repository.Storage {
-	// Открываем in-memory SQLite (БД существует только в памяти во время выполнения)
-	db, err := sql.Open("sqlite", "file::memory:?cache=shared")
+	// Открываем in-memory SQLite (БД существует только в памяти во время выполнения).
+	// У каждого теста своя именованная БД, чтобы данные тестов не пересекались.
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name()))
 	if err != nil {
 		t.Fatalf("Не удалось открыть БД: %v", err)
 	}
+	t.Cleanup(func() { db.Close() })
 
 	storage := repository.NewStorage(db)
-	if err := storage.CreateTables(); err != nil {
-		t.Fatalf("Не удалось создать таблицы: %v", err)
+	if err := storage.Migrate(context.Background()); err != nil {
+		t.Fatalf("Не удалось применить миграции: %v", err)
 	}
 	return storage
 }
 
+// --- ТЕСТЫ MIGRATIONS ---
+
+func TestStorage_Migrate_Idempotent(t *testing.T) {
+	s := newTestStorage(t)
+
+	// Повторный запуск не должен ничего применять и падать
+	if err := s.Migrate(context.Background()); err != nil {
+		t.Fatalf("Повторный Migrate провалился: %v", err)
+	}
+	latest, err := repository.LatestSchemaVersion()
+	if err != nil {
+		t.Fatalf("Не удалось прочитать миграции: %v", err)
+	}
+	if v, err := s.SchemaVersion(context.Background()); err != nil || v != latest {
+		t.Errorf("Ожидали версию схемы %d, получили %d (%v)", latest, v, err)
+	}
+}
+
+func TestStorage_Migrate_AdoptsLegacySchema(t *testing.T) {
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name()))
+	if err != nil {
+		t.Fatalf("Не удалось открыть БД: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	// Таблица в том виде, в каком ее создавал старый CreateTables
+	_, err = db.Exec("CREATE TABLE users (tg_id INTEGER NOT NULL UNIQUE, tg_username TEXT, anquette_id INTEGER)")
+	if err != nil {
+		t.Fatalf("Не удалось создать старую таблицу: %v", err)
+	}
+	db.Exec("INSERT INTO users (tg_id, tg_username, anquette_id) VALUES (7, 'legacy', 0)")
+
+	s := repository.NewStorage(db)
+	if err := s.Migrate(context.Background()); err != nil {
+		t.Fatalf("Migrate на старой схеме провалился: %v", err)
+	}
+	if u, err := s.GetUser(context.Background(), 7); err != nil || u.TgUsername != "legacy" {
+		t.Errorf("Данные старой схемы потеряны: %+v, %v", u, err)
+	}
+}
+
+func TestStorage_Migrate_ParsesPreferences(t *testing.T) {
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name()))
+	if err != nil {
+		t.Fatalf("Не удалось открыть БД: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	// Анкеты со свободным текстом предпочтений, как до структурных Preferences
+	_, err = db.Exec(`CREATE TABLE anquettes (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL, age INTEGER NOT NULL,
+		city TEXT, gender TEXT, preferences TEXT, description TEXT NOT NULL)`)
+	if err != nil {
+		t.Fatalf("Не удалось создать старую таблицу: %v", err)
+	}
+	texts := []string{"Ж", "Ищу девушку 20-25 для серьезных отношений", "Парня или девушку для дружбы", "друзей по учёбе 17-30", "неважно",
+		"Ищу напарника для пробежек", "Ищу:парней!", "Пиши 8-912-345-67-89", "тел. 345-67-89, ищу 25-30, не 40-20"}
+	for _, text := range texts {
+		db.Exec("INSERT INTO anquettes (name, age, city, gender, preferences, description) VALUES ('Старая', 25, 'Москва', 'm', ?, 'legacy')", text)
+	}
+
+	s := repository.NewStorage(db)
+	if err := s.Migrate(context.Background()); err != nil {
+		t.Fatalf("Migrate провалился: %v", err)
+	}
+
+	want := []domain.Preferences{
+		{Genders: []string{"f"}},
+		{Genders: []string{"f"}, AgeMin: 20, AgeMax: 25, Goal: domain.GoalDating},
+		{Genders: []string{"f", "m"}, Goal: domain.GoalFriendship},
+		{Goal: domain.GoalStudyBuddy}, // 17 лет - не диапазон для знакомств
+		{},
+		{},                       // «напарник» - не «парень»
+		{Genders: []string{"m"}}, // слово среди знаков препинания
+		{},                       // номер телефона - не возраст
+		{AgeMin: 25, AgeMax: 30}, // первый правдоподобный диапазон после телефона
+	}
+	for i, w := range want {
+		w.Text = texts[i] // исходный текст сохраняется
+		a, err := s.GetAnquette(context.Background(), i+1)
+		if err != nil {
+			t.Fatalf("GetAnquette провалился: %v", err)
+		}
+		if fmt.Sprint(a.Preferences) != fmt.Sprint(w) {
+			t.Errorf("%q: ожидали %+v, получили %+v", texts[i], w, a.Preferences)
+		}
+	}
+
+	// Старые анкеты попадают в полнотекстовый индекс
+	if hits, _ := s.SearchAnquettes(context.Background(), domain.SearchFilter{Query: "legacy", Limit: 10}); len(hits) != len(texts) {
+		t.Errorf("Ожидали найти %d старых анкет, нашли %d", len(texts), len(hits))
+	}
+}
+
+func TestStorage_Migrate_RefusesNewerSchema(t *testing.T) {
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name()))
+	if err != nil {
+		t.Fatalf("Не удалось открыть БД: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	s := repository.NewStorage(db)
+	if err := s.Migrate(context.Background()); err != nil {
+		t.Fatalf("Migrate провалился: %v", err)
+	}
+
+	// Имитируем БД, обновленную более новой версией бинарника
+	db.Exec("INSERT INTO schema_migrations (version, name) VALUES (9999, 'future')")
+
+	if err := s.Migrate(context.Background()); !errors.Is(err, repository.ErrSchemaTooNew) {
+		t.Errorf("Ожидали repository.ErrSchemaTooNew, получили %v", err)
+	}
+}
+
 // --- ТЕСТЫ USER ---
 
 func TestStorage_InsertAndGetUser_Success(t *testing.T) {
@@ -67,8 +187,8 @@ func TestStorage_UpdateUser_Success(t *testing.T) {
 		t.Fatalf("UpdateUser провалился: %v", err)
 	}
 
-	// 3. Проверка
-	updatedUser, _ := s.GetUser(ctx, id)
+	// 3. Проверка (tg_id тоже обновился, ищем по новому)
+	updatedUser, _ := s.GetUser(ctx, int(updateReq.TgID))
 	if updatedUser.TgUsername != "new_name" {
 		t.Errorf("Username не был обновлен")
 	}
@@ -113,3 +233,872 @@ func TestStorage_DeleteAnquette_NotFound(t *testing.T) {
 		t.Errorf("Ожидали sql.ErrNoRows, получили %v", err)
 	}
 }
+
+// --- ТЕСТЫ FEED ---
+
+func TestStorage_GetFeed_Filters(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	own, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Я", Age: 20, City: "Moscow", Gender: "m", Description: "own"})
+	match, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Подходит", Age: 21, City: "moscow", Gender: "F", Description: "ok"})
+	s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Другой город", Age: 21, City: "Kazan", Gender: "f", Description: "city"})
+	s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Старше", Age: 40, City: "Moscow", Gender: "f", Description: "age"})
+	s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Другой пол", Age: 21, City: "Moscow", Gender: "m", Description: "gender"})
+
+	feed, err := s.GetFeed(ctx, domain.FeedFilter{
+		Gender: "f", City: "Moscow", AgeMin: 18, AgeMax: 25, ExcludeAnquetteID: own, Limit: 10,
+	})
+	if err != nil {
+		t.Fatalf("GetFeed провалился: %v", err)
+	}
+
+	if len(feed) != 1 || feed[0].ID != match {
+		t.Errorf("Ожидали только анкету %d, получили %+v", match, feed)
+	}
+}
+
+func TestStorage_GetFeed_Cursor(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	var ids []int
+	for i := 0; i < 3; i++ {
+		id, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Анкета", Age: 20, Description: "cursor"})
+		ids = append(ids, id)
+	}
+
+	feed, err := s.GetFeed(ctx, domain.FeedFilter{AfterID: ids[0], Limit: 1})
+	if err != nil {
+		t.Fatalf("GetFeed провалился: %v", err)
+	}
+
+	if len(feed) != 1 || feed[0].ID != ids[1] {
+		t.Errorf("Ожидали анкету %d после курсора, получили %+v", ids[1], feed)
+	}
+}
+
+func TestStorage_GetFeed_Distance(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	place := func(name string, p *domain.GeoPoint) int {
+		id, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: name, Age: 20, Description: "geo"})
+		if p != nil {
+			if err := s.SetAnquetteLocation(ctx, id, p); err != nil {
+				t.Fatalf("SetAnquetteLocation провалился: %v", err)
+			}
+		}
+		return id
+	}
+	anapa := place("Анапа", &domain.GeoPoint{Lat: 44.89, Lon: 37.32})
+	gelendzhik := place("Геленджик", &domain.GeoPoint{Lat: 44.56, Lon: 38.08})
+	krasnodar := place("Краснодар", &domain.GeoPoint{Lat: 45.04, Lon: 38.98})
+	nowhere := place("Без геопозиции", nil)
+	origin := &domain.GeoPoint{Lat: 44.72, Lon: 37.77} // Новороссийск
+
+	// Радиус: только ближние анкеты с координатами, по возрастанию ID
+	feed, err := s.GetFeed(ctx, domain.FeedFilter{Origin: origin, MaxDistanceKm: 50, Limit: 10})
+	if err != nil {
+		t.Fatalf("GetFeed провалился: %v", err)
+	}
+	if len(feed) != 2 || feed[0].ID != anapa || feed[1].ID != gelendzhik {
+		t.Fatalf("Ожидали анкеты %d и %d, получили %+v", anapa, gelendzhik, feed)
+	}
+	if d := feed[1].DistanceKm; d < 25 || d > 35 {
+		t.Errorf("Ожидали около 30 км до Геленджика, получили %d", d)
+	}
+
+	// Сортировка по расстоянию с курсором (расстояние, ID)
+	first, _ := s.GetFeed(ctx, domain.FeedFilter{Origin: origin, Sort: domain.FeedSortDistance, Limit: 1})
+	if len(first) != 1 || first[0].ID != gelendzhik {
+		t.Fatalf("Ожидали ближайшую анкету %d, получили %+v", gelendzhik, first)
+	}
+	rest, _ := s.GetFeed(ctx, domain.FeedFilter{
+		Origin: origin, Sort: domain.FeedSortDistance, AfterDistanceKm: first[0].DistanceKm, AfterID: first[0].ID, Limit: 10,
+	})
+	if len(rest) != 2 || rest[0].ID != anapa || rest[1].ID != krasnodar {
+		t.Errorf("Ожидали анкеты %d и %d после курсора, получили %+v", anapa, krasnodar, rest)
+	}
+
+	// Без ограничений анкеты без координат остаются, но без расстояния
+	all, _ := s.GetFeed(ctx, domain.FeedFilter{Origin: origin, Limit: 10})
+	if len(all) != 4 || all[3].ID != nowhere || all[3].DistanceKm != 0 || all[3].Location != nil {
+		t.Errorf("Ожидали анкету %d без расстояния, получили %+v", nowhere, all)
+	}
+}
+
+func TestStorage_GetFeed_Preferences(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	insert := func(name string, p domain.Preferences) int {
+		id, err := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: name, Age: 25, Gender: "f", Description: "prefs", Preferences: p})
+		if err != nil {
+			t.Fatalf("InsertAnquette провалился: %v", err)
+		}
+		return id
+	}
+	anyone := insert("Любой", domain.Preferences{})
+	dating := insert("Отношения", domain.Preferences{Genders: []string{"m"}, AgeMin: 25, AgeMax: 35, Goal: domain.GoalDating, Dealbreakers: []string{"no_photos"}, Text: "Серьезные"})
+	insert("Только девушки", domain.Preferences{Genders: []string{"f"}})
+	insert("Постарше", domain.Preferences{AgeMin: 40})
+	s.InsertPhoto(ctx, domain.Photo{AnquetteID: dating, StorageKey: "d.jpg", ContentType: "image/jpeg"})
+
+	a, _ := s.GetAnquette(ctx, dating)
+	if a.Preferences.Goal != domain.GoalDating || len(a.Preferences.Genders) != 1 || a.Preferences.Dealbreakers[0] != "no_photos" || a.Preferences.Text != "Серьезные" {
+		t.Errorf("Предпочтения не сохранились: %+v", a.Preferences)
+	}
+
+	// Показываются только те, кто ищет зрителя такого пола и возраста
+	feed, err := s.GetFeed(ctx, domain.FeedFilter{ViewerGender: "m", ViewerAge: 30, Limit: 10})
+	if err != nil {
+		t.Fatalf("GetFeed провалился: %v", err)
+	}
+	if len(feed) != 2 || feed[0].ID != anyone || feed[1].ID != dating {
+		t.Errorf("Ожидали анкеты %d и %d, получили %+v", anyone, dating, feed)
+	}
+
+	if feed, _ := s.GetFeed(ctx, domain.FeedFilter{Goal: domain.GoalDating, WithPhotos: true, Limit: 10}); len(feed) != 1 || feed[0].ID != dating {
+		t.Errorf("Ожидали только анкету %d, получили %+v", dating, feed)
+	}
+}
+
+func TestStorage_GetFeed_CityID(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	spb, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Из справочника", Age: 20, City: "Санкт-Петербург", CityID: "saint-petersburg", Description: "city"})
+	legacy, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Старая", Age: 20, City: "Питер", Description: "city"})
+	s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Москва", Age: 20, City: "Москва", CityID: "moscow", Description: "city"})
+
+	feed, err := s.GetFeed(ctx, domain.FeedFilter{CityID: "saint-petersburg", Limit: 10})
+	if err != nil {
+		t.Fatalf("GetFeed провалился: %v", err)
+	}
+	if len(feed) != 1 || feed[0].ID != spb || feed[0].CityID != "saint-petersburg" {
+		t.Fatalf("Ожидали только анкету %d, получили %+v", spb, feed)
+	}
+
+	// Старые анкеты без city_id находятся и получают город из справочника
+	pending, err := s.ListAnquettesWithoutCityID(ctx, 0, 10)
+	if err != nil {
+		t.Fatalf("ListAnquettesWithoutCityID провалился: %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != legacy {
+		t.Fatalf("Ожидали анкету %d без city_id, получили %+v", legacy, pending)
+	}
+	if err := s.SetAnquetteCity(ctx, legacy, "Санкт-Петербург", "saint-petersburg"); err != nil {
+		t.Fatalf("SetAnquetteCity провалился: %v", err)
+	}
+	if feed, _ := s.GetFeed(ctx, domain.FeedFilter{CityID: "saint-petersburg", Limit: 10}); len(feed) != 2 {
+		t.Errorf("Ожидали 2 анкеты после нормализации, получили %+v", feed)
+	}
+	if err := s.SetAnquetteCity(ctx, 999, "x", "y"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Ожидали sql.ErrNoRows, получили %v", err)
+	}
+}
+
+func TestStorage_GetFeed_ScoreStats(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	idle, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Давно не заходила", Age: 20, Description: "idle"})
+	active, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Активная", Age: 20, Description: "active"})
+	s.InsertUser(ctx, domain.UserRequest{TgID: 1, AnquetteID: idle})
+	s.InsertUser(ctx, domain.UserRequest{TgID: 2, AnquetteID: active})
+	s.InsertPhoto(ctx, domain.Photo{AnquetteID: active, StorageKey: "a.jpg", ContentType: "image/jpeg"})
+
+	if err := s.TouchUser(ctx, 2); err != nil {
+		t.Fatalf("TouchUser провалился: %v", err)
+	}
+	if err := s.TouchUser(ctx, 999); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Ожидали sql.ErrNoRows для неизвестного пользователя, получили %v", err)
+	}
+	s.InsertReaction(ctx, domain.ReactionRequest{TgID: 2, AnquetteID: idle, Kind: domain.ReactionLike})
+
+	// Для ранжирования кандидаты идут от недавно активных, с данными о владельце
+	feed, err := s.GetFeed(ctx, domain.FeedFilter{ViewerTgID: 3, Sort: domain.FeedSortScore, Limit: 10})
+	if err != nil {
+		t.Fatalf("GetFeed провалился: %v", err)
+	}
+	if len(feed) != 2 || feed[0].ID != active || feed[1].ID != idle {
+		t.Fatalf("Ожидали сначала активную анкету %d, получили %+v", active, feed)
+	}
+	st := feed[0].Stats
+	if st.PhotoCount != 1 || st.LastActiveAt == nil || st.LikesGiven != 1 || st.ReactionsGiven != 1 {
+		t.Errorf("Неверные данные для ранжирования: %+v", st)
+	}
+	if feed[1].Stats.LastActiveAt != nil {
+		t.Errorf("У неактивного пользователя нет времени активности: %+v", feed[1].Stats)
+	}
+}
+
+// --- ТЕСТЫ INTEREST ---
+
+func TestStorage_Interests_SetFilterAndDelete(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	catalog, err := s.ListInterests(ctx)
+	if err != nil {
+		t.Fatalf("ListInterests провалился: %v", err)
+	}
+	if len(catalog) < 20 || catalog[0].ID != "music" || catalog[0].Name != "Музыка" {
+		t.Fatalf("Ожидали справочник из миграции, получили %+v", catalog)
+	}
+
+	musician, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Музыкант", Age: 20, Description: "music"})
+	coder, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Программист", Age: 20, Description: "it"})
+	s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Без интересов", Age: 20, Description: "none"})
+
+	if err := s.SetAnquetteInterests(ctx, musician, []string{"travel", "music"}); err != nil {
+		t.Fatalf("SetAnquetteInterests провалился: %v", err)
+	}
+	s.SetAnquetteInterests(ctx, coder, []string{"it", "music"})
+	// Повторная установка заменяет список целиком
+	s.SetAnquetteInterests(ctx, coder, []string{"it"})
+	if err := s.SetAnquetteInterests(ctx, 999, []string{"it"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Ожидали sql.ErrNoRows для несуществующей анкеты, получили %v", err)
+	}
+
+	a, _ := s.GetAnquette(ctx, musician)
+	if len(a.Interests) != 2 || a.Interests[0] != "music" || a.Interests[1] != "travel" {
+		t.Errorf("Ожидали интересы по алфавиту, получили %v", a.Interests)
+	}
+
+	feed, err := s.GetFeed(ctx, domain.FeedFilter{Interests: []string{"music", "cooking"}, Limit: 10})
+	if err != nil {
+		t.Fatalf("GetFeed провалился: %v", err)
+	}
+	if len(feed) != 1 || feed[0].ID != musician || len(feed[0].Interests) != 2 {
+		t.Errorf("Ожидали только анкету %d с интересами, получили %+v", musician, feed)
+	}
+
+	// Интересы удаляются вместе с анкетой
+	s.DeleteAnquette(ctx, musician)
+	if feed, _ := s.GetFeed(ctx, domain.FeedFilter{Interests: []string{"travel"}, Limit: 10}); len(feed) != 0 {
+		t.Errorf("Ожидали пустую ленту после удаления анкеты, получили %+v", feed)
+	}
+}
+
+// --- ТЕСТЫ LISTS ---
+
+// collectAnquettes - все страницы ListAnquettes подряд; возвращает ID и число страниц
+func collectAnquettes(t *testing.T, s *repository.Storage, f domain.ListFilter) ([]int, int) {
+	t.Helper()
+	var ids []int
+	for pages := 1; ; pages++ {
+		page, err := s.ListAnquettes(context.Background(), f)
+		if err != nil {
+			t.Fatalf("ListAnquettes провалился: %v", err)
+		}
+		for _, a := range page.Items {
+			ids = append(ids, a.ID)
+		}
+		if page.NextCursor == "" {
+			return ids, pages
+		}
+		f.Cursor = page.NextCursor
+	}
+}
+
+func TestStorage_ListAnquettes_PagesFiltersAndSort(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	var ids []int
+	for _, a := range []domain.AnquetteRequest{
+		{Name: "А", Age: 30, City: "Москва", Gender: "f", Description: "a"},
+		{Name: "Б", Age: 20, City: "Москва", Gender: "m", Description: "b"},
+		{Name: "В", Age: 25, City: "Казань", Gender: "f", Description: "c"},
+		{Name: "Г", Age: 20, City: "Москва", Gender: "f", Description: "d"},
+		{Name: "Д", Age: 40, City: "Москва", Gender: "f", Description: "e"},
+	} {
+		id, _ := s.InsertAnquette(ctx, a)
+		ids = append(ids, id)
+	}
+
+	// По возрасту, при равном возрасте - по ID
+	got, pages := collectAnquettes(t, s, domain.ListFilter{Sort: domain.ListSortAge, Limit: 2})
+	if want := []int{ids[1], ids[3], ids[2], ids[0], ids[4]}; !slices.Equal(got, want) || pages != 3 {
+		t.Errorf("Ожидали %v за 3 страницы, получили %v за %d", want, got, pages)
+	}
+
+	// Новые первыми, с фильтрами
+	got, _ = collectAnquettes(t, s, domain.ListFilter{City: "Москва", Gender: "f", AgeMax: 35, Sort: "-" + domain.ListSortCreated, Limit: 1})
+	if want := []int{ids[3], ids[0]}; !slices.Equal(got, want) {
+		t.Errorf("Ожидали %v, получили %v", want, got)
+	}
+
+	a, _ := s.GetAnquette(ctx, ids[0])
+	if a.CreatedAt == nil {
+		t.Fatalf("Ожидали created_at у новой анкеты")
+	}
+	before, after := a.CreatedAt.Add(-time.Hour), a.CreatedAt.Add(time.Hour)
+	if got, _ := collectAnquettes(t, s, domain.ListFilter{CreatedAfter: &before, Sort: domain.ListSortCreated, Limit: 10}); len(got) != 5 {
+		t.Errorf("Ожидали все анкеты, созданные после %v, получили %v", before, got)
+	}
+	if got, _ := collectAnquettes(t, s, domain.ListFilter{CreatedAfter: &after, Sort: domain.ListSortCreated, Limit: 10}); len(got) != 0 {
+		t.Errorf("Ожидали пустой список после %v, получили %v", after, got)
+	}
+
+	// Курсор другой сортировки или мусор не принимаются
+	page, _ := s.ListAnquettes(ctx, domain.ListFilter{Sort: domain.ListSortAge, Limit: 1})
+	for _, cursor := range []string{page.NextCursor, "мусор"} {
+		_, err := s.ListAnquettes(ctx, domain.ListFilter{Sort: domain.ListSortCreated, Cursor: cursor, Limit: 1})
+		if !errors.Is(err, repository.ErrInvalidCursor) {
+			t.Errorf("Ожидали ErrInvalidCursor для %q, получили %v", cursor, err)
+		}
+	}
+}
+
+func TestStorage_ListUsers_ByAnquetteAndLastActive(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	moscow, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "А", Age: 25, City: "Москва", Gender: "f", Description: "a"})
+	kazan, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Б", Age: 25, City: "Казань", Gender: "f", Description: "b"})
+	s.InsertUser(ctx, domain.UserRequest{TgID: 1, AnquetteID: moscow})
+	s.InsertUser(ctx, domain.UserRequest{TgID: 2, AnquetteID: kazan})
+	s.InsertUser(ctx, domain.UserRequest{TgID: 3})
+	s.TouchUser(ctx, 2)
+
+	page, err := s.ListUsers(ctx, domain.ListFilter{City: "Москва", Sort: domain.ListSortCreated, Limit: 10})
+	if err != nil {
+		t.Fatalf("ListUsers провалился: %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].TgID != 1 || page.Items[0].CreatedAt == nil {
+		t.Errorf("Ожидали только пользователя 1 с created_at, получили %+v", page.Items)
+	}
+
+	// Недавно активные первыми; курсор с ключом-строкой переживает кодирование
+	var got []int64
+	f := domain.ListFilter{Sort: "-" + domain.ListSortLastActive, Limit: 1}
+	for {
+		page, err := s.ListUsers(ctx, f)
+		if err != nil {
+			t.Fatalf("ListUsers провалился: %v", err)
+		}
+		for _, u := range page.Items {
+			got = append(got, u.TgID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		f.Cursor = page.NextCursor
+	}
+	if want := []int64{2, 3, 1}; !slices.Equal(got, want) {
+		t.Errorf("Ожидали %v, получили %v", want, got)
+	}
+}
+
+// --- ТЕСТЫ SEARCH ---
+
+func TestStorage_SearchAnquettes_HidesBlocked(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	mine, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Моя", Age: 20, Description: "Люблю музыку"})
+	blocked, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Чужая", Age: 20, Description: "Тоже люблю музыку"})
+	s.InsertUser(ctx, domain.UserRequest{TgID: 1, AnquetteID: mine})
+	s.InsertUser(ctx, domain.UserRequest{TgID: 2, AnquetteID: blocked})
+	s.InsertBlock(ctx, 2, 1)
+
+	hits, err := s.SearchAnquettes(ctx, domain.SearchFilter{Query: "музыка", ViewerTgID: 1, Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchAnquettes провалился: %v", err)
+	}
+	if len(hits) != 1 || hits[0].ID != mine {
+		t.Errorf("Ожидали только свою анкету %d, получили %+v", mine, hits)
+	}
+	// Без зрителя блокировки не учитываются
+	if hits, _ := s.SearchAnquettes(ctx, domain.SearchFilter{Query: "музыка", Limit: 10}); len(hits) != 2 {
+		t.Errorf("Ожидали обе анкеты, получили %+v", hits)
+	}
+}
+
+func TestStorage_SearchAnquettes_SyncRankAndSnippet(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	musician, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Музыкант", Age: 20, Description: "Играю музыку <b>каждый</b> день, люблю музыкальные фестивали"})
+	traveler, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Аня", Age: 20, Description: "Путешествия, горы и немного музыки"})
+	s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Ёжик", Age: 20, Description: "Собираю ёлочные игрушки"})
+
+	hits, err := s.SearchAnquettes(ctx, domain.SearchFilter{Query: "музыка", Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchAnquettes провалился: %v", err)
+	}
+	if len(hits) != 2 || hits[0].ID != musician || hits[1].ID != traveler || hits[0].Rank <= hits[1].Rank {
+		t.Fatalf("Ожидали анкеты %d и %d по убыванию релевантности, получили %+v", musician, traveler, hits)
+	}
+	if want := "Играю <mark>музыку</mark> &lt;b&gt;каждый&lt;/b&gt;"; !strings.HasPrefix(hits[0].Snippet, want) {
+		t.Errorf("Ожидали фрагмент с выделением и экранированием, получили %q", hits[0].Snippet)
+	}
+
+	// "ё" и "е" не различаются, все слова запроса обязательны
+	if hits, _ := s.SearchAnquettes(ctx, domain.SearchFilter{Query: "ежик елочный", Limit: 10}); len(hits) != 1 {
+		t.Errorf("Ожидали анкету с ё, получили %+v", hits)
+	}
+	if hits, _ := s.SearchAnquettes(ctx, domain.SearchFilter{Query: "музыка горы море", Limit: 10}); len(hits) != 0 {
+		t.Errorf("Ожидали пустой результат, получили %+v", hits)
+	}
+
+	// Индекс следует за изменениями анкет
+	s.UpdateAnquette(ctx, traveler, domain.AnquetteRequest{Name: "Аня", Age: 20, Description: "Только горы"})
+	s.DeleteAnquette(ctx, musician)
+	if hits, _ := s.SearchAnquettes(ctx, domain.SearchFilter{Query: "музыка", Limit: 10}); len(hits) != 0 {
+		t.Errorf("Ожидали пустой результат после изменений, получили %+v", hits)
+	}
+
+	s.SetAnquetteStatus(ctx, traveler, domain.AnquetteStatusHidden)
+	if hits, _ := s.SearchAnquettes(ctx, domain.SearchFilter{Query: "горы", Status: domain.AnquetteStatusActive, Limit: 10}); len(hits) != 0 {
+		t.Errorf("Ожидали, что скрытая анкета не найдется среди активных, получили %+v", hits)
+	}
+	if hits, _ := s.SearchAnquettes(ctx, domain.SearchFilter{Query: "горы", Limit: 10}); len(hits) != 1 {
+		t.Errorf("Ожидали скрытую анкету без фильтра статуса, получили %+v", hits)
+	}
+}
+
+// --- ТЕСТЫ REACTION ---
+
+func TestStorage_InsertReaction_DuplicateAndFeed(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	rated, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Оцененная", Age: 20, Description: "rated"})
+	fresh, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Новая", Age: 20, Description: "fresh"})
+
+	req := domain.ReactionRequest{TgID: 1, AnquetteID: rated, Kind: domain.ReactionDislike}
+	if _, err := s.InsertReaction(ctx, req); err != nil {
+		t.Fatalf("InsertReaction провалился: %v", err)
+	}
+
+	// Повторная реакция на ту же анкету запрещена
+	if _, err := s.InsertReaction(ctx, req); !errors.Is(err, repository.ErrDuplicate) {
+		t.Errorf("Ожидали repository.ErrDuplicate, получили %v", err)
+	}
+
+	// Оцененная анкета пропадает из ленты зрителя
+	feed, _ := s.GetFeed(ctx, domain.FeedFilter{ViewerTgID: 1, Limit: 10})
+	if len(feed) != 1 || feed[0].ID != fresh {
+		t.Errorf("Ожидали только анкету %d, получили %+v", fresh, feed)
+	}
+}
+
+// --- ТЕСТЫ MATCH ---
+
+// insertMatch - мэтч пользователей tgID1 и tgID2 через взаимные лайки анкет
+// tgID * 10 (анкеты для этого создавать не нужно)
+func insertMatch(t *testing.T, s *repository.Storage, tgID1, tgID2 int64) domain.Match {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := s.InsertReaction(ctx, domain.ReactionRequest{TgID: tgID2, AnquetteID: int(tgID1 * 10), Kind: domain.ReactionLike}); err != nil {
+		t.Fatalf("InsertReaction провалился: %v", err)
+	}
+	like := domain.ReactionRequest{TgID: tgID1, AnquetteID: int(tgID2 * 10), Kind: domain.ReactionLike}
+	_, m, err := s.InsertReactionAndMatch(ctx, like, tgID2, int(tgID1*10))
+	if err != nil || m == nil {
+		t.Fatalf("Ожидали мэтч %d-%d, получили %+v (%v)", tgID1, tgID2, m, err)
+	}
+	return *m
+}
+
+func TestStorage_InsertReactionAndMatch_SamePair(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	first := insertMatch(t, s, 20, 10)
+	if first.UserTgID1 != 10 || first.UserTgID2 != 20 {
+		t.Errorf("Ожидали упорядоченную пару 10-20, получили %+v", first)
+	}
+
+	// Лайк другой анкеты той же пары возвращает существующий мэтч
+	like := domain.ReactionRequest{TgID: 20, AnquetteID: 101, Kind: domain.ReactionLike}
+	_, second, err := s.InsertReactionAndMatch(ctx, like, 10, 200)
+	if err != nil || second == nil || second.ID != first.ID {
+		t.Errorf("Ожидали мэтч %d, получили %+v (%v)", first.ID, second, err)
+	}
+}
+
+func TestStorage_InsertReactionAndMatch_Atomic(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	s.InsertReaction(ctx, domain.ReactionRequest{TgID: 2, AnquetteID: 10, Kind: domain.ReactionLike})
+
+	// Мэтч создать не удалось - лайк тоже не сохраняется, повтор проходит заново
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name()))
+	if err != nil {
+		t.Fatalf("Не удалось открыть БД: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	db.Exec("ALTER TABLE matches RENAME TO matches_broken")
+	like := domain.ReactionRequest{TgID: 1, AnquetteID: 20, Kind: domain.ReactionLike}
+	if _, _, err := s.InsertReactionAndMatch(ctx, like, 2, 10); err == nil {
+		t.Fatal("Ожидали ошибку создания мэтча")
+	}
+	db.Exec("ALTER TABLE matches_broken RENAME TO matches")
+
+	reaction, match, err := s.InsertReactionAndMatch(ctx, like, 2, 10)
+	if err != nil {
+		t.Fatalf("InsertReactionAndMatch провалился: %v", err)
+	}
+	if reaction.ID == 0 || match == nil || match.UserTgID1 != 1 || match.UserTgID2 != 2 {
+		t.Errorf("Ожидали реакцию и мэтч 1-2, получили %+v, %+v", reaction, match)
+	}
+	if _, _, err := s.InsertReactionAndMatch(ctx, like, 2, 10); !errors.Is(err, repository.ErrDuplicate) {
+		t.Errorf("Ожидали ErrDuplicate, получили %v", err)
+	}
+
+	// Без встречного лайка мэтча нет
+	_, match, err = s.InsertReactionAndMatch(ctx, domain.ReactionRequest{TgID: 3, AnquetteID: 20, Kind: domain.ReactionLike}, 2, 30)
+	if err != nil || match != nil {
+		t.Errorf("Не ожидали мэтч, получили %+v (%v)", match, err)
+	}
+}
+
+func TestStorage_ListMatches_NewestFirstWithPartner(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	ank, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Партнер", Age: 22, Description: "partner"})
+	s.InsertUser(ctx, domain.UserRequest{TgID: 1, TgUsername: "me"})
+	s.InsertUser(ctx, domain.UserRequest{TgID: 2, TgUsername: "partner", AnquetteID: ank})
+	s.InsertUser(ctx, domain.UserRequest{TgID: 3, TgUsername: "no_anquette"})
+
+	older := insertMatch(t, s, 1, 2)
+	newer := insertMatch(t, s, 3, 1)
+
+	matches, err := s.ListMatches(ctx, 1, 0, 10)
+	if err != nil {
+		t.Fatalf("ListMatches провалился: %v", err)
+	}
+	if len(matches) != 2 || matches[0].ID != newer.ID || matches[1].ID != older.ID {
+		t.Fatalf("Ожидали мэтчи %d и %d от новых к старым, получили %+v", newer.ID, older.ID, matches)
+	}
+	if matches[0].Partner.TgUsername != "no_anquette" || matches[0].Partner.Anquette != nil {
+		t.Errorf("Неверный партнер без анкеты: %+v", matches[0].Partner)
+	}
+	if p := matches[1].Partner; p.TgID != 2 || p.Anquette == nil || p.Anquette.Name != "Партнер" {
+		t.Errorf("Неверный партнер с анкетой: %+v", p)
+	}
+
+	// Курсор отдает только более старые мэтчи
+	page, _ := s.ListMatches(ctx, 1, newer.ID, 10)
+	if len(page) != 1 || page[0].ID != older.ID {
+		t.Errorf("Ожидали только мэтч %d, получили %+v", older.ID, page)
+	}
+}
+
+func TestStorage_DeleteMatch(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	m := insertMatch(t, s, 1, 2)
+
+	if err := s.DeleteMatch(ctx, m.ID); err != nil {
+		t.Fatalf("DeleteMatch провалился: %v", err)
+	}
+	if err := s.DeleteMatch(ctx, m.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Ожидали sql.ErrNoRows при повторном удалении, получили %v", err)
+	}
+}
+
+// --- ТЕСТЫ BLOCK / REPORT ---
+
+func TestStorage_Block_HidesFeedAndMatches(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	mine, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Моя", Age: 20, Description: "mine"})
+	theirs, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Чужая", Age: 20, Description: "theirs"})
+	s.InsertUser(ctx, domain.UserRequest{TgID: 1, AnquetteID: mine})
+	s.InsertUser(ctx, domain.UserRequest{TgID: 2, AnquetteID: theirs})
+	insertMatch(t, s, 1, 2)
+
+	if _, err := s.InsertBlock(ctx, 2, 1); err != nil {
+		t.Fatalf("InsertBlock провалился: %v", err)
+	}
+	// Повторная блокировка не ошибка
+	if _, err := s.InsertBlock(ctx, 2, 1); err != nil {
+		t.Fatalf("Повторный InsertBlock провалился: %v", err)
+	}
+
+	if blocked, _ := s.IsBlocked(ctx, 1, 2); !blocked {
+		t.Error("Ожидали блокировку в обратную сторону")
+	}
+	// Заблокированный не видит блокирующего ни в ленте, ни в мэтчах
+	feed, _ := s.GetFeed(ctx, domain.FeedFilter{ViewerTgID: 1, ExcludeAnquetteID: mine, Limit: 10})
+	if len(feed) != 0 {
+		t.Errorf("Ожидали пустую ленту, получили %+v", feed)
+	}
+	matches, _ := s.ListMatches(ctx, 2, 0, 10)
+	if len(matches) != 0 {
+		t.Errorf("Ожидали пустой список мэтчей, получили %+v", matches)
+	}
+}
+
+func TestStorage_Block_UserWithoutAnquette(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	mine, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Моя", Age: 20, Description: "mine"})
+	other, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Другая", Age: 20, Description: "other"})
+	s.InsertUser(ctx, domain.UserRequest{TgID: 1, AnquetteID: mine})
+	s.InsertUser(ctx, domain.UserRequest{TgID: 3, AnquetteID: other})
+	// У заблокированного нет анкеты: anquette_id - NULL, как у пользователей, заведенных ботом
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name()))
+	if err != nil {
+		t.Fatalf("Не удалось открыть БД: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec("INSERT INTO users (tg_id, anquette_id, created_at) VALUES (2, NULL, CURRENT_TIMESTAMP)"); err != nil {
+		t.Fatalf("Не удалось добавить пользователя без анкеты: %v", err)
+	}
+
+	if _, err := s.InsertBlock(ctx, 1, 2); err != nil {
+		t.Fatalf("InsertBlock провалился: %v", err)
+	}
+
+	feed, err := s.GetFeed(ctx, domain.FeedFilter{ViewerTgID: 1, ExcludeAnquetteID: mine, Limit: 10})
+	if err != nil {
+		t.Fatalf("GetFeed провалился: %v", err)
+	}
+	if len(feed) != 1 || feed[0].ID != other {
+		t.Errorf("Ожидали в ленте только анкету %d, получили %+v", other, feed)
+	}
+}
+
+func TestStorage_InsertReport_Pending(t *testing.T) {
+	s := newTestStorage(t)
+
+	r, err := s.InsertReport(context.Background(), domain.Report{ReporterTgID: 1, ReportedTgID: 2, Reason: domain.ReportReasonSpam, Note: "реклама"})
+
+	if err != nil {
+		t.Fatalf("InsertReport провалился: %v", err)
+	}
+	if r.ID == 0 || r.Status != domain.ReportStatusPending || r.Note != "реклама" {
+		t.Errorf("Неверная жалоба: %+v", r)
+	}
+}
+
+// --- ТЕСТЫ MODERATION ---
+
+func TestStorage_Moderation_RejectAndBan(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	bad, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Спам", Age: 20, Description: "spam"})
+	good, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Норм", Age: 20, Description: "ok"})
+	s.InsertUser(ctx, domain.UserRequest{TgID: 2, AnquetteID: bad})
+	rep, _ := s.InsertReport(ctx, domain.Report{ReporterTgID: 1, ReportedTgID: 2, ReportedAnquetteID: bad, Reason: domain.ReportReasonSpam})
+	if err := s.SetAnquetteStatus(ctx, good, domain.AnquetteStatusPending); err != nil {
+		t.Fatalf("SetAnquetteStatus провалился: %v", err)
+	}
+
+	queue, _ := s.ListAnquettesByStatus(ctx, domain.AnquetteStatusPending, 0, 10)
+	if len(queue) != 1 || queue[0].ID != good {
+		t.Errorf("Ожидали на модерации только анкету %d, получили %+v", good, queue)
+	}
+
+	d, err := s.ApplyModerationDecision(ctx, domain.ModerationDecision{
+		Action: domain.ModerationBan, ReportID: rep.ID, AnquetteID: bad, TgID: 2, Moderator: "admin",
+	}, domain.AnquetteStatusHidden)
+	if err != nil {
+		t.Fatalf("ApplyModerationDecision провалился: %v", err)
+	}
+	if d.ID == 0 || d.Moderator != "admin" {
+		t.Errorf("Неверная запись журнала: %+v", d)
+	}
+
+	// Жалоба закрыта, пользователь забанен, анкета скрыта из ленты
+	if r, _ := s.GetReport(ctx, rep.ID); r.Status != domain.ReportStatusResolved {
+		t.Errorf("Ожидали закрытую жалобу, получили %+v", r)
+	}
+	if reports, _ := s.ListPendingReports(ctx, 0, 10); len(reports) != 0 {
+		t.Errorf("Ожидали пустую очередь жалоб, получили %+v", reports)
+	}
+	if u, _ := s.GetUser(ctx, 2); u.BannedAt == nil {
+		t.Error("Ожидали отметку о бане")
+	}
+	if a, _ := s.GetAnquette(ctx, bad); a.Status != domain.AnquetteStatusHidden {
+		t.Errorf("Ожидали скрытую анкету, получили %+v", a)
+	}
+	if feed, _ := s.GetFeed(ctx, domain.FeedFilter{Limit: 10}); len(feed) != 0 {
+		t.Errorf("Ожидали пустую ленту, получили %+v", feed)
+	}
+}
+
+func TestStorage_LastRejectionReason(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	id, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Анкета", Age: 20, Description: "text"})
+	if reason, err := s.LastRejectionReason(ctx, id); err != nil || reason != "" {
+		t.Errorf("Ожидали пустую причину, получили %q, %v", reason, err)
+	}
+	for _, reason := range []string{"первый отказ", "второй отказ"} {
+		if _, err := s.ApplyModerationDecision(ctx, domain.ModerationDecision{
+			Action: domain.ModerationReject, AnquetteID: id, Reason: reason,
+		}, domain.AnquetteStatusRejected); err != nil {
+			t.Fatalf("ApplyModerationDecision провалился: %v", err)
+		}
+	}
+	if reason, err := s.LastRejectionReason(ctx, id); err != nil || reason != "второй отказ" {
+		t.Errorf("Ожидали причину последнего отказа, получили %q, %v", reason, err)
+	}
+}
+
+func TestStorage_Moderation_ApproveKeepsHidden(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	hidden, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Скрытая", Age: 20, Description: "hidden"})
+	pending, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "На модерации", Age: 20, Description: "pending"})
+	s.SetAnquetteStatus(ctx, hidden, domain.AnquetteStatusHidden)
+	s.SetAnquetteStatus(ctx, pending, domain.AnquetteStatusPending)
+	rep, _ := s.InsertReport(ctx, domain.Report{ReporterTgID: 1, ReportedAnquetteID: hidden, Reason: domain.ReportReasonSpam})
+
+	for _, d := range []domain.ModerationDecision{
+		{Action: domain.ModerationApprove, ReportID: rep.ID, AnquetteID: hidden},
+		{Action: domain.ModerationApprove, AnquetteID: pending},
+	} {
+		if _, err := s.ApplyModerationDecision(ctx, d, domain.AnquetteStatusActive); err != nil {
+			t.Fatalf("ApplyModerationDecision провалился: %v", err)
+		}
+	}
+
+	// Жалоба закрыта, но скрытая анкета в ленту не вернулась
+	if r, _ := s.GetReport(ctx, rep.ID); r.Status != domain.ReportStatusResolved {
+		t.Errorf("Ожидали закрытую жалобу, получили %+v", r)
+	}
+	if a, _ := s.GetAnquette(ctx, hidden); a.Status != domain.AnquetteStatusHidden {
+		t.Errorf("Ожидали, что анкета останется скрытой, получили %+v", a)
+	}
+	if a, _ := s.GetAnquette(ctx, pending); a.Status != domain.AnquetteStatusActive {
+		t.Errorf("Ожидали одобренную анкету, получили %+v", a)
+	}
+}
+
+// --- ТЕСТЫ API KEY ---
+
+func TestStorage_APIKeys_InsertAndRevoke(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	req := domain.APIKeyRequest{Name: "bot-1", Scopes: []string{"users:read", "users:write"}}
+	k, err := s.InsertAPIKey(ctx, req, "hash-1")
+	if err != nil {
+		t.Fatalf("InsertAPIKey провалился: %v", err)
+	}
+	if len(k.Scopes) != 2 || k.RevokedAt != nil {
+		t.Errorf("Неверный сохраненный ключ: %+v", k)
+	}
+
+	// Имя ключа уникально
+	if _, err := s.InsertAPIKey(ctx, req, "hash-2"); !errors.Is(err, repository.ErrDuplicate) {
+		t.Errorf("Ожидали repository.ErrDuplicate, получили %v", err)
+	}
+
+	if err := s.RevokeAPIKey(ctx, k.ID); err != nil {
+		t.Fatalf("RevokeAPIKey провалился: %v", err)
+	}
+	revoked, _ := s.GetAPIKeyByHash(ctx, "hash-1")
+	if revoked.RevokedAt == nil {
+		t.Error("Ожидали отметку об отзыве ключа")
+	}
+	if err := s.RevokeAPIKey(ctx, k.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Ожидали sql.ErrNoRows при повторном отзыве, получили %v", err)
+	}
+}
+
+// --- ТЕСТЫ PHOTO ---
+
+func TestStorage_Photos_PositionsDeleteAndReorder(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	var ids []int
+	for i := 0; i < 3; i++ {
+		p, err := s.InsertPhoto(ctx, domain.Photo{AnquetteID: 1, StorageKey: fmt.Sprintf("k%d", i), ContentType: "image/jpeg", Size: 1})
+		if err != nil {
+			t.Fatalf("InsertPhoto провалился: %v", err)
+		}
+		if p.Position != i {
+			t.Errorf("Ожидали позицию %d, получили %d", i, p.Position)
+		}
+		ids = append(ids, p.ID)
+	}
+
+	// После удаления первого фото позиции сдвигаются без пропусков
+	if err := s.DeletePhoto(ctx, ids[0]); err != nil {
+		t.Fatalf("DeletePhoto провалился: %v", err)
+	}
+	if err := s.ReorderPhotos(ctx, 1, []int{ids[2], ids[1]}); err != nil {
+		t.Fatalf("ReorderPhotos провалился: %v", err)
+	}
+
+	photos, _ := s.ListPhotos(ctx, 1)
+	if len(photos) != 2 || photos[0].ID != ids[2] || photos[1].ID != ids[1] || photos[1].Position != 1 {
+		t.Errorf("Неверный порядок фото: %+v", photos)
+	}
+}
+
+func TestStorage_Photos_Variants(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	p, err := s.InsertPhoto(ctx, domain.Photo{
+		AnquetteID: 1, StorageKey: "k.jpg", ContentType: "image/jpeg", Size: 100, Width: 1200, Height: 800,
+		Variants: []domain.PhotoVariant{
+			{Name: "thumb", StorageKey: "k_thumb.jpg", ContentType: "image/jpeg", Size: 10, Width: 320, Height: 320},
+			{Name: "medium", StorageKey: "k_medium.jpg", ContentType: "image/jpeg", Size: 50, Width: 1080, Height: 720},
+		},
+	})
+	if err != nil {
+		t.Fatalf("InsertPhoto провалился: %v", err)
+	}
+
+	got, err := s.GetPhoto(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("GetPhoto провалился: %v", err)
+	}
+	if got.Width != 1200 || len(got.Variants) != 2 || got.Variants[0].Name != "medium" {
+		t.Errorf("Неверное фото с копиями: %+v", got)
+	}
+
+	// Старые фото без размеров попадают в выборку на дообработку
+	legacy, _ := s.InsertPhoto(ctx, domain.Photo{AnquetteID: 1, StorageKey: "old.jpg", ContentType: "image/jpeg", Size: 1})
+	pending, _ := s.ListUnprocessedPhotos(ctx, 0, 10)
+	if len(pending) != 1 || pending[0].ID != legacy.ID {
+		t.Errorf("Ожидали на дообработку только фото %d, получили %+v", legacy.ID, pending)
+	}
+}
+
+// --- ТЕСТЫ METRICS ---
+
+func TestStorage_RegisterMetrics(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	active, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Активная", Age: 20, Description: "metrics"})
+	hidden, _ := s.InsertAnquette(ctx, domain.AnquetteRequest{Name: "Скрытая", Age: 20, Description: "metrics"})
+	s.SetAnquetteStatus(ctx, hidden, domain.AnquetteStatusHidden)
+	s.InsertUser(ctx, domain.UserRequest{TgID: 1, AnquetteID: active})
+	s.InsertUser(ctx, domain.UserRequest{TgID: 2, AnquetteID: hidden})
+	insertMatch(t, s, 1, 2)
+
+	reg := metrics.NewRegistry()
+	s.RegisterMetrics(reg)
+	var b strings.Builder
+	if err := reg.WriteTo(ctx, &b); err != nil {
+		t.Fatalf("WriteTo провалился: %v", err)
+	}
+	for _, want := range []string{"dating_users 2", "dating_anquettes_active 1", "dating_matches_last_24h 1", "db_open_connections "} {
+		if !strings.Contains(b.String(), "\n"+want) {
+			t.Errorf("Нет строки %q в метриках:\n%s", want, b.String())
+		}
+	}
+}
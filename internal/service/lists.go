@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"bot-api/internal/domain"
+	"bot-api/internal/repository"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+)
+
+// Допустимые ListFilter.Sort без "-"
+var (
+	userListSorts     = []string{domain.ListSortCreated, domain.ListSortLastActive}
+	anquetteListSorts = []string{domain.ListSortCreated, domain.ListSortAge}
+)
+
+// prepareListFilter - проверяет фильтр списка и заполняет значения по умолчанию
+func (s *ServiceImpl) prepareListFilter(f *domain.ListFilter, sorts []string) error {
+	var v validator
+
+	if f.AgeMin < 0 || f.AgeMax < 0 || f.AgeMin > MaxAge || f.AgeMax > MaxAge {
+		v.add("age_min", domain.CodeOutOfRange, "violation.age.out_of_range", 0, MaxAge)
+	} else if f.AgeMax > 0 && f.AgeMin > f.AgeMax {
+		v.add("age_max", domain.CodeOutOfRange, "violation.age_max.out_of_range")
+	}
+	if f.Gender != "" {
+		if g, ok := normalizeGender(f.Gender); ok {
+			f.Gender = g
+		} else {
+			v.add("gender", domain.CodeInvalid, "violation.gender.invalid")
+		}
+	}
+	if f.Sort == "" {
+		f.Sort = domain.ListSortCreated
+	}
+	if !slices.Contains(sorts, strings.TrimPrefix(f.Sort, "-")) {
+		v.add("sort", domain.CodeInvalid, "violation.sort.invalid", strings.Join(sorts, ", "))
+	}
+	if err := v.err(); err != nil {
+		return err
+	}
+
+	if f.Limit <= 0 {
+		f.Limit = defaultListLimit
+	}
+	if f.Limit > maxListLimit {
+		f.Limit = maxListLimit
+	}
+	f.City = strings.TrimSpace(f.City)
+	if s.Cities != nil && f.CityID == "" && f.City != "" {
+		if c, ok := s.Cities.Lookup(f.City); ok {
+			f.City, f.CityID = "", c.ID
+		}
+	}
+	return nil
+}
+
+// listError - ошибка репозитория при чтении списка; неверный курсор - ошибка валидации
+func listError(err error) error {
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return invalid("cursor", domain.CodeInvalid, "violation.cursor.malformed")
+	}
+	return err
+}
+
+// --- Методы списков (админка, аналитика) ---
+
+// ListUsers - страница пользователей по фильтру
+func (s *ServiceImpl) ListUsers(ctx context.Context, f domain.ListFilter) (domain.Page[domain.User], error) {
+	if err := s.prepareListFilter(&f, userListSorts); err != nil {
+		return domain.Page[domain.User]{}, fmt.Errorf("service: invalid users filter: %w", err)
+	}
+	page, err := s.Repo.ListUsers(ctx, f)
+	if err != nil {
+		return domain.Page[domain.User]{}, fmt.Errorf("service: failed to list users: %w", listError(err))
+	}
+	return page, nil
+}
+
+// ListAnquettes - страница анкет любого статуса по фильтру
+func (s *ServiceImpl) ListAnquettes(ctx context.Context, f domain.ListFilter) (domain.Page[domain.Anquette], error) {
+	if err := s.prepareListFilter(&f, anquetteListSorts); err != nil {
+		return domain.Page[domain.Anquette]{}, fmt.Errorf("service: invalid anquettes filter: %w", err)
+	}
+	page, err := s.Repo.ListAnquettes(ctx, f)
+	if err != nil {
+		return domain.Page[domain.Anquette]{}, fmt.Errorf("service: failed to list anquettes: %w", listError(err))
+	}
+	return page, nil
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+
+	"bot-api/internal/domain"
+)
+
+// locationPrecision - координаты хранятся с точностью до 0.01° (около 1 км),
+// чтобы по расстояниям в ленте нельзя было вычислить точное место
+const locationPrecision = 100
+
+// roundCoord - округляет координату до locationPrecision
+func roundCoord(c float64) float64 {
+	return math.Round(c*locationPrecision) / locationPrecision
+}
+
+// --- Методы Location ---
+
+// SetAnquetteLocation - сохраняет геопозицию анкеты (например, из сообщения
+// Telegram с location) с точностью locationPrecision
+func (s *ServiceImpl) SetAnquetteLocation(ctx context.Context, id int, req domain.LocationRequest) error {
+	if err := s.checkAnquetteOwner(ctx, id); err != nil {
+		return err
+	}
+	if err := validateLocation(req); err != nil {
+		return fmt.Errorf("service: invalid location: %w", err)
+	}
+
+	p := &domain.GeoPoint{Lat: roundCoord(req.Latitude), Lon: roundCoord(req.Longitude)}
+	if err := s.Repo.SetAnquetteLocation(ctx, id, p); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("service: anquette not found for location: %w", ErrNotFound)
+		}
+		return fmt.Errorf("service: failed to set anquette location: %w", err)
+	}
+	return nil
+}
+
+// ClearAnquetteLocation - удаляет геопозицию анкеты
+func (s *ServiceImpl) ClearAnquetteLocation(ctx context.Context, id int) error {
+	if err := s.checkAnquetteOwner(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.Repo.SetAnquetteLocation(ctx, id, nil); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("service: anquette not found for location: %w", ErrNotFound)
+		}
+		return fmt.Errorf("service: failed to clear anquette location: %w", err)
+	}
+	return nil
+}
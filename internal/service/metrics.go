@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"bot-api/internal/domain"
+	"bot-api/internal/metrics"
+)
+
+// errorKindCanceled - вызывающий отменил запрос (например, клиент закрыл соединение)
+const errorKindCanceled = "canceled"
+
+// ErrorKind - вид ошибки сервиса: тот же код, который получит клиент API
+// (domain.ErrCode*); все, что не доменная ошибка, - domain.ErrCodeInternal.
+func ErrorKind(err error) string {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return domain.ErrCodeNotFound
+	case errors.Is(err, ErrValidationFailed):
+		return domain.ErrCodeValidation
+	case errors.Is(err, ErrAlreadyExists):
+		return domain.ErrCodeAlreadyExists
+	case errors.Is(err, ErrUserBanned):
+		return domain.ErrCodeUserBanned
+	case errors.Is(err, ErrForbidden):
+		return domain.ErrCodeForbidden
+	case errors.Is(err, ErrUnauthorized):
+		return domain.ErrCodeUnauthorized
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return errorKindCanceled
+	}
+	return domain.ErrCodeInternal
+}
+
+// instrumented - UserService, который считает ошибки каждого метода next по видам
+type instrumented struct {
+	next   UserService
+	errors *metrics.Counter
+}
+
+// Instrumented - оборачивает svc подсчетом ошибок методов в reg
+func Instrumented(svc UserService, reg *metrics.Registry) UserService {
+	return &instrumented{
+		next: svc,
+		errors: reg.NewCounter("service_errors_total",
+			"Service method errors by method and domain error kind.", "method", "kind"),
+	}
+}
+
+// observe - учитывает ошибку err метода method и возвращает ее
+func (s *instrumented) observe(method string, err error) error {
+	if err != nil {
+		s.errors.Inc(method, ErrorKind(err))
+	}
+	return err
+}
+
+func (s *instrumented) InsertUser(ctx context.Context, req domain.UserRequest) (int, error) {
+	id, err := s.next.InsertUser(ctx, req)
+	return id, s.observe("InsertUser", err)
+}
+
+func (s *instrumented) GetUser(ctx context.Context, id int) (domain.User, error) {
+	u, err := s.next.GetUser(ctx, id)
+	return u, s.observe("GetUser", err)
+}
+
+func (s *instrumented) UpdateUser(ctx context.Context, id int, req domain.UserRequest) error {
+	return s.observe("UpdateUser", s.next.UpdateUser(ctx, id, req))
+}
+
+func (s *instrumented) InsertAnquette(ctx context.Context, req domain.AnquetteRequest) (int, error) {
+	id, err := s.next.InsertAnquette(ctx, req)
+	return id, s.observe("InsertAnquette", err)
+}
+
+func (s *instrumented) GetAnquette(ctx context.Context, id int) (domain.Anquette, error) {
+	a, err := s.next.GetAnquette(ctx, id)
+	return a, s.observe("GetAnquette", err)
+}
+
+func (s *instrumented) UpdateAnquette(ctx context.Context, id int, req domain.AnquetteRequest) error {
+	return s.observe("UpdateAnquette", s.next.UpdateAnquette(ctx, id, req))
+}
+
+func (s *instrumented) DeleteAnquette(ctx context.Context, id int) error {
+	return s.observe("DeleteAnquette", s.next.DeleteAnquette(ctx, id))
+}
+
+func (s *instrumented) SetAnquetteLocation(ctx context.Context, id int, req domain.LocationRequest) error {
+	return s.observe("SetAnquetteLocation", s.next.SetAnquetteLocation(ctx, id, req))
+}
+
+func (s *instrumented) ClearAnquetteLocation(ctx context.Context, id int) error {
+	return s.observe("ClearAnquetteLocation", s.next.ClearAnquetteLocation(ctx, id))
+}
+
+func (s *instrumented) SetAnquetteInterests(ctx context.Context, id int, req domain.InterestsRequest) error {
+	return s.observe("SetAnquetteInterests", s.next.SetAnquetteInterests(ctx, id, req))
+}
+
+func (s *instrumented) ListInterests(ctx context.Context) ([]domain.Interest, error) {
+	items, err := s.next.ListInterests(ctx)
+	return items, s.observe("ListInterests", err)
+}
+
+func (s *instrumented) GetFeed(ctx context.Context, tgID int, f domain.FeedFilter) ([]domain.FeedItem, error) {
+	items, err := s.next.GetFeed(ctx, tgID, f)
+	return items, s.observe("GetFeed", err)
+}
+
+func (s *instrumented) ListUsers(ctx context.Context, f domain.ListFilter) (domain.Page[domain.User], error) {
+	page, err := s.next.ListUsers(ctx, f)
+	return page, s.observe("ListUsers", err)
+}
+
+func (s *instrumented) ListAnquettes(ctx context.Context, f domain.ListFilter) (domain.Page[domain.Anquette], error) {
+	page, err := s.next.ListAnquettes(ctx, f)
+	return page, s.observe("ListAnquettes", err)
+}
+
+func (s *instrumented) SearchAnquettes(ctx context.Context, f domain.SearchFilter) ([]domain.SearchHit, error) {
+	hits, err := s.next.SearchAnquettes(ctx, f)
+	return hits, s.observe("SearchAnquettes", err)
+}
+
+func (s *instrumented) ExplainFeed(ctx context.Context, tgID int, f domain.FeedFilter) ([]domain.RankedFeedItem, error) {
+	items, err := s.next.ExplainFeed(ctx, tgID, f)
+	return items, s.observe("ExplainFeed", err)
+}
+
+func (s *instrumented) SearchCities(ctx context.Context, q string, limit int) ([]domain.City, error) {
+	cities, err := s.next.SearchCities(ctx, q, limit)
+	return cities, s.observe("SearchCities", err)
+}
+
+func (s *instrumented) React(ctx context.Context, fromTgID int64, toAnquetteID int, kind string) (domain.ReactionResult, error) {
+	res, err := s.next.React(ctx, fromTgID, toAnquetteID, kind)
+	return res, s.observe("React", err)
+}
+
+func (s *instrumented) ListMatches(ctx context.Context, tgID int, beforeID, limit int) ([]domain.MatchView, error) {
+	matches, err := s.next.ListMatches(ctx, tgID, beforeID, limit)
+	return matches, s.observe("ListMatches", err)
+}
+
+func (s *instrumented) DeleteMatch(ctx context.Context, id int) error {
+	return s.observe("DeleteMatch", s.next.DeleteMatch(ctx, id))
+}
+
+func (s *instrumented) Block(ctx context.Context, req domain.BlockRequest) (domain.Block, error) {
+	b, err := s.next.Block(ctx, req)
+	return b, s.observe("Block", err)
+}
+
+func (s *instrumented) Report(ctx context.Context, req domain.ReportRequest) (domain.Report, error) {
+	r, err := s.next.Report(ctx, req)
+	return r, s.observe("Report", err)
+}
+
+func (s *instrumented) ModerationQueue(ctx context.Context, afterReportID, afterAnquetteID, limit int) (domain.ModerationQueue, error) {
+	q, err := s.next.ModerationQueue(ctx, afterReportID, afterAnquetteID, limit)
+	return q, s.observe("ModerationQueue", err)
+}
+
+func (s *instrumented) Moderate(ctx context.Context, req domain.ModerationRequest) (domain.ModerationDecision, error) {
+	d, err := s.next.Moderate(ctx, req)
+	return d, s.observe("Moderate", err)
+}
+
+func (s *instrumented) UploadPhoto(ctx context.Context, anquetteID int, data []byte) (domain.Photo, error) {
+	p, err := s.next.UploadPhoto(ctx, anquetteID, data)
+	return p, s.observe("UploadPhoto", err)
+}
+
+func (s *instrumented) ListPhotos(ctx context.Context, anquetteID int) ([]domain.Photo, error) {
+	photos, err := s.next.ListPhotos(ctx, anquetteID)
+	return photos, s.observe("ListPhotos", err)
+}
+
+func (s *instrumented) OpenPhoto(ctx context.Context, id int, variant string) (domain.PhotoVariant, io.ReadCloser, error) {
+	v, rc, err := s.next.OpenPhoto(ctx, id, variant)
+	return v, rc, s.observe("OpenPhoto", err)
+}
+
+func (s *instrumented) DeletePhoto(ctx context.Context, anquetteID, photoID int) error {
+	return s.observe("DeletePhoto", s.next.DeletePhoto(ctx, anquetteID, photoID))
+}
+
+func (s *instrumented) ReorderPhotos(ctx context.Context, anquetteID int, photoIDs []int) error {
+	return s.observe("ReorderPhotos", s.next.ReorderPhotos(ctx, anquetteID, photoIDs))
+}
+
+func (s *instrumented) CreateAPIKey(ctx context.Context, req domain.APIKeyRequest) (domain.IssuedAPIKey, error) {
+	k, err := s.next.CreateAPIKey(ctx, req)
+	return k, s.observe("CreateAPIKey", err)
+}
+
+func (s *instrumented) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	keys, err := s.next.ListAPIKeys(ctx)
+	return keys, s.observe("ListAPIKeys", err)
+}
+
+func (s *instrumented) RevokeAPIKey(ctx context.Context, id int) error {
+	return s.observe("RevokeAPIKey", s.next.RevokeAPIKey(ctx, id))
+}
+
+func (s *instrumented) AuthenticateAPIKey(ctx context.Context, key string) (domain.APIKey, error) {
+	k, err := s.next.AuthenticateAPIKey(ctx, key)
+	return k, s.observe("AuthenticateAPIKey", err)
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"bot-api/internal/auth"
+	"bot-api/internal/domain"
+)
+
+const (
+	defaultModerationLimit = 50
+	maxModerationLimit     = 200
+)
+
+// moderationStatuses - в какой статус решение переводит анкету. Approve
+// переводит только анкету на модерации (см. ApplyModerationDecision).
+var moderationStatuses = map[string]string{
+	domain.ModerationApprove: domain.AnquetteStatusActive,
+	domain.ModerationReject:  domain.AnquetteStatusRejected,
+	domain.ModerationBan:     domain.AnquetteStatusHidden,
+}
+
+// --- Методы Moderation ---
+
+// ModerationQueue - жалобы и анкеты, ожидающие решения, от старых к новым.
+// Курсоры afterReportID и afterAnquetteID листают каждую очередь отдельно.
+func (s *ServiceImpl) ModerationQueue(ctx context.Context, afterReportID, afterAnquetteID, limit int) (domain.ModerationQueue, error) {
+	if afterReportID < 0 || afterAnquetteID < 0 {
+		return domain.ModerationQueue{}, fmt.Errorf("service: invalid moderation cursor: %w", invalid("after", domain.CodeInvalid, "violation.cursor.invalid"))
+	}
+	if limit <= 0 {
+		limit = defaultModerationLimit
+	}
+	if limit > maxModerationLimit {
+		limit = maxModerationLimit
+	}
+
+	reports, err := s.Repo.ListPendingReports(ctx, afterReportID, limit)
+	if err != nil {
+		return domain.ModerationQueue{}, fmt.Errorf("service: failed to list reports: %w", err)
+	}
+	anquettes, err := s.Repo.ListAnquettesByStatus(ctx, domain.AnquetteStatusPending, afterAnquetteID, limit)
+	if err != nil {
+		return domain.ModerationQueue{}, fmt.Errorf("service: failed to list pending anquettes: %w", err)
+	}
+	return domain.ModerationQueue{Reports: reports, Anquettes: anquettes}, nil
+}
+
+// Moderate - применяет решение модератора:
+//   - approve - анкета с модерации становится активной, жалобы закрываются;
+//     отклоненная или скрытая анкета остается как есть;
+//   - reject - анкета отклоняется с причиной и пропадает из ленты;
+//   - ban - пользователь банится, его анкета скрывается.
+//
+// Решение записывается в журнал вместе с именем ключа API модератора.
+func (s *ServiceImpl) Moderate(ctx context.Context, req domain.ModerationRequest) (domain.ModerationDecision, error) {
+	anquetteStatus, ok := moderationStatuses[req.Action]
+	if !ok {
+		return domain.ModerationDecision{}, fmt.Errorf("service: unknown moderation action %q: %w", req.Action, invalid("action", domain.CodeInvalid, "violation.moderation.action"))
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Action == domain.ModerationReject && req.Reason == "" {
+		return domain.ModerationDecision{}, fmt.Errorf("service: reject needs a reason: %w", invalid("reason", domain.CodeRequired, "violation.moderation.reason"))
+	}
+
+	d, err := s.moderationTarget(ctx, req)
+	if err != nil {
+		return domain.ModerationDecision{}, err
+	}
+	if req.Action == domain.ModerationReject && d.AnquetteID == 0 {
+		return domain.ModerationDecision{}, fmt.Errorf("service: nothing to reject: %w", invalid("anquette_id", domain.CodeRequired, "violation.moderation.anquette"))
+	}
+	if req.Action == domain.ModerationBan && d.TgID == 0 {
+		return domain.ModerationDecision{}, fmt.Errorf("service: nobody to ban: %w", invalid("tg_id", domain.CodeRequired, "violation.moderation.owner"))
+	}
+	if key, ok := auth.APIKeyFromContext(ctx); ok {
+		d.Moderator = key.Name
+	}
+
+	out, err := s.Repo.ApplyModerationDecision(ctx, d, anquetteStatus)
+	if err != nil {
+		return domain.ModerationDecision{}, fmt.Errorf("service: failed to apply moderation decision: %w", err)
+	}
+	return out, nil
+}
+
+// moderationTarget - определяет жалобу, анкету и пользователя, которых касается решение.
+// Берется первое заданное из ReportID, AnquetteID, TgID; остальное достраивается по БД.
+func (s *ServiceImpl) moderationTarget(ctx context.Context, req domain.ModerationRequest) (domain.ModerationDecision, error) {
+	d := domain.ModerationDecision{Action: req.Action, Reason: req.Reason}
+
+	switch {
+	case req.ReportID != 0:
+		r, err := s.Repo.GetReport(ctx, req.ReportID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return d, fmt.Errorf("service: report not found: %w", ErrNotFound)
+			}
+			return d, fmt.Errorf("service: failed to get report: %w", err)
+		}
+		d.ReportID, d.TgID, d.AnquetteID = r.ID, r.ReportedTgID, r.ReportedAnquetteID
+		if d.AnquetteID == 0 && d.TgID != 0 {
+			if u, err := s.Repo.GetUser(ctx, int(d.TgID)); err == nil {
+				d.AnquetteID = u.AnquetteID
+			} else if !errors.Is(err, sql.ErrNoRows) {
+				return d, fmt.Errorf("service: failed to get reported user: %w", err)
+			}
+		}
+	case req.AnquetteID != 0:
+		if _, err := s.getAnquette(ctx, req.AnquetteID); err != nil {
+			return d, err
+		}
+		d.AnquetteID = req.AnquetteID
+		owner, err := s.Repo.GetUserByAnquette(ctx, req.AnquetteID)
+		if err == nil {
+			d.TgID = owner.TgID
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return d, fmt.Errorf("service: failed to get anquette owner: %w", err)
+		}
+	case req.TgID != 0:
+		u, err := s.GetUser(ctx, int(req.TgID))
+		if err != nil {
+			return d, err
+		}
+		d.TgID, d.AnquetteID = u.TgID, u.AnquetteID
+	default:
+		return d, fmt.Errorf("service: report_id, anquette_id or tg_id is required: %w", invalid("report_id", domain.CodeRequired, "violation.moderation.target"))
+	}
+	return d, nil
+}
@@ -0,0 +1,327 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"bot-api/internal/domain"
+	"bot-api/internal/imaging"
+	"bot-api/internal/storage"
+)
+
+const (
+	MaxPhotosPerAnquette = 6
+	MaxPhotoSize         = 10 << 20 // 10 МБ
+)
+
+// Размер пачки при дообработке старых фото
+const pendingPhotosBatch = 20
+
+// photoTypes - допустимые типы загружаемых фото (по содержимому файла)
+var photoTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+// photoExtensions - расширения файлов, в которых хранятся обработанные фото
+var photoExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+// withURLs - проставляет ссылки на файл фото и его копии
+func withURLs(p domain.Photo) domain.Photo {
+	p.URL = fmt.Sprintf("/api/v1/photos/%d", p.ID)
+	for i := range p.Variants {
+		p.Variants[i].URL = fmt.Sprintf("/api/v1/photos/%d?variant=%s", p.ID, p.Variants[i].Name)
+	}
+	return p
+}
+
+// photoKeys - ключи BlobStore всех файлов фото
+func photoKeys(p domain.Photo) []string {
+	keys := []string{p.StorageKey}
+	for _, v := range p.Variants {
+		keys = append(keys, v.StorageKey)
+	}
+	return keys
+}
+
+// storeProcessed - обрабатывает фото (удаление EXIF, превью, миниатюра)
+// и сохраняет все файлы в BlobStore. Возвращает фото без ID и позиции.
+func (s *ServiceImpl) storeProcessed(ctx context.Context, anquetteID int, data []byte) (domain.Photo, error) {
+	res, err := imaging.Process(data)
+	if err != nil {
+		if errors.Is(err, imaging.ErrUnsupportedImage) || errors.Is(err, imaging.ErrImageTooLarge) {
+			return domain.Photo{}, fmt.Errorf("service: %v: %w", err, invalid("photo", domain.CodeInvalid, "violation.photo.unreadable"))
+		}
+		return domain.Photo{}, fmt.Errorf("service: failed to process photo: %w", err)
+	}
+
+	name := make([]byte, 16)
+	if _, err := rand.Read(name); err != nil {
+		return domain.Photo{}, fmt.Errorf("service: failed to generate photo name: %w", err)
+	}
+	base := fmt.Sprintf("anquettes/%d/%s", anquetteID, hex.EncodeToString(name))
+
+	p := domain.Photo{
+		AnquetteID:  anquetteID,
+		StorageKey:  base + photoExtensions[res.Original.ContentType],
+		ContentType: res.Original.ContentType,
+		Size:        int64(len(res.Original.Data)),
+		Width:       res.Original.Width,
+		Height:      res.Original.Height,
+	}
+	files := map[string][]byte{p.StorageKey: res.Original.Data}
+	for _, v := range res.Variants {
+		key := base + "_" + v.Name + photoExtensions[v.ContentType]
+		files[key] = v.Data
+		p.Variants = append(p.Variants, domain.PhotoVariant{
+			Name: v.Name, ContentType: v.ContentType, Size: int64(len(v.Data)),
+			Width: v.Width, Height: v.Height, StorageKey: key,
+		})
+	}
+
+	for key, data := range files {
+		if err := s.Blobs.Put(ctx, key, bytes.NewReader(data)); err != nil {
+			s.deleteBlobs(ctx, photoKeys(p))
+			return domain.Photo{}, fmt.Errorf("service: failed to store photo: %w", err)
+		}
+	}
+	return p, nil
+}
+
+// --- Методы Photo ---
+
+// UploadPhoto - проверяет тип и размер файла, очищает его от метаданных,
+// готовит уменьшенные копии и добавляет фото в конец списка фото анкеты
+func (s *ServiceImpl) UploadPhoto(ctx context.Context, anquetteID int, data []byte) (domain.Photo, error) {
+	if err := s.checkAnquetteOwner(ctx, anquetteID); err != nil {
+		return domain.Photo{}, err
+	}
+	if len(data) == 0 || len(data) > MaxPhotoSize {
+		return domain.Photo{}, fmt.Errorf("service: photo size %d is out of range: %w", len(data), invalid("photo", domain.CodeOutOfRange, "violation.photo.size", MaxPhotoSize>>20))
+	}
+	contentType := http.DetectContentType(data)
+	if !photoTypes[contentType] {
+		return domain.Photo{}, fmt.Errorf("service: unsupported photo type %q: %w", contentType, invalid("photo", domain.CodeInvalid, "violation.photo.type"))
+	}
+
+	if _, err := s.getAnquette(ctx, anquetteID); err != nil {
+		return domain.Photo{}, err
+	}
+	existing, err := s.Repo.ListPhotos(ctx, anquetteID)
+	if err != nil {
+		return domain.Photo{}, fmt.Errorf("service: failed to list photos: %w", err)
+	}
+	if len(existing) >= MaxPhotosPerAnquette {
+		return domain.Photo{}, fmt.Errorf("service: anquette already has %d photos: %w", len(existing), invalid("photo", domain.CodeOutOfRange, "violation.photo.limit", MaxPhotosPerAnquette))
+	}
+
+	processed, err := s.storeProcessed(ctx, anquetteID, data)
+	if err != nil {
+		return domain.Photo{}, err
+	}
+	p, err := s.Repo.InsertPhoto(ctx, processed)
+	if err != nil {
+		s.deleteBlobs(ctx, photoKeys(processed))
+		return domain.Photo{}, fmt.Errorf("service: failed to insert photo: %w", err)
+	}
+	return withURLs(p), nil
+}
+
+func (s *ServiceImpl) ListPhotos(ctx context.Context, anquetteID int) ([]domain.Photo, error) {
+	if _, err := s.GetAnquette(ctx, anquetteID); err != nil {
+		return nil, err
+	}
+	photos, err := s.Repo.ListPhotos(ctx, anquetteID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to list photos: %w", err)
+	}
+	for i := range photos {
+		photos[i] = withURLs(photos[i])
+	}
+	return photos, nil
+}
+
+// OpenPhoto - файл фото или его копии variant ("" - оригинал) и его метаданные.
+// Вызывающий закрывает reader.
+func (s *ServiceImpl) OpenPhoto(ctx context.Context, id int, variant string) (domain.PhotoVariant, io.ReadCloser, error) {
+	p, err := s.getPhoto(ctx, id)
+	if err != nil {
+		return domain.PhotoVariant{}, nil, err
+	}
+
+	file := domain.PhotoVariant{
+		ContentType: p.ContentType, Size: p.Size, Width: p.Width, Height: p.Height, URL: p.URL, StorageKey: p.StorageKey,
+	}
+	if variant != "" {
+		i := slices.IndexFunc(p.Variants, func(v domain.PhotoVariant) bool { return v.Name == variant })
+		if i < 0 {
+			return domain.PhotoVariant{}, nil, fmt.Errorf("service: photo %d has no variant %q: %w", id, variant, ErrNotFound)
+		}
+		file = p.Variants[i]
+	}
+
+	rc, err := s.Blobs.Open(ctx, file.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			return domain.PhotoVariant{}, nil, fmt.Errorf("service: photo %d file is missing: %w", id, ErrNotFound)
+		}
+		return domain.PhotoVariant{}, nil, fmt.Errorf("service: failed to open photo: %w", err)
+	}
+	return file, rc, nil
+}
+
+func (s *ServiceImpl) DeletePhoto(ctx context.Context, anquetteID, photoID int) error {
+	if err := s.checkAnquetteOwner(ctx, anquetteID); err != nil {
+		return err
+	}
+	p, err := s.getPhoto(ctx, photoID)
+	if err != nil {
+		return err
+	}
+	if p.AnquetteID != anquetteID {
+		return fmt.Errorf("service: photo %d is not in anquette %d: %w", photoID, anquetteID, ErrNotFound)
+	}
+
+	if err := s.Repo.DeletePhoto(ctx, photoID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("service: photo not found for delete: %w", ErrNotFound)
+		}
+		return fmt.Errorf("service: failed to delete photo: %w", err)
+	}
+	s.deleteBlobs(ctx, photoKeys(p))
+	return nil
+}
+
+// ReorderPhotos - photoIDs должен содержать все фото анкеты ровно по одному разу
+func (s *ServiceImpl) ReorderPhotos(ctx context.Context, anquetteID int, photoIDs []int) error {
+	if err := s.checkAnquetteOwner(ctx, anquetteID); err != nil {
+		return err
+	}
+	if _, err := s.getAnquette(ctx, anquetteID); err != nil {
+		return err
+	}
+	photos, err := s.Repo.ListPhotos(ctx, anquetteID)
+	if err != nil {
+		return fmt.Errorf("service: failed to list photos: %w", err)
+	}
+
+	current := make([]int, len(photos))
+	for i, p := range photos {
+		current[i] = p.ID
+	}
+	requested := slices.Clone(photoIDs)
+	slices.Sort(current)
+	slices.Sort(requested)
+	if !slices.Equal(current, requested) {
+		return fmt.Errorf("service: photo order must list every photo once: %w", invalid("photo_ids", domain.CodeInvalid, "violation.photo_ids.invalid"))
+	}
+
+	if err := s.Repo.ReorderPhotos(ctx, anquetteID, photoIDs); err != nil {
+		return fmt.Errorf("service: failed to reorder photos: %w", err)
+	}
+	return nil
+}
+
+func (s *ServiceImpl) getPhoto(ctx context.Context, id int) (domain.Photo, error) {
+	p, err := s.Repo.GetPhoto(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Photo{}, fmt.Errorf("service: photo not found: %w", ErrNotFound)
+		}
+		return domain.Photo{}, fmt.Errorf("service: failed to get photo: %w", err)
+	}
+	return withURLs(p), nil
+}
+
+// deleteAnquettePhotos - удаляет все фото удаленной анкеты вместе с файлами
+func (s *ServiceImpl) deleteAnquettePhotos(ctx context.Context, anquetteID int) {
+	photos, err := s.Repo.ListPhotos(ctx, anquetteID)
+	if err == nil {
+		err = s.Repo.DeleteAnquettePhotos(ctx, anquetteID)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "failed to delete photos of anquette", "anquette_id", anquetteID, "error", err)
+		return
+	}
+	for _, p := range photos {
+		s.deleteBlobs(ctx, photoKeys(p))
+	}
+}
+
+// deleteBlobs - файлы без записи в БД никому не видны, поэтому ошибки только логируются
+func (s *ServiceImpl) deleteBlobs(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := s.Blobs.Delete(ctx, key); err != nil {
+			slog.WarnContext(ctx, "failed to delete blob", "key", key, "error", err)
+		}
+	}
+}
+
+// ProcessPendingPhotos - обрабатывает фото, загруженные до появления очистки
+// EXIF и уменьшенных копий. Старый файл заменяется очищенным. Фото, которые
+// не удалось обработать, пропускаются и остаются как есть.
+func (s *ServiceImpl) ProcessPendingPhotos(ctx context.Context) error {
+	processed, afterID := 0, 0
+	for {
+		photos, err := s.Repo.ListUnprocessedPhotos(ctx, afterID, pendingPhotosBatch)
+		if err != nil {
+			return fmt.Errorf("service: failed to list unprocessed photos: %w", err)
+		}
+		if len(photos) == 0 {
+			break
+		}
+		for _, old := range photos {
+			afterID = old.ID
+			if err := s.reprocessPhoto(ctx, old); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				slog.WarnContext(ctx, "failed to process photo", "photo_id", old.ID, "error", err)
+				continue
+			}
+			processed++
+		}
+	}
+
+	if processed > 0 {
+		slog.InfoContext(ctx, "Обработано старых фото", "count", processed)
+	}
+	return nil
+}
+
+func (s *ServiceImpl) reprocessPhoto(ctx context.Context, old domain.Photo) error {
+	rc, err := s.Blobs.Open(ctx, old.StorageKey)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(io.LimitReader(rc, MaxPhotoSize+1))
+	rc.Close()
+	if err != nil {
+		return err
+	}
+
+	p, err := s.storeProcessed(ctx, old.AnquetteID, data)
+	if err != nil {
+		return err
+	}
+	p.ID = old.ID
+	if err := s.Repo.UpdateProcessedPhoto(ctx, p); err != nil {
+		s.deleteBlobs(ctx, photoKeys(p))
+		return err
+	}
+	s.deleteBlobs(ctx, photoKeys(old))
+	return nil
+}
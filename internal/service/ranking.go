@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"unicode/utf8"
+
+	"bot-api/internal/domain"
+	"bot-api/internal/ranking"
+)
+
+// rankPoolSize - из скольких недавно активных кандидатов выбирается лучшая пачка
+const rankPoolSize = 200
+
+// FeedRanker - оценка совместимости кандидата со зрителем (см. ranking.Scorer)
+type FeedRanker interface {
+	Score(v ranking.Viewer, c ranking.Candidate) domain.ScoreExplanation
+}
+
+// rankFeed - берет rankPoolSize недавно активных кандидатов и возвращает f.Limit
+// лучших по оценке Ranker, по убыванию (Score, -ID). Следующая страница - после
+// курсора (f.AfterScore, f.AfterID): курсор задан значением, а не номером позиции,
+// поэтому анкеты, которые зритель оценил и которые выпали из пула, не сдвигают
+// страницы. Без Ranker все оценки равны и порядок - по ID.
+func (s *ServiceImpl) rankFeed(ctx context.Context, viewer domain.Anquette, f domain.FeedFilter) ([]domain.RankedFeedItem, error) {
+	limit := f.Limit
+	f.Limit = rankPoolSize
+
+	pool, err := s.Repo.GetFeed(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get feed candidates: %w", err)
+	}
+
+	ranked := make([]domain.RankedFeedItem, 0, len(pool))
+	v := rankingViewer(viewer, f)
+	for _, item := range pool {
+		r := domain.RankedFeedItem{FeedItem: item}
+		if s.Ranker != nil {
+			exp := s.Ranker.Score(v, rankingCandidate(item))
+			r.Score, r.Signals = exp.Score, exp.Signals
+		}
+		if f.AfterID == 0 || r.Score < f.AfterScore || (r.Score == f.AfterScore && r.ID > f.AfterID) {
+			ranked = append(ranked, r)
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].ID < ranked[j].ID
+	})
+
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked, nil
+}
+
+// rankingViewer - зритель для ранжирования; искомый возраст берется из фильтра
+func rankingViewer(a domain.Anquette, f domain.FeedFilter) ranking.Viewer {
+	return ranking.Viewer{
+		Age: a.Age, Gender: a.Gender, CityID: a.CityID, Interests: a.Interests,
+		AgeMin: f.AgeMin, AgeMax: f.AgeMax,
+	}
+}
+
+// rankingCandidate - кандидат для ранжирования. Кого ищет кандидат, берется из
+// его Preferences так же, как фильтр ленты по умолчанию (см. applyViewerDefaults).
+func rankingCandidate(item domain.FeedItem) ranking.Candidate {
+	c := ranking.Candidate{
+		Age: item.Age, Gender: item.Gender, CityID: item.CityID, Interests: item.Interests,
+		DistanceKm:        item.DistanceKm,
+		DescriptionLength: utf8.RuneCountInString(item.Description),
+		PhotoCount:        item.Stats.PhotoCount,
+		HasLocation:       item.Location != nil,
+		LikesGiven:        item.Stats.LikesGiven,
+		ReactionsGiven:    item.Stats.ReactionsGiven,
+	}
+	if p := item.Preferences; p.AgeMin > 0 || p.AgeMax > 0 {
+		c.AgeMin, c.AgeMax = p.AgeMin, p.AgeMax
+	} else if item.Age > 0 {
+		c.AgeMin, c.AgeMax = max(item.Age-defaultFeedAgeSpread, 1), item.Age+defaultFeedAgeSpread
+	}
+	c.Genders = item.Preferences.Genders
+	if item.Stats.LastActiveAt != nil {
+		c.LastActiveAt = *item.Stats.LastActiveAt
+	}
+	return c
+}
+
+// touchUser - отмечает активность пользователя; ошибка не мешает запросу
+func (s *ServiceImpl) touchUser(ctx context.Context, tgID int64) {
+	if err := s.Repo.TouchUser(ctx, tgID); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		slog.WarnContext(ctx, "failed to update last activity of user", "tg_id", tgID, "error", err)
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"bot-api/internal/auth"
+	"bot-api/internal/domain"
+	"bot-api/internal/repository"
+)
+
+const (
+	defaultMatchesLimit = 20
+	maxMatchesLimit     = 100
+)
+
+// --- Методы Reaction ---
+
+// React - сохраняет реакцию пользователя fromTgID на анкету toAnquetteID.
+// Если это лайк и владелец анкеты уже лайкнул анкету fromTgID, создается мэтч.
+func (s *ServiceImpl) React(ctx context.Context, fromTgID int64, toAnquetteID int, kind string) (domain.ReactionResult, error) {
+	if err := s.checkCaller(ctx, fromTgID); err != nil {
+		return domain.ReactionResult{}, err
+	}
+	if kind != domain.ReactionLike && kind != domain.ReactionDislike {
+		return domain.ReactionResult{}, fmt.Errorf("service: unknown reaction kind %q: %w", kind, invalid("kind", domain.CodeInvalid, "violation.reaction.kind"))
+	}
+
+	from, err := s.GetUser(ctx, int(fromTgID))
+	if err != nil {
+		return domain.ReactionResult{}, err
+	}
+	if from.AnquetteID == toAnquetteID {
+		return domain.ReactionResult{}, fmt.Errorf("service: reaction to own anquette: %w", invalid("anquette_id", domain.CodeInvalid, "violation.reaction.own"))
+	}
+	to, err := s.GetAnquette(ctx, toAnquetteID)
+	if err != nil {
+		return domain.ReactionResult{}, err
+	}
+	// Анкеты вне ленты (на модерации, отклоненные, скрытые) оценить нельзя
+	if to.Status != domain.AnquetteStatusActive {
+		return domain.ReactionResult{}, fmt.Errorf("service: anquette %d is %s: %w", toAnquetteID, to.Status, ErrNotFound)
+	}
+
+	owner, err := s.Repo.GetUserByAnquette(ctx, toAnquetteID)
+	hasOwner := err == nil
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return domain.ReactionResult{}, fmt.Errorf("service: failed to get anquette owner: %w", err)
+	}
+	if hasOwner {
+		blocked, err := s.Repo.IsBlocked(ctx, fromTgID, owner.TgID)
+		if err != nil {
+			return domain.ReactionResult{}, fmt.Errorf("service: failed to check block: %w", err)
+		}
+		// Для заблокированной пары анкеты друг друга не существуют
+		if blocked {
+			return domain.ReactionResult{}, fmt.Errorf("service: anquette %d is hidden by block: %w", toAnquetteID, ErrNotFound)
+		}
+	}
+
+	req := domain.ReactionRequest{TgID: fromTgID, AnquetteID: toAnquetteID, Kind: kind}
+	var result domain.ReactionResult
+	// Мэтч возможен только при лайке анкеты, у которой есть владелец с собственной анкетой;
+	// тогда реакция и мэтч сохраняются вместе, чтобы сбой не оставил лайк без мэтча
+	if kind == domain.ReactionLike && from.AnquetteID != 0 && hasOwner {
+		result.Reaction, result.Match, err = s.Repo.InsertReactionAndMatch(ctx, req, owner.TgID, from.AnquetteID)
+	} else {
+		result.Reaction, err = s.Repo.InsertReaction(ctx, req)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return domain.ReactionResult{}, fmt.Errorf("service: anquette already rated: %w", ErrAlreadyExists)
+		}
+		return domain.ReactionResult{}, fmt.Errorf("service: failed to insert reaction: %w", err)
+	}
+	s.touchUser(ctx, fromTgID)
+	return result, nil
+}
+
+// --- Методы Match ---
+
+// ListMatches - мэтчи пользователя tgID, новые первыми
+func (s *ServiceImpl) ListMatches(ctx context.Context, tgID int, beforeID, limit int) ([]domain.MatchView, error) {
+	if err := s.checkCaller(ctx, int64(tgID)); err != nil {
+		return nil, err
+	}
+	if beforeID < 0 {
+		return nil, fmt.Errorf("service: invalid matches cursor: %w", invalid("before", domain.CodeInvalid, "violation.cursor.invalid"))
+	}
+	if limit <= 0 {
+		limit = defaultMatchesLimit
+	}
+	if limit > maxMatchesLimit {
+		limit = maxMatchesLimit
+	}
+
+	if _, err := s.GetUser(ctx, tgID); err != nil {
+		return nil, err
+	}
+
+	matches, err := s.Repo.ListMatches(ctx, int64(tgID), beforeID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to list matches: %w", err)
+	}
+	return matches, nil
+}
+
+// DeleteMatch - разрывает мэтч. Пользователь Telegram может разорвать только свой мэтч.
+func (s *ServiceImpl) DeleteMatch(ctx context.Context, id int) error {
+	if caller, ok := auth.TgIDFromContext(ctx); ok {
+		m, err := s.Repo.GetMatch(ctx, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("service: match not found for delete: %w", ErrNotFound)
+			}
+			return fmt.Errorf("service: failed to get match: %w", err)
+		}
+		if m.UserTgID1 != caller && m.UserTgID2 != caller {
+			return fmt.Errorf("service: match %d does not belong to user %d: %w", id, caller, ErrForbidden)
+		}
+	}
+
+	err := s.Repo.DeleteMatch(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("service: match not found for delete: %w", ErrNotFound)
+		}
+		return fmt.Errorf("service: failed to delete match: %w", err)
+	}
+	return nil
+}
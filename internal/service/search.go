@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"bot-api/internal/auth"
+	"bot-api/internal/domain"
+	"bot-api/internal/search"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+	maxSearchQueryLen  = 200
+)
+
+// searchStatuses - статусы, по которым ключ API с правом admin может ограничить поиск
+var searchStatuses = []string{
+	domain.AnquetteStatusPending, domain.AnquetteStatusActive,
+	domain.AnquetteStatusRejected, domain.AnquetteStatusHidden,
+}
+
+// --- Методы Search ---
+
+// SearchAnquettes - полнотекстовый поиск анкет по имени и описанию.
+// Анкеты любого статуса или статуса f.Status ищет только ключ API с правом
+// admin (модерация); остальные находят только активные анкеты, а запрос
+// другого статуса - ErrForbidden. С f.ViewerTgID (пользователь Mini App -
+// всегда он сам) анкеты, с владельцами которых есть блокировка, скрываются, как в ленте.
+func (s *ServiceImpl) SearchAnquettes(ctx context.Context, f domain.SearchFilter) ([]domain.SearchHit, error) {
+	var v validator
+	f.Query = strings.TrimSpace(f.Query)
+	v.length("q", f.Query, 1, maxSearchQueryLen)
+	if f.Query != "" && search.Match(f.Query) == "" {
+		v.add("q", domain.CodeInvalid, "violation.q.no_words")
+	}
+	if f.Status != "" && !slices.Contains(searchStatuses, f.Status) {
+		v.add("status", domain.CodeInvalid, "violation.status.invalid", strings.Join(searchStatuses, ", "))
+	}
+	if err := v.err(); err != nil {
+		return nil, fmt.Errorf("service: invalid search query: %w", err)
+	}
+
+	if !canSearchAnyStatus(ctx) {
+		if f.Status != "" && f.Status != domain.AnquetteStatusActive {
+			return nil, fmt.Errorf("service: search by status %q requires %s scope: %w", f.Status, auth.ScopeAdmin, ErrForbidden)
+		}
+		f.Status = domain.AnquetteStatusActive
+	}
+	if tgID, ok := auth.TgIDFromContext(ctx); ok && f.ViewerTgID == 0 {
+		f.ViewerTgID = tgID
+	}
+	if f.ViewerTgID != 0 {
+		if err := s.checkCaller(ctx, f.ViewerTgID); err != nil {
+			return nil, err
+		}
+	}
+	if f.Limit <= 0 {
+		f.Limit = defaultSearchLimit
+	}
+	if f.Limit > maxSearchLimit {
+		f.Limit = maxSearchLimit
+	}
+
+	hits, err := s.Repo.SearchAnquettes(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to search anquettes: %w", err)
+	}
+	return hits, nil
+}
+
+// canSearchAnyStatus - может ли вызывающий искать среди неактивных анкет:
+// ключ API с правом admin или внутренний вызов без аутентификации
+func canSearchAnyStatus(ctx context.Context) bool {
+	if _, ok := auth.TgIDFromContext(ctx); ok {
+		return false
+	}
+	if key, ok := auth.APIKeyFromContext(ctx); ok {
+		return auth.HasScope(key.Scopes, auth.ScopeAdmin)
+	}
+	return true
+}
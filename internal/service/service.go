@@ -3,10 +3,12 @@ package service
 import (
 	"bot-api/internal/domain"
 	"bot-api/internal/repository"
+	"bot-api/internal/storage"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"io"
 )
 
 // Стандартизированные доменные ошибки
@@ -14,6 +16,9 @@ var (
 	ErrNotFound         = errors.New("item not found")
 	ErrValidationFailed = errors.New("validation failed")
 	ErrAlreadyExists    = errors.New("item already exists")
+	ErrForbidden        = errors.New("access denied")
+	ErrUnauthorized     = errors.New("authentication failed")
+	ErrUserBanned       = errors.New("user is banned")
 )
 
 // UserService - интерфейс с экспортированными именами функций
@@ -26,11 +31,49 @@ type UserService interface {
 	GetAnquette(ctx context.Context, id int) (domain.Anquette, error)             // Экспортировано
 	UpdateAnquette(ctx context.Context, id int, req domain.AnquetteRequest) error // Экспортировано
 	DeleteAnquette(ctx context.Context, id int) error                             // Экспортировано
+
+	SetAnquetteLocation(ctx context.Context, id int, req domain.LocationRequest) error
+	ClearAnquetteLocation(ctx context.Context, id int) error
+	SetAnquetteInterests(ctx context.Context, id int, req domain.InterestsRequest) error
+	ListInterests(ctx context.Context) ([]domain.Interest, error)
+
+	GetFeed(ctx context.Context, tgID int, f domain.FeedFilter) ([]domain.FeedItem, error)
+	ListUsers(ctx context.Context, f domain.ListFilter) (domain.Page[domain.User], error)
+	ListAnquettes(ctx context.Context, f domain.ListFilter) (domain.Page[domain.Anquette], error)
+	SearchAnquettes(ctx context.Context, f domain.SearchFilter) ([]domain.SearchHit, error)
+	ExplainFeed(ctx context.Context, tgID int, f domain.FeedFilter) ([]domain.RankedFeedItem, error)
+
+	SearchCities(ctx context.Context, q string, limit int) ([]domain.City, error)
+
+	React(ctx context.Context, fromTgID int64, toAnquetteID int, kind string) (domain.ReactionResult, error)
+	ListMatches(ctx context.Context, tgID int, beforeID, limit int) ([]domain.MatchView, error)
+	DeleteMatch(ctx context.Context, id int) error
+
+	Block(ctx context.Context, req domain.BlockRequest) (domain.Block, error)
+	Report(ctx context.Context, req domain.ReportRequest) (domain.Report, error)
+
+	ModerationQueue(ctx context.Context, afterReportID, afterAnquetteID, limit int) (domain.ModerationQueue, error)
+	Moderate(ctx context.Context, req domain.ModerationRequest) (domain.ModerationDecision, error)
+
+	UploadPhoto(ctx context.Context, anquetteID int, data []byte) (domain.Photo, error)
+	ListPhotos(ctx context.Context, anquetteID int) ([]domain.Photo, error)
+	OpenPhoto(ctx context.Context, id int, variant string) (domain.PhotoVariant, io.ReadCloser, error)
+	DeletePhoto(ctx context.Context, anquetteID, photoID int) error
+	ReorderPhotos(ctx context.Context, anquetteID int, photoIDs []int) error
+
+	CreateAPIKey(ctx context.Context, req domain.APIKeyRequest) (domain.IssuedAPIKey, error)
+	ListAPIKeys(ctx context.Context) ([]domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int) error
+	AuthenticateAPIKey(ctx context.Context, key string) (domain.APIKey, error)
 }
 
 // ServiceImpl - реализация сервиса, зависит от Repository
 type ServiceImpl struct {
-	Repo repository.UserRepository
+	Repo   repository.UserRepository
+	Blobs  storage.BlobStore // файлы фото анкет
+	Filter ContentFilter     // проверка текста анкет; nil - без проверки
+	Cities CityDirectory     // справочник городов; nil - город хранится как введен
+	Ranker FeedRanker        // ранжирование ленты для FeedSortScore; nil - по активности
 }
 
 func NewService(repo repository.UserRepository) *ServiceImpl {
@@ -40,6 +83,16 @@ func NewService(repo repository.UserRepository) *ServiceImpl {
 // --- Методы User с экспортированными именами ---
 
 func (s *ServiceImpl) InsertUser(ctx context.Context, req domain.UserRequest) (int, error) {
+	if err := validateUser(&req); err != nil {
+		return 0, err
+	}
+	if err := s.checkCaller(ctx, req.TgID); err != nil {
+		return 0, err
+	}
+	if err := s.checkAnquetteFree(ctx, req.AnquetteID, req.TgID); err != nil {
+		return 0, err
+	}
+
 	// Вызов экспортированного метода
 	newID, err := s.Repo.InsertUser(ctx, req)
 	if err != nil {
@@ -49,6 +102,12 @@ func (s *ServiceImpl) InsertUser(ctx context.Context, req domain.UserRequest) (i
 }
 
 func (s *ServiceImpl) GetUser(ctx context.Context, tg_id int) (domain.User, error) {
+	// Пользователь Telegram читает только себя: tg_username другого пользователя
+	// раскрывается лишь после мэтча (ListMatches)
+	if err := s.checkCaller(ctx, int64(tg_id)); err != nil {
+		return domain.User{}, err
+	}
+
 	// Вызов экспортированного метода
 	u, err := s.Repo.GetUser(ctx, tg_id)
 	if err != nil {
@@ -62,6 +121,20 @@ func (s *ServiceImpl) GetUser(ctx context.Context, tg_id int) (domain.User, erro
 }
 
 func (s *ServiceImpl) UpdateUser(ctx context.Context, tg_id int, req domain.UserRequest) error {
+	if err := validateUser(&req); err != nil {
+		return err
+	}
+	// Пользователь Telegram меняет только себя и не может присвоить чужой tg_id
+	if err := s.checkCaller(ctx, int64(tg_id)); err != nil {
+		return err
+	}
+	if err := s.checkCaller(ctx, req.TgID); err != nil {
+		return err
+	}
+	if err := s.checkAnquetteFree(ctx, req.AnquetteID, int64(tg_id)); err != nil {
+		return err
+	}
+
 	// Вызов экспортированного метода
 	err := s.Repo.UpdateUser(ctx, tg_id, req)
 	if err != nil {
@@ -77,8 +150,17 @@ func (s *ServiceImpl) UpdateUser(ctx context.Context, tg_id int, req domain.User
 
 func (s *ServiceImpl) InsertAnquette(ctx context.Context, req domain.AnquetteRequest) (int, error) {
 	// Бизнес-валидация
-	if len(req.Description) < 50 {
-		return 0, fmt.Errorf("service: validation failed: %w", ErrValidationFailed)
+	if err := validateAnquette(&req); err != nil {
+		return 0, err
+	}
+	s.resolveCity(&req)
+
+	flagged, err := s.checkContent(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	if flagged {
+		req.Status = domain.AnquetteStatusPending
 	}
 
 	// Вызов экспортированного метода
@@ -89,7 +171,27 @@ func (s *ServiceImpl) InsertAnquette(ctx context.Context, req domain.AnquetteReq
 	return newID, nil
 }
 
+// GetAnquette - анкета, какой ее видит вызывающий: пользователю Telegram чужая
+// анкета доступна, только если она активна и между ними нет блокировки.
+// Владелец отклоненной анкеты видит причину отказа.
 func (s *ServiceImpl) GetAnquette(ctx context.Context, id int) (domain.Anquette, error) {
+	a, err := s.getAnquette(ctx, id)
+	if err != nil {
+		return domain.Anquette{}, err
+	}
+	if err := s.checkAnquetteVisible(ctx, a); err != nil {
+		return domain.Anquette{}, err
+	}
+	if a.Status == domain.AnquetteStatusRejected {
+		if a.RejectionReason, err = s.Repo.LastRejectionReason(ctx, a.ID); err != nil {
+			return domain.Anquette{}, fmt.Errorf("service: failed to get rejection reason: %w", err)
+		}
+	}
+	return a, nil
+}
+
+// getAnquette - анкета в любом статусе, без проверки доступа
+func (s *ServiceImpl) getAnquette(ctx context.Context, id int) (domain.Anquette, error) {
 	// Вызов экспортированного метода
 	a, err := s.Repo.GetAnquette(ctx, id)
 	if err != nil {
@@ -102,13 +204,32 @@ func (s *ServiceImpl) GetAnquette(ctx context.Context, id int) (domain.Anquette,
 }
 
 func (s *ServiceImpl) UpdateAnquette(ctx context.Context, id int, req domain.AnquetteRequest) error {
+	if err := s.checkAnquetteOwner(ctx, id); err != nil {
+		return err
+	}
+
 	// Бизнес-валидация
-	if len(req.Description) < 50 {
-		return fmt.Errorf("service: validation failed: %w", ErrValidationFailed)
+	if err := validateAnquette(&req); err != nil {
+		return err
+	}
+	s.resolveCity(&req)
+	flagged, err := s.checkContent(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	cur, err := s.getAnquette(ctx, id)
+	if err != nil {
+		return err
+	}
+	// Исправленная после отказа или подозрительная анкета уходит на модерацию,
+	// скрытая модератором остается скрытой
+	if cur.Status != domain.AnquetteStatusHidden && (flagged || cur.Status == domain.AnquetteStatusRejected) {
+		req.Status = domain.AnquetteStatusPending
 	}
 
 	// Вызов экспортированного метода
-	err := s.Repo.UpdateAnquette(ctx, id, req)
+	err = s.Repo.UpdateAnquette(ctx, id, req)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return fmt.Errorf("service: anquette not found for update: %w", ErrNotFound)
@@ -119,6 +240,10 @@ func (s *ServiceImpl) UpdateAnquette(ctx context.Context, id int, req domain.Anq
 }
 
 func (s *ServiceImpl) DeleteAnquette(ctx context.Context, id int) error {
+	if err := s.checkAnquetteOwner(ctx, id); err != nil {
+		return err
+	}
+
 	// Вызов экспортированного метода
 	err := s.Repo.DeleteAnquette(ctx, id)
 	if err != nil {
@@ -127,5 +252,6 @@ func (s *ServiceImpl) DeleteAnquette(ctx context.Context, id int) error {
 		}
 		return fmt.Errorf("service: failed to delete anquette: %w", err)
 	}
+	s.deleteAnquettePhotos(ctx, id)
 	return nil
 }
@@ -1,14 +1,26 @@
 package service_test
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
 	"testing"
+	"time"
 
+	"bot-api/internal/auth"
 	"bot-api/internal/domain"
+	"bot-api/internal/gazetteer"
+	"bot-api/internal/metrics"
+	"bot-api/internal/ranking"
 	"bot-api/internal/repository"
 	"bot-api/internal/service"
+	"bot-api/internal/storage"
+	"bot-api/internal/textfilter"
 )
 
 // MockRepo - заглушка, реализующая repository.UserRepository.
@@ -19,7 +31,39 @@ type MockRepo struct {
 	InsertUserFunc     func(ctx context.Context, u domain.UserRequest) (int, error)
 	GetUserFunc        func(ctx context.Context, id int) (domain.User, error)
 	InsertAnquetteFunc func(ctx context.Context, a domain.AnquetteRequest) (int, error)
+	GetAnquetteFunc    func(ctx context.Context, id int) (domain.Anquette, error)
 	DeleteAnquetteFunc func(ctx context.Context, id int) error
+	GetFeedFunc        func(ctx context.Context, f domain.FeedFilter) ([]domain.FeedItem, error)
+	TouchUserFunc      func(ctx context.Context, tgID int64) error
+
+	SetAnquetteLocationFunc  func(ctx context.Context, id int, p *domain.GeoPoint) error
+	SetAnquetteInterestsFunc func(ctx context.Context, id int, interestIDs []string) error
+	ListInterestsFunc        func(ctx context.Context) ([]domain.Interest, error)
+
+	GetUserByAnquetteFunc      func(ctx context.Context, anquetteID int) (domain.User, error)
+	InsertReactionFunc         func(ctx context.Context, r domain.ReactionRequest) (domain.Reaction, error)
+	InsertReactionAndMatchFunc func(ctx context.Context, r domain.ReactionRequest, matchTgID int64, matchAnquetteID int) (domain.Reaction, *domain.Match, error)
+
+	InsertBlockFunc  func(ctx context.Context, blockerTgID, blockedTgID int64) (domain.Block, error)
+	IsBlockedFunc    func(ctx context.Context, tgID1, tgID2 int64) (bool, error)
+	InsertReportFunc func(ctx context.Context, r domain.Report) (domain.Report, error)
+
+	GetReportFunc               func(ctx context.Context, id int) (domain.Report, error)
+	ApplyModerationDecisionFunc func(ctx context.Context, d domain.ModerationDecision, anquetteStatus string) (domain.ModerationDecision, error)
+	LastRejectionReasonFunc     func(ctx context.Context, anquetteID int) (string, error)
+
+	GetAPIKeyByHashFunc func(ctx context.Context, keyHash string) (domain.APIKey, error)
+
+	ListPhotosFunc  func(ctx context.Context, anquetteID int) ([]domain.Photo, error)
+	InsertPhotoFunc func(ctx context.Context, p domain.Photo) (domain.Photo, error)
+
+	ListAnquettesWithoutCityIDFunc func(ctx context.Context, afterID, limit int) ([]domain.Anquette, error)
+	SetAnquetteCityFunc            func(ctx context.Context, id int, city, cityID string) error
+
+	ListUsersFunc     func(ctx context.Context, f domain.ListFilter) (domain.Page[domain.User], error)
+	ListAnquettesFunc func(ctx context.Context, f domain.ListFilter) (domain.Page[domain.Anquette], error)
+
+	SearchAnquettesFunc func(ctx context.Context, f domain.SearchFilter) ([]domain.SearchHit, error)
 }
 
 // Переопределяем только те методы, которые нам нужны для тестов
@@ -27,14 +71,98 @@ func (m *MockRepo) InsertUser(ctx context.Context, u domain.UserRequest) (int, e
 	return m.InsertUserFunc(ctx, u)
 }
 func (m *MockRepo) GetUser(ctx context.Context, id int) (domain.User, error) {
+	if m.GetUserFunc == nil {
+		return domain.User{}, sql.ErrNoRows
+	}
 	return m.GetUserFunc(ctx, id)
 }
 func (m *MockRepo) InsertAnquette(ctx context.Context, a domain.AnquetteRequest) (int, error) {
 	return m.InsertAnquetteFunc(ctx, a)
 }
+func (m *MockRepo) GetAnquette(ctx context.Context, id int) (domain.Anquette, error) {
+	return m.GetAnquetteFunc(ctx, id)
+}
 func (m *MockRepo) DeleteAnquette(ctx context.Context, id int) error {
 	return m.DeleteAnquetteFunc(ctx, id)
 }
+func (m *MockRepo) SetAnquetteLocation(ctx context.Context, id int, p *domain.GeoPoint) error {
+	return m.SetAnquetteLocationFunc(ctx, id, p)
+}
+func (m *MockRepo) GetFeed(ctx context.Context, f domain.FeedFilter) ([]domain.FeedItem, error) {
+	return m.GetFeedFunc(ctx, f)
+}
+func (m *MockRepo) GetUserByAnquette(ctx context.Context, anquetteID int) (domain.User, error) {
+	if m.GetUserByAnquetteFunc == nil {
+		return domain.User{}, sql.ErrNoRows
+	}
+	return m.GetUserByAnquetteFunc(ctx, anquetteID)
+}
+func (m *MockRepo) InsertReaction(ctx context.Context, r domain.ReactionRequest) (domain.Reaction, error) {
+	return m.InsertReactionFunc(ctx, r)
+}
+func (m *MockRepo) InsertReactionAndMatch(ctx context.Context, r domain.ReactionRequest, matchTgID int64, matchAnquetteID int) (domain.Reaction, *domain.Match, error) {
+	return m.InsertReactionAndMatchFunc(ctx, r, matchTgID, matchAnquetteID)
+}
+func (m *MockRepo) InsertBlock(ctx context.Context, blockerTgID, blockedTgID int64) (domain.Block, error) {
+	return m.InsertBlockFunc(ctx, blockerTgID, blockedTgID)
+}
+func (m *MockRepo) SetAnquetteInterests(ctx context.Context, id int, interestIDs []string) error {
+	return m.SetAnquetteInterestsFunc(ctx, id, interestIDs)
+}
+func (m *MockRepo) ListInterests(ctx context.Context) ([]domain.Interest, error) {
+	return m.ListInterestsFunc(ctx)
+}
+func (m *MockRepo) ListUsers(ctx context.Context, f domain.ListFilter) (domain.Page[domain.User], error) {
+	return m.ListUsersFunc(ctx, f)
+}
+func (m *MockRepo) ListAnquettes(ctx context.Context, f domain.ListFilter) (domain.Page[domain.Anquette], error) {
+	return m.ListAnquettesFunc(ctx, f)
+}
+func (m *MockRepo) SearchAnquettes(ctx context.Context, f domain.SearchFilter) ([]domain.SearchHit, error) {
+	return m.SearchAnquettesFunc(ctx, f)
+}
+func (m *MockRepo) TouchUser(ctx context.Context, tgID int64) error {
+	if m.TouchUserFunc == nil {
+		return nil
+	}
+	return m.TouchUserFunc(ctx, tgID)
+}
+func (m *MockRepo) IsBlocked(ctx context.Context, tgID1, tgID2 int64) (bool, error) {
+	if m.IsBlockedFunc == nil {
+		return false, nil
+	}
+	return m.IsBlockedFunc(ctx, tgID1, tgID2)
+}
+func (m *MockRepo) InsertReport(ctx context.Context, r domain.Report) (domain.Report, error) {
+	return m.InsertReportFunc(ctx, r)
+}
+func (m *MockRepo) GetReport(ctx context.Context, id int) (domain.Report, error) {
+	return m.GetReportFunc(ctx, id)
+}
+func (m *MockRepo) ApplyModerationDecision(ctx context.Context, d domain.ModerationDecision, anquetteStatus string) (domain.ModerationDecision, error) {
+	return m.ApplyModerationDecisionFunc(ctx, d, anquetteStatus)
+}
+func (m *MockRepo) LastRejectionReason(ctx context.Context, anquetteID int) (string, error) {
+	if m.LastRejectionReasonFunc == nil {
+		return "", nil
+	}
+	return m.LastRejectionReasonFunc(ctx, anquetteID)
+}
+func (m *MockRepo) ListPhotos(ctx context.Context, anquetteID int) ([]domain.Photo, error) {
+	return m.ListPhotosFunc(ctx, anquetteID)
+}
+func (m *MockRepo) InsertPhoto(ctx context.Context, p domain.Photo) (domain.Photo, error) {
+	return m.InsertPhotoFunc(ctx, p)
+}
+func (m *MockRepo) ListAnquettesWithoutCityID(ctx context.Context, afterID, limit int) ([]domain.Anquette, error) {
+	return m.ListAnquettesWithoutCityIDFunc(ctx, afterID, limit)
+}
+func (m *MockRepo) SetAnquetteCity(ctx context.Context, id int, city, cityID string) error {
+	return m.SetAnquetteCityFunc(ctx, id, city, cityID)
+}
+func (m *MockRepo) GetAPIKeyByHash(ctx context.Context, keyHash string) (domain.APIKey, error) {
+	return m.GetAPIKeyByHashFunc(ctx, keyHash)
+}
 
 // Для остальных методов (UpdateUser, GetAnquette, UpdateAnquette) будет использована базовая реализация,
 // если они не переопределены, но для чистоты теста можно определить все, чтобы не было nil-указателей
@@ -78,6 +206,27 @@ func TestServiceImpl_GetUser_NotFound(t *testing.T) {
 	}
 }
 
+func TestServiceImpl_GetUser_Stranger(t *testing.T) {
+	mockRepo := &MockRepo{
+		GetUserFunc: func(ctx context.Context, id int) (domain.User, error) {
+			return domain.User{TgID: int64(id), TgUsername: "secret"}, nil
+		},
+	}
+	svc := service.NewService(mockRepo)
+
+	// Чужой профиль с tg_username недоступен до мэтча
+	u, err := svc.GetUser(auth.WithTgID(context.Background(), 1), 2)
+	if !errors.Is(err, service.ErrForbidden) || u.TgUsername != "" {
+		t.Errorf("Ожидали ошибку service.ErrForbidden без username, получили %+v, %v", u, err)
+	}
+
+	// Свой профиль - можно
+	u, err = svc.GetUser(auth.WithTgID(context.Background(), 1), 1)
+	if err != nil || u.TgUsername != "secret" {
+		t.Errorf("Ожидали свой профиль, получили %+v, %v", u, err)
+	}
+}
+
 // --- ТЕСТЫ ANQUETTE ---
 
 func TestServiceImpl_InsertAnquette_ValidationFail(t *testing.T) {
@@ -95,8 +244,105 @@ func TestServiceImpl_InsertAnquette_ValidationFail(t *testing.T) {
 	}
 }
 
+func TestServiceImpl_InsertAnquette_FieldViolations(t *testing.T) {
+	req := domain.AnquetteRequest{
+		Name: " Я ", Age: 16, Gender: "robot", City: "Moscow 2",
+		Description: "Это очень длинное описание, которое точно пройдет проверку валидации и будет вставлено.",
+	}
+	svc := service.NewService(&MockRepo{})
+
+	_, err := svc.InsertAnquette(context.Background(), req)
+
+	var verr *service.ValidationError
+	if !errors.As(err, &verr) || !errors.Is(err, service.ErrValidationFailed) {
+		t.Fatalf("Ожидали service.ValidationError, получили: %v", err)
+	}
+	got := map[string]string{}
+	for _, v := range verr.Violations {
+		got[v.Field] = v.Code
+		if v.Key == "" {
+			t.Errorf("Нет ключа сообщения для поля %s", v.Field)
+		}
+	}
+	want := map[string]string{
+		"name":   domain.CodeTooShort,
+		"age":    domain.CodeOutOfRange,
+		"gender": domain.CodeInvalid,
+		"city":   domain.CodeInvalid,
+	}
+	if len(got) != len(want) {
+		t.Errorf("Ожидали нарушения %v, получили %v", want, got)
+	}
+	for field, code := range want {
+		if got[field] != code {
+			t.Errorf("Поле %s: ожидали код %s, получили %q", field, code, got[field])
+		}
+	}
+}
+
+func TestServiceImpl_InsertUser_InvalidTgID(t *testing.T) {
+	svc := service.NewService(&MockRepo{})
+
+	_, err := svc.InsertUser(context.Background(), domain.UserRequest{TgID: -5})
+
+	var verr *service.ValidationError
+	if !errors.As(err, &verr) || verr.Violations[0].Field != "tg_id" {
+		t.Errorf("Ожидали нарушение в поле tg_id, получили: %v", err)
+	}
+}
+
+func TestServiceImpl_InsertAnquette_Preferences(t *testing.T) {
+	var got domain.Preferences
+	mockRepo := &MockRepo{
+		InsertAnquetteFunc: func(ctx context.Context, a domain.AnquetteRequest) (int, error) {
+			got = a.Preferences
+			return 5, nil
+		},
+	}
+	svc := service.NewService(mockRepo)
+	req := domain.AnquetteRequest{
+		Name: "Анна", Age: 22, Gender: "f", City: "Новороссийск",
+		Description: "Это очень длинное описание, которое точно пройдет проверку валидации и будет вставлено.",
+		Preferences: domain.Preferences{
+			Genders: []string{"М", "female", "m"}, AgeMin: 20, AgeMax: 30, Goal: " Dating",
+			Dealbreakers: []string{"no_photos", "other_goal", "no_photos"}, Text: " Ищу девушку ",
+		},
+	}
+
+	if _, err := svc.InsertAnquette(context.Background(), req); err != nil {
+		t.Fatalf("Ожидали отсутствие ошибки, получили: %v", err)
+	}
+	want := domain.Preferences{
+		Genders: []string{"f", "m"}, AgeMin: 20, AgeMax: 30, Goal: domain.GoalDating,
+		Dealbreakers: []string{domain.DealbreakerNoPhotos, domain.DealbreakerOtherGoal}, Text: "Ищу девушку",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Ожидали нормализованные предпочтения %+v, получили %+v", want, got)
+	}
+
+	req.Preferences = domain.Preferences{
+		Genders: []string{"robot"}, AgeMin: 30, AgeMax: 20, MaxDistanceKm: -1, Goal: "marriage", Dealbreakers: []string{"smoking"},
+		Text: strings.Repeat("я", service.MaxPreferencesLen+1),
+	}
+	_, err := svc.InsertAnquette(context.Background(), req)
+	var verr *service.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Ожидали service.ValidationError, получили: %v", err)
+	}
+	fields := map[string]bool{}
+	for _, v := range verr.Violations {
+		fields[v.Field] = true
+	}
+	for _, f := range []string{"preferences.genders", "preferences.age_max", "preferences.max_distance_km", "preferences.goal", "preferences.dealbreakers", "preferences.text"} {
+		if !fields[f] {
+			t.Errorf("Нет нарушения для %s: %+v", f, verr.Violations)
+		}
+	}
+}
+
 func TestServiceImpl_InsertAnquette_Success(t *testing.T) {
 	req := domain.AnquetteRequest{
+		Name: "Анна", Age: 22, Gender: "Ж", City: "Новороссийск",
 		Description: "Это очень длинное описание, которое точно пройдет проверку валидации и будет вставлено.", // > 50 символов
 	}
 	mockRepo := &MockRepo{
@@ -115,3 +361,970 @@ func TestServiceImpl_InsertAnquette_Success(t *testing.T) {
 		t.Errorf("Ожидали ID 5, получили %d", newID)
 	}
 }
+
+func TestServiceImpl_InsertAnquette_NormalizesCity(t *testing.T) {
+	var got []domain.AnquetteRequest
+	mockRepo := &MockRepo{
+		InsertAnquetteFunc: func(ctx context.Context, a domain.AnquetteRequest) (int, error) {
+			got = append(got, a)
+			return len(got), nil
+		},
+	}
+	svc := service.NewService(mockRepo)
+	svc.Cities = gazetteer.Default()
+
+	for _, city := range []string{"Спб", "Урюпинск"} {
+		req := domain.AnquetteRequest{
+			Name: "Анна", Age: 22, Gender: "Ж", City: city,
+			Description: "Это очень длинное описание, которое точно пройдет проверку валидации и будет вставлено.",
+		}
+		if _, err := svc.InsertAnquette(context.Background(), req); err != nil {
+			t.Fatalf("Ожидали отсутствие ошибки, получили: %v", err)
+		}
+	}
+
+	if got[0].City != "Санкт-Петербург" || got[0].CityID != "saint-petersburg" {
+		t.Errorf("Город не приведен к справочнику: %+v", got[0])
+	}
+	// Неизвестный город сохраняется как введен
+	if got[1].City != "Урюпинск" || got[1].CityID != "" {
+		t.Errorf("Неизвестный город изменен: %+v", got[1])
+	}
+}
+
+// --- ТЕСТЫ FEED ---
+
+func TestServiceImpl_GetFeed_ViewerDefaults(t *testing.T) {
+	var got domain.FeedFilter
+	mockRepo := &MockRepo{
+		GetUserFunc: func(ctx context.Context, id int) (domain.User, error) {
+			return domain.User{TgID: 123, AnquetteID: 7}, nil
+		},
+		GetAnquetteFunc: func(ctx context.Context, id int) (domain.Anquette, error) {
+			return domain.Anquette{ID: 7, Age: 20, City: "Новороссийск", Preferences: domain.Preferences{Genders: []string{domain.GenderFemale}}}, nil
+		},
+		GetFeedFunc: func(ctx context.Context, f domain.FeedFilter) ([]domain.FeedItem, error) {
+			got = f
+			return []domain.FeedItem{}, nil
+		},
+	}
+	svc := service.NewService(mockRepo)
+
+	if _, err := svc.GetFeed(context.Background(), 123, domain.FeedFilter{}); err != nil {
+		t.Fatalf("Ожидали отсутствие ошибки, получили: %v", err)
+	}
+
+	// Незаданные параметры берутся из анкеты зрителя, своя анкета исключается,
+	// пол берется из Preferences
+	if got.Gender != domain.GenderFemale || got.City != "Новороссийск" || got.AgeMin != 15 || got.AgeMax != 25 {
+		t.Errorf("Фильтр не заполнен из анкеты зрителя: %+v", got)
+	}
+	if got.ExcludeAnquetteID != 7 || got.ViewerTgID != 123 || got.Limit == 0 {
+		t.Errorf("Неверные служебные поля фильтра: %+v", got)
+	}
+}
+
+func TestServiceImpl_GetFeed_Preferences(t *testing.T) {
+	var got domain.FeedFilter
+	viewer := domain.Anquette{
+		ID: 7, Age: 30, Gender: "m", City: "Новороссийск", Interests: []string{"music"},
+		Location: &domain.GeoPoint{Lat: 44.72, Lon: 37.77},
+		Preferences: domain.Preferences{
+			Genders: []string{"f", "m"}, AgeMin: 25, AgeMax: 35, MaxDistanceKm: 30, Goal: domain.GoalDating,
+			Dealbreakers: []string{domain.DealbreakerOtherGoal, domain.DealbreakerNoPhotos, domain.DealbreakerNoSharedInterest},
+		},
+	}
+	mockRepo := &MockRepo{
+		GetUserFunc: func(ctx context.Context, id int) (domain.User, error) {
+			return domain.User{TgID: 123, AnquetteID: 7}, nil
+		},
+		GetAnquetteFunc: func(ctx context.Context, id int) (domain.Anquette, error) {
+			return viewer, nil
+		},
+		GetFeedFunc: func(ctx context.Context, f domain.FeedFilter) ([]domain.FeedItem, error) {
+			got = f
+			return []domain.FeedItem{}, nil
+		},
+	}
+	svc := service.NewService(mockRepo)
+
+	if _, err := svc.GetFeed(context.Background(), 123, domain.FeedFilter{}); err != nil {
+		t.Fatalf("Ожидали отсутствие ошибки, получили: %v", err)
+	}
+	// Оба пола - без фильтра по полу; расстояние из предпочтений заменяет город
+	if got.Gender != "" || got.AgeMin != 25 || got.AgeMax != 35 || got.MaxDistanceKm != 30 || got.City != "" {
+		t.Errorf("Фильтр не заполнен из предпочтений: %+v", got)
+	}
+	if got.ViewerGender != "m" || got.ViewerAge != 30 {
+		t.Errorf("Ожидали пол и возраст зрителя для взаимности: %+v", got)
+	}
+	if got.Goal != domain.GoalDating || !got.WithPhotos || len(got.Interests) != 1 || got.Interests[0] != "music" {
+		t.Errorf("Стоп-факторы не применены: %+v", got)
+	}
+}
+
+func TestServiceImpl_GetFeed_CityID(t *testing.T) {
+	var got domain.FeedFilter
+	mockRepo := &MockRepo{
+		GetUserFunc: func(ctx context.Context, id int) (domain.User, error) {
+			return domain.User{TgID: 123, AnquetteID: 7}, nil
+		},
+		GetAnquetteFunc: func(ctx context.Context, id int) (domain.Anquette, error) {
+			return domain.Anquette{ID: 7, Age: 20, City: "Москва", CityID: "moscow"}, nil
+		},
+		GetFeedFunc: func(ctx context.Context, f domain.FeedFilter) ([]domain.FeedItem, error) {
+			got = f
+			return []domain.FeedItem{}, nil
+		},
+	}
+	svc := service.NewService(mockRepo)
+	svc.Cities = gazetteer.Default()
+
+	// Город из анкеты зрителя - по city_id
+	svc.GetFeed(context.Background(), 123, domain.FeedFilter{})
+	if got.CityID != "moscow" || got.City != "" {
+		t.Errorf("Ожидали city_id зрителя, получили %+v", got)
+	}
+
+	// Город из запроса переводится в city_id
+	svc.GetFeed(context.Background(), 123, domain.FeedFilter{City: "Питер"})
+	if got.CityID != "saint-petersburg" || got.City != "" {
+		t.Errorf("Ожидали city_id из справочника, получили %+v", got)
+	}
+}
+
+func TestServiceImpl_GetFeed_Interests(t *testing.T) {
+	var got domain.FeedFilter
+	mockRepo := &MockRepo{
+		GetUserFunc: func(ctx context.Context, id int) (domain.User, error) {
+			return domain.User{TgID: 123}, nil
+		},
+		GetFeedFunc: func(ctx context.Context, f domain.FeedFilter) ([]domain.FeedItem, error) {
+			got = f
+			return []domain.FeedItem{}, nil
+		},
+		ListInterestsFunc: interestsCatalog,
+	}
+	svc := service.NewService(mockRepo)
+
+	if _, err := svc.GetFeed(context.Background(), 123, domain.FeedFilter{Interests: []string{"IT", "travel"}}); err != nil {
+		t.Fatalf("Ожидали отсутствие ошибки, получили: %v", err)
+	}
+	if len(got.Interests) != 2 || got.Interests[0] != "it" {
+		t.Errorf("Неверные интересы в фильтре: %v", got.Interests)
+	}
+
+	_, err := svc.GetFeed(context.Background(), 123, domain.FeedFilter{Interests: []string{"knitting"}})
+	if !errors.Is(err, service.ErrValidationFailed) {
+		t.Errorf("Ожидали ошибку валидации для неизвестного интереса, получили: %v", err)
+	}
+}
+
+// stubRanker - оценка равна возрасту кандидата, деленному на 100
+type stubRanker struct{}
+
+func (stubRanker) Score(v ranking.Viewer, c ranking.Candidate) domain.ScoreExplanation {
+	return domain.ScoreExplanation{Score: float64(c.Age) / 100}
+}
+
+func TestServiceImpl_GetFeed_Score(t *testing.T) {
+	var got domain.FeedFilter
+	var touched int64
+	pool := []domain.FeedItem{
+		{Anquette: domain.Anquette{ID: 1, Age: 20}},
+		{Anquette: domain.Anquette{ID: 2, Age: 40}},
+		{Anquette: domain.Anquette{ID: 3, Age: 30}},
+		{Anquette: domain.Anquette{ID: 4, Age: 20}},
+	}
+	mockRepo := &MockRepo{
+		GetUserFunc: func(ctx context.Context, id int) (domain.User, error) {
+			return domain.User{TgID: 123}, nil
+		},
+		GetFeedFunc: func(ctx context.Context, f domain.FeedFilter) ([]domain.FeedItem, error) {
+			got = f
+			return pool, nil
+		},
+		TouchUserFunc: func(ctx context.Context, tgID int64) error {
+			touched = tgID
+			return nil
+		},
+	}
+	svc := service.NewService(mockRepo)
+	svc.Ranker = stubRanker{}
+
+	feed, err := svc.GetFeed(context.Background(), 123, domain.FeedFilter{Sort: domain.FeedSortScore, Limit: 2})
+	if err != nil {
+		t.Fatalf("Ожидали отсутствие ошибки, получили: %v", err)
+	}
+	// Из БД берется пул кандидатов, наружу - лучшие limit по оценке
+	if got.Limit <= 2 {
+		t.Errorf("Ожидали пул больше limit, получили %d", got.Limit)
+	}
+	if len(feed) != 2 || feed[0].ID != 2 || feed[1].ID != 3 || feed[1].Score != 0.3 {
+		t.Errorf("Ожидали анкеты 2 и 3 по убыванию оценки, получили %+v", feed)
+	}
+	if touched != 123 {
+		t.Errorf("Просмотр ленты должен отмечать активность, получили %d", touched)
+	}
+
+	// Зритель оценил показанные анкеты, и они выпали из пула: следующая страница
+	// после курсора (score, id) последней анкеты ничего не пропускает
+	pool = []domain.FeedItem{pool[0], pool[3]}
+	feed, err = svc.GetFeed(context.Background(), 123, domain.FeedFilter{Sort: domain.FeedSortScore, AfterScore: 0.3, AfterID: 3, Limit: 1})
+	if err != nil {
+		t.Fatalf("Ожидали отсутствие ошибки, получили: %v", err)
+	}
+	if len(feed) != 1 || feed[0].ID != 1 {
+		t.Errorf("Ожидали на второй странице анкету 1, получили %+v", feed)
+	}
+	// При равной оценке порядок - по ID
+	feed, _ = svc.GetFeed(context.Background(), 123, domain.FeedFilter{Sort: domain.FeedSortScore, AfterScore: 0.2, AfterID: 1, Limit: 2})
+	if len(feed) != 1 || feed[0].ID != 4 {
+		t.Errorf("Ожидали на третьей странице анкету 4, получили %+v", feed)
+	}
+
+	ranked, err := svc.ExplainFeed(context.Background(), 123, domain.FeedFilter{Limit: 3})
+	if err != nil {
+		t.Fatalf("Ожидали отсутствие ошибки, получили: %v", err)
+	}
+	if len(ranked) != 2 || ranked[0].Score != 0.2 || ranked[1].ID != 4 {
+		t.Errorf("Неверное объяснение ленты: %+v", ranked)
+	}
+}
+
+func TestServiceImpl_NormalizeCities(t *testing.T) {
+	updated := map[int]string{}
+	mockRepo := &MockRepo{
+		ListAnquettesWithoutCityIDFunc: func(ctx context.Context, afterID, limit int) ([]domain.Anquette, error) {
+			if afterID > 0 {
+				return nil, nil
+			}
+			return []domain.Anquette{{ID: 1, City: "екб"}, {ID: 2, City: "Урюпинск"}}, nil
+		},
+		SetAnquetteCityFunc: func(ctx context.Context, id int, city, cityID string) error {
+			updated[id] = city + "/" + cityID
+			return nil
+		},
+	}
+	svc := service.NewService(mockRepo)
+	svc.Cities = gazetteer.Default()
+
+	if err := svc.NormalizeCities(context.Background()); err != nil {
+		t.Fatalf("Ожидали отсутствие ошибки, получили: %v", err)
+	}
+	if len(updated) != 1 || updated[1] != "Екатеринбург/yekaterinburg" {
+		t.Errorf("Ожидали обновление только анкеты 1, получили %v", updated)
+	}
+}
+
+func TestServiceImpl_GetFeed_InvalidAgeRange(t *testing.T) {
+	svc := service.NewService(&MockRepo{})
+
+	_, err := svc.GetFeed(context.Background(), 123, domain.FeedFilter{AgeMin: 30, AgeMax: 20})
+
+	if !errors.Is(err, service.ErrValidationFailed) {
+		t.Errorf("Ожидали ошибку service.ErrValidationFailed, получили: %v", err)
+	}
+}
+
+func TestServiceImpl_GetFeed_Distance(t *testing.T) {
+	var got domain.FeedFilter
+	viewer := domain.Anquette{ID: 7, Age: 20, City: "Новороссийск", Location: &domain.GeoPoint{Lat: 44.72, Lon: 37.77}}
+	mockRepo := &MockRepo{
+		GetUserFunc: func(ctx context.Context, id int) (domain.User, error) {
+			return domain.User{TgID: 123, AnquetteID: 7}, nil
+		},
+		GetAnquetteFunc: func(ctx context.Context, id int) (domain.Anquette, error) {
+			return viewer, nil
+		},
+		GetFeedFunc: func(ctx context.Context, f domain.FeedFilter) ([]domain.FeedItem, error) {
+			got = f
+			return []domain.FeedItem{}, nil
+		},
+	}
+	svc := service.NewService(mockRepo)
+
+	if _, err := svc.GetFeed(context.Background(), 123, domain.FeedFilter{MaxDistanceKm: 50}); err != nil {
+		t.Fatalf("Ожидали отсутствие ошибки, получили: %v", err)
+	}
+	// Расстояние считается от анкеты зрителя, город зрителя не подставляется
+	if got.Origin == nil || *got.Origin != *viewer.Location || got.City != "" {
+		t.Errorf("Неверный фильтр по расстоянию: %+v", got)
+	}
+
+	// Без геопозиции искать по расстоянию нельзя
+	viewer.Location = nil
+	_, err := svc.GetFeed(context.Background(), 123, domain.FeedFilter{Sort: domain.FeedSortDistance})
+	var verr *service.ValidationError
+	if !errors.As(err, &verr) || verr.Violations[0].Code != domain.CodeRequired {
+		t.Errorf("Ожидали нарушение required, получили: %v", err)
+	}
+}
+
+func TestServiceImpl_GetFeed_InvalidDistance(t *testing.T) {
+	svc := service.NewService(&MockRepo{})
+
+	for _, f := range []domain.FeedFilter{
+		{MaxDistanceKm: -1},
+		{MaxDistanceKm: service.MaxFeedDistanceKm + 1},
+		{Sort: "random"},
+		{Sort: domain.FeedSortScore, AfterScore: -1},
+		{Sort: domain.FeedSortScore, AfterScore: 2},
+		{AfterScore: 0.5}, // after_score только для sort=score
+	} {
+		if _, err := svc.GetFeed(context.Background(), 123, f); !errors.Is(err, service.ErrValidationFailed) {
+			t.Errorf("Фильтр %+v: ожидали service.ErrValidationFailed, получили: %v", f, err)
+		}
+	}
+}
+
+// --- ТЕСТЫ LOCATION ---
+
+func TestServiceImpl_SetAnquetteLocation_Rounds(t *testing.T) {
+	var got *domain.GeoPoint
+	mockRepo := &MockRepo{
+		SetAnquetteLocationFunc: func(ctx context.Context, id int, p *domain.GeoPoint) error {
+			got = p
+			return nil
+		},
+	}
+	svc := service.NewService(mockRepo)
+
+	err := svc.SetAnquetteLocation(context.Background(), 7, domain.LocationRequest{Latitude: 44.723571, Longitude: 37.768642})
+	if err != nil {
+		t.Fatalf("Ожидали отсутствие ошибки, получили: %v", err)
+	}
+	// Точные координаты не сохраняются
+	if got == nil || got.Lat != 44.72 || got.Lon != 37.77 {
+		t.Errorf("Ожидали координаты с точностью 0.01, получили %+v", got)
+	}
+}
+
+// interestsCatalog - справочник интересов для тестов
+func interestsCatalog(ctx context.Context) ([]domain.Interest, error) {
+	return []domain.Interest{{ID: "music"}, {ID: "it"}, {ID: "travel"}}, nil
+}
+
+func TestServiceImpl_SetAnquetteInterests(t *testing.T) {
+	var got []string
+	mockRepo := &MockRepo{
+		ListInterestsFunc: interestsCatalog,
+		SetAnquetteInterestsFunc: func(ctx context.Context, id int, interestIDs []string) error {
+			got = interestIDs
+			return nil
+		},
+	}
+	svc := service.NewService(mockRepo)
+
+	err := svc.SetAnquetteInterests(context.Background(), 7, domain.InterestsRequest{Interests: []string{" Music", "it", "music", ""}})
+	if err != nil {
+		t.Fatalf("Ожидали отсутствие ошибки, получили: %v", err)
+	}
+	if len(got) != 2 || got[0] != "music" || got[1] != "it" {
+		t.Errorf("Ожидали интересы без повторов, получили %v", got)
+	}
+
+	// Интересы не из справочника не сохраняются
+	got = nil
+	err = svc.SetAnquetteInterests(context.Background(), 7, domain.InterestsRequest{Interests: []string{"music", "knitting"}})
+	var verr *service.ValidationError
+	if !errors.As(err, &verr) || len(verr.Violations) != 1 || verr.Violations[0].Args[0] != "knitting" {
+		t.Errorf("Ожидали нарушение для knitting, получили: %v", err)
+	}
+	if got != nil {
+		t.Errorf("Интересы не должны сохраняться при ошибке: %v", got)
+	}
+
+	many := make([]string, service.MaxInterests+1)
+	for i := range many {
+		many[i] = fmt.Sprintf("i%d", i)
+	}
+	err = svc.SetAnquetteInterests(context.Background(), 7, domain.InterestsRequest{Interests: many})
+	if !errors.As(err, &verr) || verr.Violations[0].Code != domain.CodeOutOfRange {
+		t.Errorf("Ожидали нарушение out_of_range, получили: %v", err)
+	}
+}
+
+func TestServiceImpl_SetAnquetteLocation_OutOfRange(t *testing.T) {
+	svc := service.NewService(&MockRepo{})
+
+	err := svc.SetAnquetteLocation(context.Background(), 7, domain.LocationRequest{Latitude: 91, Longitude: -181})
+
+	var verr *service.ValidationError
+	if !errors.As(err, &verr) || len(verr.Violations) != 2 {
+		t.Errorf("Ожидали нарушения для latitude и longitude, получили: %v", err)
+	}
+}
+
+func TestServiceImpl_InsertAnquette_ContentFilter(t *testing.T) {
+	var gotStatus string
+	mockRepo := &MockRepo{
+		InsertAnquetteFunc: func(ctx context.Context, a domain.AnquetteRequest) (int, error) {
+			gotStatus = a.Status
+			return 5, nil
+		},
+	}
+	svc := service.NewService(mockRepo)
+	svc.Filter = textfilter.New(textfilter.DefaultConfig())
+
+	// Мат - отказ без сохранения
+	rude := domain.AnquetteRequest{Name: "Вася", Age: 30, Gender: "m", City: "Сочи", Description: "Ищу нормальную девушку, а не всякую хуйню, пишите мне в любое время"}
+	if _, err := svc.InsertAnquette(context.Background(), rude); !errors.Is(err, service.ErrValidationFailed) {
+		t.Errorf("Ожидали ошибку service.ErrValidationFailed, получили: %v", err)
+	}
+
+	// Телефон - анкета сохраняется, но уходит на модерацию
+	phone := domain.AnquetteRequest{Name: "Петя", Age: 30, Gender: "m", City: "Сочи", Description: "Люблю горы и велосипед, звоните по номеру +7 912 345-67-89 вечером"}
+	if _, err := svc.InsertAnquette(context.Background(), phone); err != nil {
+		t.Fatalf("Ожидали отсутствие ошибки, получили: %v", err)
+	}
+	if gotStatus != domain.AnquetteStatusPending {
+		t.Errorf("Ожидали статус %q, получили %q", domain.AnquetteStatusPending, gotStatus)
+	}
+
+	// Отказ объясняется по полям
+	var verr *service.ValidationError
+	_, err := svc.InsertAnquette(context.Background(), rude)
+	if !errors.As(err, &verr) || verr.Violations[0].Field != "description" || verr.Violations[0].Code != domain.CodeForbiddenContent {
+		t.Errorf("Ожидали нарушение forbidden_content в description, получили: %v", err)
+	}
+}
+
+// --- ТЕСТЫ REACTION ---
+
+// newReactionRepo - мок с двумя пользователями: 1 (анкета 10) и 2 (анкета 20)
+func newReactionRepo(mutual bool) *MockRepo {
+	return &MockRepo{
+		GetUserFunc: func(ctx context.Context, id int) (domain.User, error) {
+			return domain.User{TgID: int64(id), AnquetteID: id * 10}, nil
+		},
+		GetAnquetteFunc: func(ctx context.Context, id int) (domain.Anquette, error) {
+			return domain.Anquette{ID: id, Status: domain.AnquetteStatusActive}, nil
+		},
+		InsertReactionFunc: func(ctx context.Context, r domain.ReactionRequest) (domain.Reaction, error) {
+			return domain.Reaction{ID: 1, FromTgID: r.TgID, ToAnquetteID: r.AnquetteID, Kind: r.Kind}, nil
+		},
+		GetUserByAnquetteFunc: func(ctx context.Context, anquetteID int) (domain.User, error) {
+			return domain.User{TgID: int64(anquetteID / 10), AnquetteID: anquetteID}, nil
+		},
+		InsertReactionAndMatchFunc: func(ctx context.Context, r domain.ReactionRequest, matchTgID int64, matchAnquetteID int) (domain.Reaction, *domain.Match, error) {
+			reaction := domain.Reaction{ID: 1, FromTgID: r.TgID, ToAnquetteID: r.AnquetteID, Kind: r.Kind}
+			if !mutual || matchTgID != 2 || matchAnquetteID != 10 {
+				return reaction, nil, nil
+			}
+			return reaction, &domain.Match{ID: 3, UserTgID1: r.TgID, UserTgID2: matchTgID}, nil
+		},
+	}
+}
+
+func TestServiceImpl_React_MutualLikeCreatesMatch(t *testing.T) {
+	svc := service.NewService(newReactionRepo(true))
+
+	res, err := svc.React(context.Background(), 1, 20, domain.ReactionLike)
+
+	if err != nil {
+		t.Fatalf("Ожидали отсутствие ошибки, получили: %v", err)
+	}
+	if res.Match == nil || res.Match.ID != 3 {
+		t.Errorf("Ожидали мэтч с ID 3, получили %+v", res.Match)
+	}
+}
+
+func TestServiceImpl_React_OneSidedLike(t *testing.T) {
+	svc := service.NewService(newReactionRepo(false))
+
+	res, err := svc.React(context.Background(), 1, 20, domain.ReactionLike)
+
+	if err != nil {
+		t.Fatalf("Ожидали отсутствие ошибки, получили: %v", err)
+	}
+	if res.Match != nil {
+		t.Errorf("Не ожидали мэтч, получили %+v", res.Match)
+	}
+}
+
+func TestServiceImpl_React_Duplicate(t *testing.T) {
+	mockRepo := newReactionRepo(false)
+	mockRepo.InsertReactionFunc = func(ctx context.Context, r domain.ReactionRequest) (domain.Reaction, error) {
+		return domain.Reaction{}, repository.ErrDuplicate
+	}
+	svc := service.NewService(mockRepo)
+
+	_, err := svc.React(context.Background(), 1, 20, domain.ReactionDislike)
+
+	if !errors.Is(err, service.ErrAlreadyExists) {
+		t.Errorf("Ожидали ошибку service.ErrAlreadyExists, получили: %v", err)
+	}
+}
+
+func TestServiceImpl_React_Blocked(t *testing.T) {
+	mockRepo := newReactionRepo(true)
+	mockRepo.IsBlockedFunc = func(ctx context.Context, tgID1, tgID2 int64) (bool, error) {
+		return true, nil
+	}
+	mockRepo.InsertReactionFunc = func(ctx context.Context, r domain.ReactionRequest) (domain.Reaction, error) {
+		t.Fatal("Реакция не должна сохраняться при блокировке")
+		return domain.Reaction{}, nil
+	}
+	svc := service.NewService(mockRepo)
+
+	_, err := svc.React(context.Background(), 1, 20, domain.ReactionLike)
+
+	if !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("Ожидали ошибку service.ErrNotFound, получили: %v", err)
+	}
+}
+
+// --- ТЕСТЫ BLOCK / REPORT ---
+
+func TestServiceImpl_Block_ByAnquette(t *testing.T) {
+	mockRepo := newReactionRepo(false)
+	var blocker, blocked int64
+	mockRepo.InsertBlockFunc = func(ctx context.Context, blockerTgID, blockedTgID int64) (domain.Block, error) {
+		blocker, blocked = blockerTgID, blockedTgID
+		return domain.Block{BlockerTgID: blockerTgID, BlockedTgID: blockedTgID}, nil
+	}
+	svc := service.NewService(mockRepo)
+
+	_, err := svc.Block(context.Background(), domain.BlockRequest{TgID: 1, AnquetteID: 20})
+
+	if err != nil {
+		t.Fatalf("Ожидали отсутствие ошибки, получили: %v", err)
+	}
+	if blocker != 1 || blocked != 2 {
+		t.Errorf("Ожидали блокировку 1 -> 2, получили %d -> %d", blocker, blocked)
+	}
+}
+
+func TestServiceImpl_Block_Self(t *testing.T) {
+	svc := service.NewService(newReactionRepo(false))
+
+	_, err := svc.Block(context.Background(), domain.BlockRequest{TgID: 1, TargetTgID: 1})
+
+	if !errors.Is(err, service.ErrValidationFailed) {
+		t.Errorf("Ожидали ошибку service.ErrValidationFailed, получили: %v", err)
+	}
+}
+
+func TestServiceImpl_Report_Validation(t *testing.T) {
+	svc := service.NewService(newReactionRepo(false))
+
+	cases := []domain.ReportRequest{
+		{TgID: 1, TargetTgID: 2, Reason: "boring"},
+		{TgID: 1, TargetTgID: 2, Reason: domain.ReportReasonOther, Note: strings.Repeat("я", service.MaxReportNoteLength+1)},
+		{TgID: 1, Reason: domain.ReportReasonSpam},
+	}
+	for _, req := range cases {
+		if _, err := svc.Report(context.Background(), req); !errors.Is(err, service.ErrValidationFailed) {
+			t.Errorf("Ожидали ошибку service.ErrValidationFailed для %+v, получили: %v", req, err)
+		}
+	}
+}
+
+func TestServiceImpl_Report_Success(t *testing.T) {
+	mockRepo := newReactionRepo(false)
+	mockRepo.InsertReportFunc = func(ctx context.Context, r domain.Report) (domain.Report, error) {
+		r.ID = 5
+		r.Status = domain.ReportStatusPending
+		return r, nil
+	}
+	svc := service.NewService(mockRepo)
+
+	r, err := svc.Report(context.Background(), domain.ReportRequest{TgID: 1, AnquetteID: 20, Reason: domain.ReportReasonFake})
+
+	if err != nil {
+		t.Fatalf("Ожидали отсутствие ошибки, получили: %v", err)
+	}
+	if r.ReportedTgID != 2 || r.ReportedAnquetteID != 20 {
+		t.Errorf("Ожидали жалобу на пользователя 2 и анкету 20, получили %+v", r)
+	}
+}
+
+// --- ТЕСТЫ MODERATION ---
+
+func TestServiceImpl_Moderate_RejectNeedsReason(t *testing.T) {
+	svc := service.NewService(newReactionRepo(false))
+
+	_, err := svc.Moderate(context.Background(), domain.ModerationRequest{Action: domain.ModerationReject, AnquetteID: 20, Reason: "  "})
+
+	if !errors.Is(err, service.ErrValidationFailed) {
+		t.Errorf("Ожидали ошибку service.ErrValidationFailed, получили: %v", err)
+	}
+}
+
+func TestServiceImpl_Moderate_BanByReport(t *testing.T) {
+	mockRepo := newReactionRepo(false)
+	mockRepo.GetReportFunc = func(ctx context.Context, id int) (domain.Report, error) {
+		return domain.Report{ID: id, ReporterTgID: 1, ReportedTgID: 2}, nil
+	}
+	var gotStatus string
+	mockRepo.ApplyModerationDecisionFunc = func(ctx context.Context, d domain.ModerationDecision, anquetteStatus string) (domain.ModerationDecision, error) {
+		gotStatus = anquetteStatus
+		d.ID = 1
+		return d, nil
+	}
+	svc := service.NewService(mockRepo)
+	ctx := auth.WithAPIKey(context.Background(), domain.APIKey{Name: "moderator"})
+
+	d, err := svc.Moderate(ctx, domain.ModerationRequest{Action: domain.ModerationBan, ReportID: 7})
+
+	if err != nil {
+		t.Fatalf("Ожидали отсутствие ошибки, получили: %v", err)
+	}
+	// Анкета берется у пользователя, на которого пожаловались
+	if d.TgID != 2 || d.AnquetteID != 20 || d.ReportID != 7 || d.Moderator != "moderator" {
+		t.Errorf("Неверное решение: %+v", d)
+	}
+	if gotStatus != domain.AnquetteStatusHidden {
+		t.Errorf("Ожидали статус анкеты %q, получили %q", domain.AnquetteStatusHidden, gotStatus)
+	}
+}
+
+// --- ТЕСТЫ ВЛАДЕНИЯ ---
+
+func TestServiceImpl_DeleteAnquette_NotOwner(t *testing.T) {
+	mockRepo := &MockRepo{
+		GetUserFunc: func(ctx context.Context, id int) (domain.User, error) {
+			return domain.User{TgID: int64(id), AnquetteID: 10}, nil
+		},
+		DeleteAnquetteFunc: func(ctx context.Context, id int) error {
+			t.Error("Чужая анкета не должна удаляться")
+			return nil
+		},
+	}
+	svc := service.NewService(mockRepo)
+	ctx := auth.WithTgID(context.Background(), 1)
+
+	err := svc.DeleteAnquette(ctx, 20)
+
+	if !errors.Is(err, service.ErrForbidden) {
+		t.Errorf("Ожидали ошибку service.ErrForbidden, получили: %v", err)
+	}
+}
+
+func TestServiceImpl_GetAnquette_Visibility(t *testing.T) {
+	mockRepo := newReactionRepo(false)
+	status := domain.AnquetteStatusRejected
+	mockRepo.GetAnquetteFunc = func(ctx context.Context, id int) (domain.Anquette, error) {
+		return domain.Anquette{ID: id, Status: status}, nil
+	}
+	mockRepo.LastRejectionReasonFunc = func(ctx context.Context, anquetteID int) (string, error) {
+		return "фото не соответствует правилам", nil
+	}
+	blocked := false
+	mockRepo.IsBlockedFunc = func(ctx context.Context, tgID1, tgID2 int64) (bool, error) {
+		return blocked, nil
+	}
+	svc := service.NewService(mockRepo)
+	stranger := auth.WithTgID(context.Background(), 1)
+
+	// Владелец видит свою отклоненную анкету с причиной отказа
+	a, err := svc.GetAnquette(auth.WithTgID(context.Background(), 2), 20)
+	if err != nil || a.RejectionReason != "фото не соответствует правилам" {
+		t.Errorf("Ожидали анкету с причиной отказа, получили %+v, %v", a, err)
+	}
+	// Для остальных пользователей ее нет
+	if _, err := svc.GetAnquette(stranger, 20); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("Ожидали ошибку service.ErrNotFound для отклоненной анкеты, получили: %v", err)
+	}
+
+	status = domain.AnquetteStatusActive
+	if _, err := svc.GetAnquette(stranger, 20); err != nil {
+		t.Errorf("Ожидали активную анкету, получили: %v", err)
+	}
+	blocked = true
+	if _, err := svc.GetAnquette(stranger, 20); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("Ожидали ошибку service.ErrNotFound при блокировке, получили: %v", err)
+	}
+}
+
+func TestServiceImpl_React_AsAnotherUser(t *testing.T) {
+	svc := service.NewService(newReactionRepo(true))
+	ctx := auth.WithTgID(context.Background(), 2)
+
+	_, err := svc.React(ctx, 1, 20, domain.ReactionLike)
+
+	if !errors.Is(err, service.ErrForbidden) {
+		t.Errorf("Ожидали ошибку service.ErrForbidden, получили: %v", err)
+	}
+}
+
+func TestServiceImpl_BannedUserViaAPIKey(t *testing.T) {
+	bannedAt := time.Now()
+	banned := domain.User{TgID: 1, AnquetteID: 10, BannedAt: &bannedAt}
+	mockRepo := newReactionRepo(true)
+	mockRepo.GetUserFunc = func(ctx context.Context, id int) (domain.User, error) {
+		if id == 1 {
+			return banned, nil
+		}
+		return domain.User{}, sql.ErrNoRows
+	}
+	mockRepo.GetUserByAnquetteFunc = func(ctx context.Context, anquetteID int) (domain.User, error) {
+		return banned, nil
+	}
+	svc := service.NewService(mockRepo)
+	// Бот с ключом API: tg_id в контексте нет, но бан все равно действует
+	ctx := auth.WithAPIKey(context.Background(), domain.APIKey{Name: "bot"})
+
+	if _, err := svc.React(ctx, 1, 20, domain.ReactionLike); !errors.Is(err, service.ErrUserBanned) {
+		t.Errorf("React: ожидали service.ErrUserBanned, получили: %v", err)
+	}
+	if _, err := svc.Block(ctx, domain.BlockRequest{TgID: 1, TargetTgID: 2}); !errors.Is(err, service.ErrUserBanned) {
+		t.Errorf("Block: ожидали service.ErrUserBanned, получили: %v", err)
+	}
+	if err := svc.UpdateUser(ctx, 1, domain.UserRequest{TgID: 1, AnquetteID: 11}); !errors.Is(err, service.ErrUserBanned) {
+		t.Errorf("UpdateUser: ожидали service.ErrUserBanned, получили: %v", err)
+	}
+	if err := svc.UpdateAnquette(ctx, 10, domain.AnquetteRequest{Name: "Имя", Age: 20, Description: "описание"}); !errors.Is(err, service.ErrUserBanned) {
+		t.Errorf("UpdateAnquette: ожидали service.ErrUserBanned, получили: %v", err)
+	}
+}
+
+// --- ТЕСТЫ API KEY ---
+
+func TestServiceImpl_CreateAPIKey_UnknownScope(t *testing.T) {
+	svc := service.NewService(&MockRepo{})
+
+	_, err := svc.CreateAPIKey(context.Background(), domain.APIKeyRequest{Name: "bot", Scopes: []string{"users:delete"}})
+
+	if !errors.Is(err, service.ErrValidationFailed) {
+		t.Errorf("Ожидали ошибку service.ErrValidationFailed, получили: %v", err)
+	}
+}
+
+func TestServiceImpl_AuthenticateAPIKey_Revoked(t *testing.T) {
+	revokedAt := time.Now()
+	mockRepo := &MockRepo{
+		GetAPIKeyByHashFunc: func(ctx context.Context, keyHash string) (domain.APIKey, error) {
+			if keyHash != auth.HashAPIKey("bk_old") {
+				return domain.APIKey{}, sql.ErrNoRows
+			}
+			return domain.APIKey{ID: 1, RevokedAt: &revokedAt}, nil
+		},
+	}
+	svc := service.NewService(mockRepo)
+
+	_, err := svc.AuthenticateAPIKey(context.Background(), "bk_old")
+
+	if !errors.Is(err, service.ErrUnauthorized) {
+		t.Errorf("Ожидали ошибку service.ErrUnauthorized, получили: %v", err)
+	}
+}
+
+// --- ТЕСТЫ PHOTO ---
+
+// testPNG - маленькое валидное PNG-изображение
+func testPNG() []byte {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 8, 8)))
+	return buf.Bytes()
+}
+
+func newPhotoService(t *testing.T, existing int) *service.ServiceImpl {
+	blobs, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore провалился: %v", err)
+	}
+	mockRepo := &MockRepo{
+		GetAnquetteFunc: func(ctx context.Context, id int) (domain.Anquette, error) {
+			return domain.Anquette{ID: id}, nil
+		},
+		ListPhotosFunc: func(ctx context.Context, anquetteID int) ([]domain.Photo, error) {
+			return make([]domain.Photo, existing), nil
+		},
+		InsertPhotoFunc: func(ctx context.Context, p domain.Photo) (domain.Photo, error) {
+			p.ID = 11
+			return p, nil
+		},
+	}
+	svc := service.NewService(mockRepo)
+	svc.Blobs = blobs
+	return svc
+}
+
+func TestServiceImpl_UploadPhoto_Success(t *testing.T) {
+	svc := newPhotoService(t, 0)
+
+	p, err := svc.UploadPhoto(context.Background(), 1, testPNG())
+
+	if err != nil {
+		t.Fatalf("Ожидали отсутствие ошибки, получили: %v", err)
+	}
+	if p.ContentType != "image/png" || p.URL != "/api/v1/photos/11" || p.Width != 8 {
+		t.Errorf("Неверное фото: %+v", p)
+	}
+	if len(p.Variants) != 2 || p.Variants[0].URL == "" {
+		t.Errorf("Ожидали превью и миниатюру со ссылками, получили %+v", p.Variants)
+	}
+}
+
+func TestServiceImpl_UploadPhoto_NotAnImage(t *testing.T) {
+	svc := newPhotoService(t, 0)
+
+	_, err := svc.UploadPhoto(context.Background(), 1, []byte("#!/bin/sh\nrm -rf /"))
+
+	if !errors.Is(err, service.ErrValidationFailed) {
+		t.Errorf("Ожидали ошибку service.ErrValidationFailed, получили: %v", err)
+	}
+}
+
+func TestServiceImpl_UploadPhoto_LimitReached(t *testing.T) {
+	svc := newPhotoService(t, service.MaxPhotosPerAnquette)
+
+	_, err := svc.UploadPhoto(context.Background(), 1, testPNG())
+
+	if !errors.Is(err, service.ErrValidationFailed) {
+		t.Errorf("Ожидали ошибку service.ErrValidationFailed, получили: %v", err)
+	}
+}
+
+// --- ТЕСТЫ LISTS ---
+
+func TestServiceImpl_ListAnquettes_Defaults(t *testing.T) {
+	var got domain.ListFilter
+	mockRepo := &MockRepo{
+		ListAnquettesFunc: func(ctx context.Context, f domain.ListFilter) (domain.Page[domain.Anquette], error) {
+			got = f
+			return domain.Page[domain.Anquette]{Items: []domain.Anquette{{ID: 1}}}, nil
+		},
+	}
+	svc := service.NewService(mockRepo)
+	svc.Cities = gazetteer.Default()
+
+	if _, err := svc.ListAnquettes(context.Background(), domain.ListFilter{City: " москва ", Gender: "Ж", Limit: 1000}); err != nil {
+		t.Fatalf("Ожидали отсутствие ошибки, получили: %v", err)
+	}
+	if got.CityID != "moscow" || got.City != "" || got.Gender != "f" || got.Sort != domain.ListSortCreated || got.Limit != 200 {
+		t.Errorf("Неверный фильтр для репозитория: %+v", got)
+	}
+}
+
+func TestServiceImpl_ListUsers_Invalid(t *testing.T) {
+	svc := service.NewService(&MockRepo{})
+
+	for _, f := range []domain.ListFilter{
+		{Sort: domain.ListSortAge}, // возраст есть только у анкет
+		{Sort: "-name"},
+		{AgeMin: 30, AgeMax: 20},
+		{Gender: "x"},
+	} {
+		if _, err := svc.ListUsers(context.Background(), f); !errors.Is(err, service.ErrValidationFailed) {
+			t.Errorf("Ожидали ошибку валидации для %+v, получили: %v", f, err)
+		}
+	}
+}
+
+func TestServiceImpl_ListUsers_InvalidCursor(t *testing.T) {
+	mockRepo := &MockRepo{
+		ListUsersFunc: func(ctx context.Context, f domain.ListFilter) (domain.Page[domain.User], error) {
+			return domain.Page[domain.User]{}, repository.ErrInvalidCursor
+		},
+	}
+	svc := service.NewService(mockRepo)
+
+	_, err := svc.ListUsers(context.Background(), domain.ListFilter{Cursor: "мусор"})
+
+	var verr *service.ValidationError
+	if !errors.As(err, &verr) || verr.Violations[0].Field != "cursor" {
+		t.Errorf("Ожидали нарушение для cursor, получили: %v", err)
+	}
+}
+
+// --- ТЕСТЫ SEARCH ---
+
+func TestServiceImpl_SearchAnquettes_StatusByCaller(t *testing.T) {
+	var got domain.SearchFilter
+	mockRepo := &MockRepo{
+		SearchAnquettesFunc: func(ctx context.Context, f domain.SearchFilter) ([]domain.SearchHit, error) {
+			got = f
+			return []domain.SearchHit{}, nil
+		},
+	}
+	svc := service.NewService(mockRepo)
+
+	// Ключ API с правом admin ищет среди анкет любого статуса
+	admin := auth.WithAPIKey(context.Background(), domain.APIKey{Name: "moderator", Scopes: []string{auth.ScopeAdmin}})
+	if _, err := svc.SearchAnquettes(admin, domain.SearchFilter{Query: " музыка ", Limit: 1000}); err != nil {
+		t.Fatalf("Ожидали отсутствие ошибки, получили: %v", err)
+	}
+	if got.Query != "музыка" || got.Status != "" || got.Limit != 50 {
+		t.Errorf("Неверный фильтр для репозитория: %+v", got)
+	}
+	if _, err := svc.SearchAnquettes(admin, domain.SearchFilter{Query: "музыка", Status: domain.AnquetteStatusHidden}); err != nil || got.Status != domain.AnquetteStatusHidden {
+		t.Errorf("Ожидали поиск скрытых анкет, получили %+v (%v)", got, err)
+	}
+
+	// Ключ бота без admin - только среди активных, с блокировками пользователя tg_id
+	bot := auth.WithAPIKey(context.Background(), domain.APIKey{Name: "bot", Scopes: []string{auth.ScopeAnquettesRead}})
+	if _, err := svc.SearchAnquettes(bot, domain.SearchFilter{Query: "музыка", ViewerTgID: 7}); err != nil {
+		t.Fatalf("Ожидали отсутствие ошибки, получили: %v", err)
+	}
+	if got.Status != domain.AnquetteStatusActive || got.ViewerTgID != 7 {
+		t.Errorf("Ожидали поиск активных анкет для пользователя 7, получили %+v", got)
+	}
+	if _, err := svc.SearchAnquettes(bot, domain.SearchFilter{Query: "музыка", Status: domain.AnquetteStatusPending}); !errors.Is(err, service.ErrForbidden) {
+		t.Errorf("Ожидали ErrForbidden для поиска по статусу без admin, получили: %v", err)
+	}
+
+	// Пользователь Mini App - только среди активных и от своего имени
+	ctx := auth.WithTgID(context.Background(), 123)
+	if _, err := svc.SearchAnquettes(ctx, domain.SearchFilter{Query: "музыка"}); err != nil {
+		t.Fatalf("Ожидали отсутствие ошибки, получили: %v", err)
+	}
+	if got.Status != domain.AnquetteStatusActive || got.ViewerTgID != 123 || got.Limit != 20 {
+		t.Errorf("Ожидали поиск активных анкет для пользователя 123, получили %+v", got)
+	}
+	if _, err := svc.SearchAnquettes(ctx, domain.SearchFilter{Query: "музыка", Status: domain.AnquetteStatusHidden}); !errors.Is(err, service.ErrForbidden) {
+		t.Errorf("Ожидали ErrForbidden для поиска скрытых анкет, получили: %v", err)
+	}
+	if _, err := svc.SearchAnquettes(ctx, domain.SearchFilter{Query: "музыка", ViewerTgID: 456}); !errors.Is(err, service.ErrForbidden) {
+		t.Errorf("Ожидали ErrForbidden для поиска от чужого имени, получили: %v", err)
+	}
+}
+
+func TestServiceImpl_SearchAnquettes_Invalid(t *testing.T) {
+	svc := service.NewService(&MockRepo{})
+
+	for _, f := range []domain.SearchFilter{
+		{Query: "  "},
+		{Query: "?!"},
+		{Query: strings.Repeat("музыка ", 50)},
+		{Query: "музыка", Status: "deleted"},
+	} {
+		if _, err := svc.SearchAnquettes(context.Background(), f); !errors.Is(err, service.ErrValidationFailed) {
+			t.Errorf("Ожидали ошибку валидации для %q/%q, получили: %v", f.Query, f.Status, err)
+		}
+	}
+}
+
+// --- ТЕСТЫ METRICS ---
+
+func TestInstrumented_CountsErrorsByKind(t *testing.T) {
+	mockRepo := &MockRepo{
+		GetUserFunc: func(ctx context.Context, id int) (domain.User, error) {
+			if id == 1 {
+				return domain.User{}, sql.ErrNoRows
+			}
+			return domain.User{}, errors.New("disk I/O error")
+		},
+	}
+	reg := metrics.NewRegistry()
+	svc := service.Instrumented(service.NewService(mockRepo), reg)
+
+	svc.GetUser(context.Background(), 1)
+	svc.GetUser(context.Background(), 1)
+	svc.GetUser(context.Background(), 2)
+	svc.InsertUser(context.Background(), domain.UserRequest{})
+
+	var b strings.Builder
+	reg.WriteTo(context.Background(), &b)
+	for _, want := range []string{
+		`service_errors_total{method="GetUser",kind="internal_error"} 1`,
+		`service_errors_total{method="GetUser",kind="not_found"} 2`,
+		`service_errors_total{method="InsertUser",kind="validation_failed"} 1`,
+	} {
+		if !strings.Contains(b.String(), want+"\n") {
+			t.Errorf("Нет строки %s в метриках:\n%s", want, b.String())
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"bot-api/internal/domain"
+	"bot-api/internal/i18n"
+)
+
+// Ограничения полей анкеты
+const (
+	MinAge            = 18
+	MaxAge            = 100
+	MinNameLength     = 2
+	MaxNameLength     = 50
+	MaxCityLength     = 100
+	MinDescriptionLen = 50
+	MaxDescriptionLen = 2000
+	MaxPreferencesLen = 500 // Preferences.Text
+)
+
+// MaxFeedDistanceKm - наибольший радиус поиска в ленте
+const MaxFeedDistanceKm = 500
+
+// Допустимые Preferences.Goal и Preferences.Dealbreakers
+var (
+	preferenceGoals        = []string{domain.GoalFriendship, domain.GoalDating, domain.GoalStudyBuddy}
+	preferenceDealbreakers = []string{domain.DealbreakerOtherGoal, domain.DealbreakerNoPhotos, domain.DealbreakerNoSharedInterest}
+)
+
+// feedSorts - допустимые FeedFilter.Sort; FeedSortID (пусто) - по умолчанию
+var feedSorts = []string{domain.FeedSortID, domain.FeedSortDistance, domain.FeedSortScore}
+
+// genderAliases - допустимые написания пола; в БД хранится domain.Gender*
+var genderAliases = map[string]string{
+	"m": domain.GenderMale, "male": domain.GenderMale, "м": domain.GenderMale, "муж": domain.GenderMale, "мужской": domain.GenderMale,
+	"f": domain.GenderFemale, "female": domain.GenderFemale, "ж": domain.GenderFemale, "жен": domain.GenderFemale, "женский": domain.GenderFemale,
+}
+
+var (
+	cityPattern     = regexp.MustCompile(`^\p{L}[\p{L} .'-]*$`)
+	usernamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{4,31}$`)
+)
+
+// ValidationError - ошибка валидации с нарушениями по полям.
+// errors.Is(err, ErrValidationFailed) для нее истинно.
+type ValidationError struct {
+	Violations []domain.Violation
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, v.Field+": "+v.Code)
+	}
+	return fmt.Sprintf("%s (%s)", ErrValidationFailed, strings.Join(parts, ", "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidationFailed
+}
+
+// invalid - ошибка валидации с одним нарушением; key и args - сообщение из каталога i18n
+func invalid(field, code, key string, args ...any) error {
+	return &ValidationError{Violations: []domain.Violation{{Field: field, Code: code, Key: key, Args: args}}}
+}
+
+// validator - собирает нарушения, чтобы вернуть их все разом
+type validator struct {
+	violations []domain.Violation
+}
+
+func (v *validator) add(field, code, key string, args ...any) {
+	v.violations = append(v.violations, domain.Violation{Field: field, Code: code, Key: key, Args: args})
+}
+
+// length - проверяет длину строки в символах; пустая строка - CodeRequired.
+// Название поля в сообщении берется из каталога i18n по ключу "field.<field>".
+func (v *validator) length(field, value string, minLen, maxLen int) {
+	label := i18n.Key("field." + field)
+	n := utf8.RuneCountInString(value)
+	switch {
+	case n == 0:
+		v.add(field, domain.CodeRequired, "violation.required", label)
+	case n < minLen:
+		v.add(field, domain.CodeTooShort, "violation.too_short", label, minLen)
+	case n > maxLen:
+		v.add(field, domain.CodeTooLong, "violation.too_long", label, maxLen)
+	}
+}
+
+// preferences - проверяет предпочтения анкеты. Пол приводится к domain.Gender*,
+// повторы в списках убираются, списки сортируются.
+func (v *validator) preferences(p *domain.Preferences) {
+	var genders []string
+	for _, g := range p.Genders {
+		ng, ok := normalizeGender(g)
+		if !ok {
+			v.add("preferences.genders", domain.CodeInvalid, "violation.gender.invalid")
+			break
+		}
+		if !slices.Contains(genders, ng) {
+			genders = append(genders, ng)
+		}
+	}
+	slices.Sort(genders)
+	p.Genders = genders
+
+	outOfRange := func(age int) bool { return age != 0 && (age < MinAge || age > MaxAge) }
+	if outOfRange(p.AgeMin) || outOfRange(p.AgeMax) {
+		v.add("preferences.age_min", domain.CodeOutOfRange, "violation.age.out_of_range", MinAge, MaxAge)
+	} else if p.AgeMax > 0 && p.AgeMin > p.AgeMax {
+		v.add("preferences.age_max", domain.CodeOutOfRange, "violation.age_max.out_of_range")
+	}
+	if p.MaxDistanceKm < 0 || p.MaxDistanceKm > MaxFeedDistanceKm {
+		v.add("preferences.max_distance_km", domain.CodeOutOfRange, "violation.max_distance_km.out_of_range", MaxFeedDistanceKm)
+	}
+
+	p.Goal = strings.ToLower(strings.TrimSpace(p.Goal))
+	if p.Goal != "" && !slices.Contains(preferenceGoals, p.Goal) {
+		v.add("preferences.goal", domain.CodeInvalid, "violation.goal.invalid", strings.Join(preferenceGoals, ", "))
+	}
+
+	var dealbreakers []string
+	for _, d := range p.Dealbreakers {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if !slices.Contains(preferenceDealbreakers, d) {
+			v.add("preferences.dealbreakers", domain.CodeInvalid, "violation.dealbreaker.invalid", strings.Join(preferenceDealbreakers, ", "))
+			break
+		}
+		if !slices.Contains(dealbreakers, d) {
+			dealbreakers = append(dealbreakers, d)
+		}
+	}
+	slices.Sort(dealbreakers)
+	p.Dealbreakers = dealbreakers
+
+	p.Text = strings.TrimSpace(p.Text)
+	if p.Text != "" {
+		v.length("preferences.text", p.Text, 1, MaxPreferencesLen)
+	}
+}
+
+// err - nil, если нарушений нет
+func (v *validator) err() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return &ValidationError{Violations: v.violations}
+}
+
+// normalizeGender - domain.Gender* для допустимого написания пола
+func normalizeGender(g string) (string, bool) {
+	norm, ok := genderAliases[strings.ToLower(strings.TrimSpace(g))]
+	return norm, ok
+}
+
+// validateAnquette - проверяет и нормализует поля анкеты
+func validateAnquette(req *domain.AnquetteRequest) error {
+	var v validator
+
+	req.Name = strings.TrimSpace(req.Name)
+	v.length("name", req.Name, MinNameLength, MaxNameLength)
+
+	if req.Age < MinAge || req.Age > MaxAge {
+		v.add("age", domain.CodeOutOfRange, "violation.age.out_of_range", MinAge, MaxAge)
+	}
+
+	if strings.TrimSpace(req.Gender) == "" {
+		v.add("gender", domain.CodeRequired, "violation.gender.required")
+	} else if g, ok := normalizeGender(req.Gender); ok {
+		req.Gender = g
+	} else {
+		v.add("gender", domain.CodeInvalid, "violation.gender.invalid")
+	}
+
+	req.City = strings.TrimSpace(req.City)
+	v.length("city", req.City, 1, MaxCityLength)
+	if req.City != "" && !cityPattern.MatchString(req.City) {
+		v.add("city", domain.CodeInvalid, "violation.city.invalid")
+	}
+
+	v.length("description", strings.TrimSpace(req.Description), MinDescriptionLen, MaxDescriptionLen)
+	v.preferences(&req.Preferences)
+
+	return v.err()
+}
+
+// validateUser - проверяет поля пользователя
+func validateUser(req *domain.UserRequest) error {
+	var v validator
+
+	if req.TgID <= 0 {
+		v.add("tg_id", domain.CodeInvalid, "violation.tg_id.invalid")
+	}
+	req.TgUsername = strings.TrimPrefix(strings.TrimSpace(req.TgUsername), "@")
+	if req.TgUsername != "" && !usernamePattern.MatchString(req.TgUsername) {
+		v.add("tg_username", domain.CodeInvalid, "violation.tg_username.invalid")
+	}
+	if req.AnquetteID < 0 {
+		v.add("anquette_id", domain.CodeInvalid, "violation.anquette_id.invalid")
+	}
+	req.Locale = strings.ToLower(strings.TrimSpace(req.Locale))
+	if req.Locale != "" && !i18n.IsSupported(req.Locale) {
+		v.add("locale", domain.CodeInvalid, "violation.locale.invalid", strings.Join(i18n.Supported, ", "))
+	}
+
+	return v.err()
+}
+
+// validateLocation - проверяет диапазоны широты и долготы
+func validateLocation(req domain.LocationRequest) error {
+	var v validator
+
+	if math.IsNaN(req.Latitude) || req.Latitude < -90 || req.Latitude > 90 {
+		v.add("latitude", domain.CodeOutOfRange, "violation.latitude.out_of_range")
+	}
+	if math.IsNaN(req.Longitude) || req.Longitude < -180 || req.Longitude > 180 {
+		v.add("longitude", domain.CodeOutOfRange, "violation.longitude.out_of_range")
+	}
+
+	return v.err()
+}
+
+// validateFeedFilter - проверяет возрастной диапазон, пол, расстояние и порядок фильтра ленты
+func validateFeedFilter(f *domain.FeedFilter) error {
+	var v validator
+
+	if f.AgeMin < 0 || f.AgeMax < 0 || f.AgeMin > MaxAge || f.AgeMax > MaxAge {
+		v.add("age_min", domain.CodeOutOfRange, "violation.age.out_of_range", 0, MaxAge)
+	} else if f.AgeMax > 0 && f.AgeMin > f.AgeMax {
+		v.add("age_max", domain.CodeOutOfRange, "violation.age_max.out_of_range")
+	}
+	if f.Gender != "" {
+		if g, ok := normalizeGender(f.Gender); ok {
+			f.Gender = g
+		} else {
+			v.add("gender", domain.CodeInvalid, "violation.gender.invalid")
+		}
+	}
+	if f.MaxDistanceKm < 0 || f.MaxDistanceKm > MaxFeedDistanceKm {
+		v.add("max_distance_km", domain.CodeOutOfRange, "violation.max_distance_km.out_of_range", MaxFeedDistanceKm)
+	}
+	if f.AfterDistanceKm < 0 {
+		v.add("after_distance_km", domain.CodeOutOfRange, "violation.after_distance_km.out_of_range")
+	}
+	f.Goal = strings.ToLower(strings.TrimSpace(f.Goal))
+	if f.Goal != "" && !slices.Contains(preferenceGoals, f.Goal) {
+		v.add("goal", domain.CodeInvalid, "violation.goal.invalid", strings.Join(preferenceGoals, ", "))
+	}
+	if !slices.Contains(feedSorts, f.Sort) {
+		v.add("sort", domain.CodeInvalid, "violation.sort.invalid", strings.Join(feedSorts[1:], ", "))
+	}
+	switch {
+	case f.AfterScore < 0 || f.AfterScore > 1:
+		v.add("after_score", domain.CodeOutOfRange, "violation.after_score.out_of_range")
+	case f.AfterScore != 0 && f.Sort != domain.FeedSortScore:
+		v.add("after_score", domain.CodeInvalid, "violation.after_score.score_only")
+	}
+
+	return v.err()
+}
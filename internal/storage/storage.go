@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrBlobNotFound - объекта с таким ключом нет в хранилище
+var ErrBlobNotFound = errors.New("storage: blob not found")
+
+// BlobStore - хранилище бинарных объектов (фото анкет).
+// Ключи - относительные пути через "/", например "anquettes/1/abc.jpg".
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// LocalStore - BlobStore в каталоге локальной файловой системы
+type LocalStore struct {
+	root string
+}
+
+func NewLocalStore(root string) (*LocalStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("storage: failed to create root %q: %w", root, err)
+	}
+	return &LocalStore{root: root}, nil
+}
+
+// path - путь к файлу объекта; ключи, выходящие за пределы root, отклоняются
+func (s *LocalStore) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || !filepath.IsLocal(filepath.FromSlash(key)) {
+		return "", fmt.Errorf("storage: invalid key %q", key)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Put - записывает объект во временный файл и атомарно переименовывает его
+func (s *LocalStore) Put(ctx context.Context, key string, r io.Reader) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("storage: failed to create dir for %q: %w", key, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("storage: failed to create temp file for %q: %w", key, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("storage: failed to write %q: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("storage: failed to close %q: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		return fmt.Errorf("storage: failed to store %q: %w", key, err)
+	}
+	return nil
+}
+
+func (s *LocalStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrBlobNotFound
+		}
+		return nil, fmt.Errorf("storage: failed to open %q: %w", key, err)
+	}
+	return f, nil
+}
+
+// Delete - удаляет объект; отсутствие объекта ошибкой не считается
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("storage: failed to delete %q: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"bot-api/internal/storage"
+)
+
+func TestLocalStore_PutOpenDelete(t *testing.T) {
+	s, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore провалился: %v", err)
+	}
+	ctx := context.Background()
+
+	if err := s.Put(ctx, "anquettes/1/a.jpg", strings.NewReader("jpeg")); err != nil {
+		t.Fatalf("Put провалился: %v", err)
+	}
+
+	rc, err := s.Open(ctx, "anquettes/1/a.jpg")
+	if err != nil {
+		t.Fatalf("Open провалился: %v", err)
+	}
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(data) != "jpeg" {
+		t.Errorf("Ожидали содержимое jpeg, получили %q", data)
+	}
+
+	if err := s.Delete(ctx, "anquettes/1/a.jpg"); err != nil {
+		t.Fatalf("Delete провалился: %v", err)
+	}
+	if _, err := s.Open(ctx, "anquettes/1/a.jpg"); !errors.Is(err, storage.ErrBlobNotFound) {
+		t.Errorf("Ожидали storage.ErrBlobNotFound, получили %v", err)
+	}
+}
+
+func TestLocalStore_RejectsEscapingKeys(t *testing.T) {
+	s, _ := storage.NewLocalStore(t.TempDir())
+
+	for _, key := range []string{"../secret", "/etc/passwd", "a/../../b", ""} {
+		if err := s.Put(context.Background(), key, strings.NewReader("x")); err == nil {
+			t.Errorf("Ожидали ошибку для ключа %q", key)
+		}
+	}
+}
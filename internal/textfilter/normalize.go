@@ -0,0 +1,101 @@
+package textfilter
+
+import (
+	"strings"
+	"unicode"
+)
+
+// lookalikes - буквы и цифры, похожие на одну и ту же букву, приводятся к общему
+// виду. Так "xyй" с латинскими буквами, "ѕех" с кириллицей и "sh1t" совпадают
+// со словами словаря. Текст и словарь нормализуются одинаково.
+var lookalikes = map[rune]rune{
+	'а': 'a', '@': 'a', '4': 'a',
+	'в': 'b',
+	'с': 'c',
+	'е': 'e', 'ё': 'e', 'є': 'e',
+	'к': 'k',
+	'м': 'm',
+	'н': 'h',
+	'о': 'o', '0': 'o',
+	'р': 'p',
+	'т': 't',
+	'х': 'x',
+	'у': 'y',
+	'і': 'i', '1': 'i', '!': 'i', '|': 'i',
+	'ѕ': 's', '$': 's', '5': 's',
+	'з': '3',
+	'6': 'б',
+}
+
+// isWordRune - входит ли символ в слово (с учетом символов, которыми заменяют буквы)
+func isWordRune(r rune) bool {
+	return isLookalikeSymbol(r) || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.In(r, unicode.Mn, unicode.Cf)
+}
+
+// isLookalikeSymbol - знак препинания или символ, которым заменяют букву ("sh!t").
+// Буквой он считается только внутри слова: на краю это обычная пунктуация.
+func isLookalikeSymbol(r rune) bool {
+	_, ok := lookalikes[r]
+	return ok && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// run - буква и сколько раз подряд она повторяется
+type run struct {
+	r     rune
+	count int
+}
+
+// normalizeWord - приводит слово к виду для сравнения: нижний регистр, общие
+// похожие буквы, без диакритики и невидимых символов. Повторы букв сворачиваются
+// в серии, чтобы "fuuuuck" совпадало с "fuck".
+func normalizeWord(word string) []run {
+	var runs []run
+	for _, r := range strings.ToLower(word) {
+		if unicode.In(r, unicode.Mn, unicode.Cf) {
+			continue // диакритика и невидимые символы вроде U+200B
+		}
+		if l, ok := lookalikes[r]; ok {
+			r = l
+		}
+		if n := len(runs); n > 0 && runs[n-1].r == r {
+			runs[n-1].count++
+			continue
+		}
+		runs = append(runs, run{r: r, count: 1})
+	}
+	return runs
+}
+
+// token - слово текста в исходном и нормализованном виде
+type token struct {
+	text string
+	runs []run
+}
+
+// tokenize - разбивает текст на слова. Символы-заменители на краях слова
+// отбрасываются, чтобы "сука!" не превращалось в "сукаi".
+func tokenize(text string) []token {
+	var tokens []token
+	for _, w := range strings.FieldsFunc(text, func(r rune) bool { return !isWordRune(r) }) {
+		w = strings.TrimFunc(w, isLookalikeSymbol)
+		if runs := normalizeWord(w); len(runs) > 0 {
+			tokens = append(tokens, token{text: w, runs: runs})
+		}
+	}
+	return tokens
+}
+
+// matchWord - совпадает ли слово текста со словом словаря. Каждая буква словаря
+// может повторяться в тексте чаще, но не реже: "asss" совпадает с "ass", а "as" - нет.
+// При prefix слово текста может быть длиннее (любое окончание).
+func matchWord(tok, word []run, prefix bool) bool {
+	if len(tok) < len(word) || (!prefix && len(tok) != len(word)) {
+		return false
+	}
+	for i, w := range word {
+		if tok[i].r != w.r || tok[i].count < w.count {
+			return false
+		}
+	}
+	return true
+}
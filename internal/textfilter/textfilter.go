@@ -0,0 +1,338 @@
+// Package textfilter - автоматическая проверка текста анкет: мат и оскорбления,
+// спам, ссылки и телефоны. Слова ищутся с учетом маскировки похожими буквами
+// другого алфавита, цифрами и повторами букв.
+package textfilter
+
+import (
+	"bufio"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+//go:embed dict/*.txt
+var dicts embed.FS
+
+// Action - что делать с текстом, в котором что-то нашлось
+type Action int
+
+const (
+	Allow  Action = iota // пропустить
+	Flag                 // сохранить и отправить на модерацию
+	Reject               // отклонить
+)
+
+var actionNames = map[Action]string{Allow: "allow", Flag: "flag", Reject: "reject"}
+
+func (a Action) String() string {
+	return actionNames[a]
+}
+
+// ParseAction - разбирает "allow", "flag" или "reject"
+func ParseAction(s string) (Action, error) {
+	for a, name := range actionNames {
+		if strings.EqualFold(strings.TrimSpace(s), name) {
+			return a, nil
+		}
+	}
+	return Allow, fmt.Errorf("textfilter: unknown action %q", s)
+}
+
+// Category - вид найденного нарушения
+type Category string
+
+const (
+	CategoryProfanity  Category = "profanity"
+	CategorySuspicious Category = "suspicious" // может быть и матом, и обычным словом
+	CategorySpam       Category = "spam"
+	CategoryLink       Category = "link"
+	CategoryPhone      Category = "phone"
+)
+
+// Policy - действие для каждой категории. Категории без записи пропускаются.
+type Policy map[Category]Action
+
+// DefaultPolicy - мат отклоняется, остальное уходит на модерацию
+func DefaultPolicy() Policy {
+	return Policy{
+		CategoryProfanity:  Reject,
+		CategorySuspicious: Flag,
+		CategorySpam:       Flag,
+		CategoryLink:       Flag,
+		CategoryPhone:      Flag,
+	}
+}
+
+// ParsePolicy - разбирает строку вида "profanity=reject,link=flag" поверх DefaultPolicy
+func ParsePolicy(s string) (Policy, error) {
+	p := DefaultPolicy()
+	for _, item := range strings.Split(s, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("textfilter: bad policy item %q", item)
+		}
+		cat := Category(strings.ToLower(strings.TrimSpace(name)))
+		if _, known := p[cat]; !known {
+			return nil, fmt.Errorf("textfilter: unknown category %q", name)
+		}
+		a, err := ParseAction(value)
+		if err != nil {
+			return nil, err
+		}
+		p[cat] = a
+	}
+	return p, nil
+}
+
+// Config - словари и политика фильтра. Записи словаря - слова или фразы;
+// * в конце слова означает любое окончание.
+type Config struct {
+	Profanity  []string
+	Suspicious []string // CategorySuspicious
+	Spam       []string
+	Policy     Policy
+}
+
+// DefaultConfig - встроенные русские и английские словари и DefaultPolicy
+func DefaultConfig() Config {
+	return Config{
+		Profanity:  append(mustReadDict("dict/profanity_ru.txt"), mustReadDict("dict/profanity_en.txt")...),
+		Suspicious: mustReadDict("dict/suspicious.txt"),
+		Spam:       mustReadDict("dict/spam.txt"),
+		Policy:     DefaultPolicy(),
+	}
+}
+
+func mustReadDict(name string) []string {
+	f, err := dicts.Open(name)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+	words, err := ReadWordList(f)
+	if err != nil {
+		panic(err)
+	}
+	return words
+}
+
+// LoadDir - дополняет словари записями из profanity.txt, suspicious.txt и spam.txt
+// в каталоге dir. Отсутствующие файлы пропускаются.
+func (c *Config) LoadDir(dir string) error {
+	for name, list := range map[string]*[]string{"profanity.txt": &c.Profanity, "suspicious.txt": &c.Suspicious, "spam.txt": &c.Spam} {
+		f, err := os.Open(filepath.Join(dir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("textfilter: failed to open %s: %w", name, err)
+		}
+		words, err := ReadWordList(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		*list = append(*list, words...)
+	}
+	return nil
+}
+
+// ReadWordList - читает словарь: одна запись на строку, пустые строки и строки с # пропускаются
+func ReadWordList(r io.Reader) ([]string, error) {
+	var words []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("textfilter: failed to read word list: %w", err)
+	}
+	return words, nil
+}
+
+// Field - проверяемое поле анкеты
+type Field struct {
+	Name string
+	Text string
+}
+
+// Finding - найденное нарушение
+type Finding struct {
+	Field    string   `json:"field"`
+	Category Category `json:"category"`
+	Match    string   `json:"match"`
+	Action   Action   `json:"-"` // действие по политике для этой категории
+}
+
+// Result - итог проверки: самое строгое действие среди найденного
+type Result struct {
+	Action   Action
+	Findings []Finding
+}
+
+// entry - запись словаря: последовательность слов
+type entry []entryWord
+
+type entryWord struct {
+	runs   []run
+	prefix bool
+}
+
+func compile(words []string) []entry {
+	var entries []entry
+	for _, w := range words {
+		var e entry
+		for _, part := range strings.Fields(w) {
+			prefix := strings.HasSuffix(part, "*")
+			if runs := normalizeWord(strings.TrimSuffix(part, "*")); len(runs) > 0 {
+				e = append(e, entryWord{runs: runs, prefix: prefix})
+			}
+		}
+		if len(e) > 0 {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+var (
+	linkPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)(?:https?://|www\.)\S+`),
+		regexp.MustCompile(`(?i)(?:^|[^\p{L}\d])(?:t\.me|telegram\.me|wa\.me)/\S+`),
+		regexp.MustCompile(`(?i)[\p{L}\d-]+\.(?:ru|рф|su|com|net|org|info|io|me|co|xyz|site|online|ly|gg|tk|ua|by|kz)(?:$|[^\p{L}\d])`),
+		regexp.MustCompile(`(?i)(?:^|\s)@[a-z0-9_]{5,}`),
+	}
+	phonePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?:\+7|(?:^|[^\d])[78])[\s\-.]*\(?\d{3}\)?[\s\-.]*\d{3}[\s\-.]*\d{2}[\s\-.]*\d{2}(?:$|[^\d])`),
+		regexp.MustCompile(`\+\d(?:[\s\-.()]?\d){9,13}(?:$|[^\d])`),
+	}
+)
+
+// Filter - проверка текста по словарям и шаблонам. Безопасен для параллельного использования.
+type Filter struct {
+	profanity  []entry
+	suspicious []entry
+	spam       []entry
+	policy     Policy
+}
+
+func New(cfg Config) *Filter {
+	policy := cfg.Policy
+	if policy == nil {
+		policy = DefaultPolicy()
+	}
+	return &Filter{
+		profanity:  compile(cfg.Profanity),
+		suspicious: compile(cfg.Suspicious),
+		spam:       compile(cfg.Spam),
+		policy:     policy,
+	}
+}
+
+// Check - проверяет поля и возвращает найденное и действие по политике
+func (f *Filter) Check(fields ...Field) Result {
+	var res Result
+	add := func(field string, cat Category, match string) {
+		a, ok := f.policy[cat]
+		if !ok || a == Allow {
+			return
+		}
+		res.Findings = append(res.Findings, Finding{Field: field, Category: cat, Match: strings.TrimSpace(match), Action: a})
+		if a > res.Action {
+			res.Action = a
+		}
+	}
+
+	for _, fld := range fields {
+		tokens := tokenize(fld.Text)
+		for _, m := range findEntries(tokens, f.profanity) {
+			add(fld.Name, CategoryProfanity, m)
+		}
+		for _, m := range findEntries(tokens, f.suspicious) {
+			add(fld.Name, CategorySuspicious, m)
+		}
+		for _, m := range findEntries(tokens, f.spam) {
+			add(fld.Name, CategorySpam, m)
+		}
+		for _, re := range linkPatterns {
+			for _, m := range re.FindAllString(fld.Text, -1) {
+				add(fld.Name, CategoryLink, m)
+			}
+		}
+		for _, m := range findPhones(fld.Text) {
+			add(fld.Name, CategoryPhone, m)
+		}
+	}
+	return res
+}
+
+// findPhones - телефоны в тексте. Шаблоны пересекаются, поэтому номер,
+// найденный несколькими шаблонами, возвращается один раз.
+func findPhones(text string) []string {
+	var found []string
+	seen := map[[2]int]bool{}
+	for _, re := range phonePatterns {
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			// шаблоны захватывают соседние символы, чтобы номер не оказался
+			// частью более длинного числа: границы номера - от + или цифры до цифры
+			start, end := loc[0], loc[1]
+			for start < end && text[start] != '+' && !isASCIIDigit(text[start]) {
+				start++
+			}
+			for end > start && !isASCIIDigit(text[end-1]) {
+				end--
+			}
+			span := [2]int{start, end}
+			if seen[span] {
+				continue
+			}
+			seen[span] = true
+			found = append(found, text[start:end])
+		}
+	}
+	return found
+}
+
+func isASCIIDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// findEntries - исходный текст всех совпадений записей словаря
+func findEntries(tokens []token, entries []entry) []string {
+	var found []string
+	for i := range tokens {
+		for _, e := range entries {
+			if i+len(e) > len(tokens) {
+				continue
+			}
+			matched := true
+			for j, w := range e {
+				if !matchWord(tokens[i+j].runs, w.runs, w.prefix) {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				words := make([]string, len(e))
+				for j := range e {
+					words[j] = tokens[i+j].text
+				}
+				found = append(found, strings.Join(words, " "))
+			}
+		}
+	}
+	return found
+}
@@ -0,0 +1,154 @@
+package textfilter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func check(text string) Result {
+	return New(DefaultConfig()).Check(Field{Name: "description", Text: text})
+}
+
+func TestCheck_CleanText(t *testing.T) {
+	texts := []string{
+		"Люблю горы, кофе и долгие прогулки по набережной. Ищу человека для путешествий.",
+		"I like hiking, jazz and good books. Let's grab a coffee as friends first.",
+		"Мне 25 лет, рост 170, работаю с 2019 года. Потребуется время, чтобы узнать друг друга.",
+		"Работаю мастером маникюра и педикюра, делаю педикюр на дому.", // "педик" - только целое слово
+	}
+	for _, text := range texts {
+		if res := check(text); res.Action != Allow {
+			t.Errorf("Ожидали чистый текст для %q, получили %+v", text, res.Findings)
+		}
+	}
+}
+
+func TestCheck_ObfuscatedProfanity(t *testing.T) {
+	texts := []string{
+		"ну и хуйня",
+		"ну и xyйня",       // латинские x и y
+		"ПИИИИЗДЕЦ",        // повторы и верхний регистр
+		"пи\u200bздец",     // невидимый пробел
+		"what the fuuuuck", // повторы
+		"ѕh1t happens",     // кириллическая ѕ и цифра
+		"ёбаный стыд",
+		"сам ты педик",
+	}
+	for _, text := range texts {
+		res := check(text)
+		if res.Action != Reject || len(res.Findings) == 0 || res.Findings[0].Category != CategoryProfanity {
+			t.Errorf("Ожидали отказ из-за мата для %q, получили %+v", text, res)
+		}
+	}
+}
+
+func TestCheck_TrailingPunctuation(t *testing.T) {
+	// знаки, которыми заменяют буквы ("sh!t"), на краю слова - обычная пунктуация
+	for _, text := range []string{"сука!", "бля!", "fag!", "ну ты и сука?", "сука|", "!сука", "sh!t!"} {
+		if res := check(text); res.Action != Reject {
+			t.Errorf("Ожидали отказ из-за мата для %q, получили %+v", text, res)
+		}
+	}
+	res := check("Dick!")
+	if res.Action != Flag || len(res.Findings) != 1 || res.Findings[0].Match != "Dick" {
+		t.Errorf("Ожидали флаг suspicious для Dick!, получили %+v", res)
+	}
+}
+
+func TestCheck_RepeatsDoNotShortenWords(t *testing.T) {
+	// "ass" в словаре нет, но повторы не должны превращать обычные слова в словарные
+	f := New(Config{Profanity: []string{"ass"}})
+	if res := f.Check(Field{Text: "as good as it gets"}); res.Action != Allow {
+		t.Errorf("Ожидали чистый текст, получили %+v", res.Findings)
+	}
+	if res := f.Check(Field{Text: "aaasss"}); res.Action != Reject {
+		t.Errorf("Ожидали отказ, получили %+v", res)
+	}
+}
+
+func TestCheck_SuspiciousFlagged(t *testing.T) {
+	// Dick - и имя, и ругательство: такую анкету решает модератор
+	for _, text := range []string{"Hi, I'm Dick from Boston", "Перечитываю Moby Dick"} {
+		res := check(text)
+		if res.Action != Flag || len(res.Findings) != 1 || res.Findings[0].Category != CategorySuspicious {
+			t.Errorf("Ожидали флаг suspicious для %q, получили %+v", text, res)
+		}
+	}
+	// dickhead по-прежнему мат
+	if res := check("what a dickhead"); res.Action != Reject {
+		t.Errorf("Ожидали отказ, получили %+v", res)
+	}
+}
+
+func TestCheck_SpamLinksPhones(t *testing.T) {
+	cases := map[string]Category{
+		"Пассивный доход без вложений":    CategorySpam,
+		"Заглядывай на мой OnlyFans":      CategorySpam,
+		"пишите https://example.com/me":   CategoryLink,
+		"мой канал t.me/channel":          CategoryLink,
+		"подробности на сайте анкета.рф":  CategoryLink,
+		"пиши в тг @my_profile":           CategoryLink,
+		"звони +7 (912) 345-67-89":        CategoryPhone,
+		"номер 8 912 345 67 89, после 18": CategoryPhone,
+	}
+	for text, cat := range cases {
+		res := check(text)
+		if res.Action != Flag || len(res.Findings) == 0 || res.Findings[0].Category != cat {
+			t.Errorf("Ожидали флаг %s для %q, получили %+v", cat, text, res)
+		}
+	}
+}
+
+func TestCheck_PhoneReportedOnce(t *testing.T) {
+	// номер подходит под оба шаблона телефонов
+	res := check("звони +7 900 123-45-67, после 18")
+	if len(res.Findings) != 1 || res.Findings[0].Match != "+7 900 123-45-67" {
+		t.Errorf("Ожидали одну находку с номером, получили %+v", res.Findings)
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	p, err := ParsePolicy("link=reject, phone=allow")
+	if err != nil {
+		t.Fatalf("ParsePolicy провалился: %v", err)
+	}
+	if p[CategoryLink] != Reject || p[CategoryPhone] != Allow || p[CategoryProfanity] != Reject {
+		t.Errorf("Неверная политика: %v", p)
+	}
+
+	f := New(Config{Policy: p})
+	if res := f.Check(Field{Text: "звони +7 912 345 67 89"}); res.Action != Allow || len(res.Findings) != 0 {
+		t.Errorf("Ожидали пропуск телефона, получили %+v", res)
+	}
+
+	if _, err := ParsePolicy("emoji=reject"); err == nil {
+		t.Error("Ожидали ошибку для неизвестной категории")
+	}
+}
+
+func TestReadWordList(t *testing.T) {
+	words, err := ReadWordList(strings.NewReader("# комментарий\n\nслово\n фраза из слов \n"))
+	if err != nil {
+		t.Fatalf("ReadWordList провалился: %v", err)
+	}
+	if len(words) != 2 || words[1] != "фраза из слов" {
+		t.Errorf("Неверный словарь: %q", words)
+	}
+}
+
+func TestConfig_LoadDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "spam.txt"), []byte("промокод*\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultConfig()
+	if err := cfg.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir провалился: %v", err)
+	}
+	if res := New(cfg).Check(Field{Text: "Держи промокоды"}); res.Action != Flag {
+		t.Errorf("Ожидали флаг по словарю из каталога, получили %+v", res)
+	}
+}